@@ -1,28 +1,149 @@
 package main
 
 import (
+	"encoding/json"
+	"expvar"
+	"flag"
+	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
+	"github.com/mikekulinski/zookeeper/pkg/certs"
+	"github.com/mikekulinski/zookeeper/pkg/config"
 	zookeeper "github.com/mikekulinski/zookeeper/pkg/server"
 	pbzk "github.com/mikekulinski/zookeeper/proto"
 	"google.golang.org/grpc"
 )
 
+var (
+	configPath = flag.String("config", "", "path to the config file, in the same key=value format as zoo.cfg")
+	clientPort = flag.Int("client-port", config.DefaultClientPort, "port to listen on for clients")
+	dataDir    = flag.String("data-dir", "", "directory for snapshots and the myid file")
+	dataLogDir = flag.String("data-log-dir", "", "directory for the transaction log (defaults to the data dir)")
+	tickTime   = flag.Duration("tick-time", config.DefaultTickTime, "basic unit of time for heartbeats and session timeouts")
+	snapCount  = flag.Int("snap-count", config.DefaultSnapCount, "number of transactions to log between snapshots")
+	myID       = flag.Int64("myid", 0, "id of this server in the ensemble")
+	adminPort  = flag.Int("admin-port", config.DefaultAdminPort, "port to serve admin commands on. Setting this enables the admin server")
+)
+
+// loadConfig reads the config file, if there is one, and then applies any flags that were set
+// explicitly on the command line on top of it.
+func loadConfig() (*config.Config, error) {
+	cfg := config.Default()
+	if *configPath != "" {
+		var err error
+		cfg, err = config.Load(*configPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "client-port":
+			cfg.ClientPort = *clientPort
+		case "data-dir":
+			cfg.DataDir = *dataDir
+		case "data-log-dir":
+			cfg.DataLogDir = *dataLogDir
+		case "tick-time":
+			cfg.TickTime = *tickTime
+		case "snap-count":
+			cfg.SnapCount = *snapCount
+		case "myid":
+			cfg.ServerID = *myID
+		case "admin-port":
+			cfg.AdminEnabled = true
+			cfg.AdminPort = *adminPort
+		}
+	})
+
+	err := cfg.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+	return cfg, nil
+}
+
+// serveAdmin serves the admin commands over HTTP.
+func serveAdmin(cfg *config.Config, zk *zookeeper.Server) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/commands/ruok", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, "imok")
+	})
+	mux.HandleFunc("/commands/watch_summary", func(w http.ResponseWriter, _ *http.Request) {
+		stats := zk.WatchStats()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]int{
+			"num_connections":   stats.Sessions,
+			"num_paths":         stats.Paths,
+			"num_total_watches": stats.Watches,
+		})
+	})
+	// Metrics, such as how often quotas were exceeded.
+	mux.Handle("/debug/vars", expvar.Handler())
+	s := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.AdminPort),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		log.Printf("admin server listening at %v", s.Addr)
+		if err := s.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("admin server failed: %v", err)
+		}
+	}()
+	return s
+}
+
 func main() {
-	lis, err := net.Listen("tcp", ":8080")
+	flag.Parse()
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.ClientPort))
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
-	s := grpc.NewServer()
-	zk := zookeeper.NewServer()
+	limiter := zookeeper.NewConnectionLimiter(cfg.MaxClientConnections)
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(cfg.MaxRequestSize),
+		grpc.ChainStreamInterceptor(limiter.StreamInterceptor()),
+	}
+	tlsConfig, err := cfg.TLS.ServerConfig()
+	if err != nil {
+		log.Fatalf("failed to set up TLS: %v", err)
+	}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(certs.NewServerCredentials(tlsConfig, cfg.TLS.Mode == config.TLSMode_ALLOW)))
+	}
+
+	s := grpc.NewServer(opts...)
+	var zk *zookeeper.Server
+	if cfg.DataLogDir != "" {
+		zk, err = zookeeper.RecoverServer(cfg)
+		if err != nil {
+			log.Fatalf("failed to recover from the log: %v", err)
+		}
+	} else {
+		// Without a directory for the log, the tree is only kept in memory.
+		zk = zookeeper.NewServerWithConfig(cfg)
+	}
 	pbzk.RegisterZookeeperServer(s, zk)
 
+	var admin *http.Server
+	if cfg.AdminEnabled {
+		admin = serveAdmin(cfg, zk)
+	}
+
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
 	wg := sync.WaitGroup{}
@@ -31,10 +152,14 @@ func main() {
 		sig := <-sigCh
 		log.Printf("got signal %v, attempting graceful shutdown", sig)
 		s.GracefulStop()
+		zk.Close()
+		if admin != nil {
+			_ = admin.Close()
+		}
 		wg.Done()
 	}()
 
-	log.Printf("server listening at %v", lis.Addr())
+	log.Printf("server [%d] listening at %v", cfg.ServerID, lis.Addr())
 	if err := s.Serve(lis); err != nil {
 		log.Fatalf("failed to serve: %v", err)
 	}
@@ -0,0 +1,66 @@
+// Package auth has the providers that clients use to prove who they are. Each provider handles one
+// scheme, and the ids it hands out are what ACLs grant permissions to.
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+
+	pbzk "github.com/mikekulinski/zookeeper/proto"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// AuthProvider authenticates clients for a single scheme, and decides which ACL entries of that scheme
+// apply to them.
+type AuthProvider interface {
+	// Scheme is the name of the scheme. i.e. "digest"
+	Scheme() string
+	// Authenticate checks the credentials the client sent in addAuth, and returns the id the client proved
+	// it has. Providers that identify clients by their connection can ignore the credentials.
+	Authenticate(conn *ConnInfo, credentials []byte) (*pbzk.Id, error)
+	// IsValid returns whether the id is well-formed enough to be used in an ACL.
+	IsValid(id string) bool
+	// Matches returns whether a client with the id has the permissions that an ACL grants to aclID.
+	Matches(id string, aclID string) bool
+}
+
+// ConnectionAuthProvider is an AuthProvider that can identify clients from their connection alone, such as by
+// their address or certificate. Clients are authenticated with these as soon as they connect.
+type ConnectionAuthProvider interface {
+	AuthProvider
+	// AuthenticateConnection returns the id of the client on the connection.
+	AuthenticateConnection(conn *ConnInfo) (*pbzk.Id, error)
+}
+
+// ConnInfo is what we know about the connection the client is using.
+type ConnInfo struct {
+	// RemoteAddr is the address the client is connecting from.
+	RemoteAddr net.Addr
+	// TLS is the state of the TLS connection. This is nil if the client isn't using TLS.
+	TLS *tls.ConnectionState
+}
+
+// ConnInfoFromContext returns what we know about the connection of the stream with the given context.
+func ConnInfoFromContext(ctx context.Context) *ConnInfo {
+	info := &ConnInfo{}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return info
+	}
+	info.RemoteAddr = p.Addr
+	if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+		info.TLS = &tlsInfo.State
+	}
+	return info
+}
+
+// DefaultProviders returns the providers every server supports.
+func DefaultProviders() []AuthProvider {
+	return []AuthProvider{
+		&DigestProvider{},
+		&IPProvider{},
+		&X509Provider{},
+	}
+}
@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+
+	pbzk "github.com/mikekulinski/zookeeper/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+func TestGenerateDigest(t *testing.T) {
+	// This is the same digest that ZooKeeper generates for these credentials.
+	assert.Equal(t, "super:xQJmxLMiHGwaqBvst5y6rkB6HQs=", GenerateDigest("super", "admin"))
+}
+
+func TestDigestProvider_Authenticate(t *testing.T) {
+	tests := []struct {
+		name          string
+		credentials   string
+		expected      *pbzk.Id
+		errorExpected bool
+	}{
+		{
+			name:        "valid",
+			credentials: "super:admin",
+			expected:    &pbzk.Id{Scheme: SchemeDigest, Id: "super:xQJmxLMiHGwaqBvst5y6rkB6HQs="},
+		},
+		{
+			name:        "password with a colon",
+			credentials: "user:pass:word",
+			expected:    &pbzk.Id{Scheme: SchemeDigest, Id: GenerateDigest("user", "pass:word")},
+		},
+		{
+			name:          "missing password",
+			credentials:   "user",
+			errorExpected: true,
+		},
+		{
+			name:          "missing user",
+			credentials:   ":password",
+			errorExpected: true,
+		},
+	}
+	p := &DigestProvider{}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			id, err := p.Authenticate(nil, []byte(test.credentials))
+			if test.errorExpected {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, id)
+			assert.True(t, p.IsValid(id.GetId()))
+			assert.True(t, p.Matches(id.GetId(), test.expected.GetId()))
+		})
+	}
+	assert.False(t, p.IsValid("no digest"))
+}
+
+func TestIPProvider(t *testing.T) {
+	p := &IPProvider{}
+	id, err := p.Authenticate(&ConnInfo{RemoteAddr: &net.TCPAddr{IP: net.ParseIP("10.1.2.3"), Port: 5555}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, &pbzk.Id{Scheme: SchemeIP, Id: "10.1.2.3"}, id)
+
+	_, err = p.Authenticate(&ConnInfo{}, nil)
+	assert.Error(t, err)
+	_, err = p.Authenticate(&ConnInfo{RemoteAddr: &net.UnixAddr{Name: "bufconn"}}, nil)
+	assert.Error(t, err)
+
+	tests := []struct {
+		name    string
+		id      string
+		aclID   string
+		matches bool
+	}{
+		{
+			name:    "same address",
+			id:      "10.1.2.3",
+			aclID:   "10.1.2.3",
+			matches: true,
+		},
+		{
+			name:  "different address",
+			id:    "10.1.2.3",
+			aclID: "10.1.2.4",
+		},
+		{
+			name:    "inside the network",
+			id:      "10.1.2.3",
+			aclID:   "10.0.0.0/8",
+			matches: true,
+		},
+		{
+			name:  "outside the network",
+			id:    "192.168.1.1",
+			aclID: "10.0.0.0/8",
+		},
+		{
+			name:  "invalid network",
+			id:    "10.1.2.3",
+			aclID: "10.0.0.0/64",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.matches, p.Matches(test.id, test.aclID))
+		})
+	}
+
+	assert.True(t, p.IsValid("10.1.2.3"))
+	assert.True(t, p.IsValid("10.0.0.0/8"))
+	assert.True(t, p.IsValid("::1"))
+	assert.False(t, p.IsValid("localhost"))
+	assert.False(t, p.IsValid("10.0.0.0/64"))
+}
+
+func TestX509Provider(t *testing.T) {
+	cert := &x509.Certificate{
+		Subject: pkix.Name{CommonName: "alice", Organization: []string{"tenant1"}},
+	}
+	p := &X509Provider{}
+
+	id, err := p.Authenticate(&ConnInfo{TLS: &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert},
+		VerifiedChains:   [][]*x509.Certificate{{cert}},
+	}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, &pbzk.Id{Scheme: SchemeX509, Id: "CN=alice,O=tenant1"}, id)
+	assert.True(t, p.Matches(id.GetId(), "CN=alice,O=tenant1"))
+	assert.False(t, p.Matches(id.GetId(), "CN=bob,O=tenant1"))
+
+	// Certificates we didn't verify can't be trusted.
+	_, err = p.Authenticate(&ConnInfo{TLS: &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert},
+	}}, nil)
+	assert.Error(t, err)
+	_, err = p.Authenticate(&ConnInfo{}, nil)
+	assert.Error(t, err)
+}
+
+func TestConnInfoFromContext(t *testing.T) {
+	assert.Equal(t, &ConnInfo{}, ConnInfoFromContext(context.Background()))
+
+	addr := &net.TCPAddr{IP: net.ParseIP("10.1.2.3"), Port: 5555}
+	state := tls.ConnectionState{ServerName: "zk1"}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr:     addr,
+		AuthInfo: credentials.TLSInfo{State: state},
+	})
+	info := ConnInfoFromContext(ctx)
+	assert.Equal(t, addr, info.RemoteAddr)
+	require.NotNil(t, info.TLS)
+	assert.Equal(t, "zk1", info.TLS.ServerName)
+}
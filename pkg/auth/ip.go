@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	pbzk "github.com/mikekulinski/zookeeper/proto"
+)
+
+// SchemeIP identifies clients by the address they connect from.
+const SchemeIP = "ip"
+
+// IPProvider authenticates clients by their IP address. ACLs can grant permissions to a single address
+// (i.e. "10.0.0.5"), or to a whole network in CIDR notation (i.e. "10.0.0.0/8").
+type IPProvider struct{}
+
+func (p *IPProvider) Scheme() string {
+	return SchemeIP
+}
+
+func (p *IPProvider) Authenticate(conn *ConnInfo, _ []byte) (*pbzk.Id, error) {
+	return p.AuthenticateConnection(conn)
+}
+
+func (p *IPProvider) AuthenticateConnection(conn *ConnInfo) (*pbzk.Id, error) {
+	if conn == nil || conn.RemoteAddr == nil {
+		return nil, fmt.Errorf("client address is unknown")
+	}
+	host := conn.RemoteAddr.String()
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, fmt.Errorf("client address [%s] is not an IP address", conn.RemoteAddr)
+	}
+	return &pbzk.Id{Scheme: SchemeIP, Id: ip.String()}, nil
+}
+
+func (p *IPProvider) IsValid(id string) bool {
+	if strings.Contains(id, "/") {
+		_, _, err := net.ParseCIDR(id)
+		return err == nil
+	}
+	return net.ParseIP(id) != nil
+}
+
+func (p *IPProvider) Matches(id string, aclID string) bool {
+	ip := net.ParseIP(id)
+	if ip == nil {
+		return false
+	}
+	if strings.Contains(aclID, "/") {
+		_, network, err := net.ParseCIDR(aclID)
+		return err == nil && network.Contains(ip)
+	}
+	return ip.Equal(net.ParseIP(aclID))
+}
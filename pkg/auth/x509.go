@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"fmt"
+
+	pbzk "github.com/mikekulinski/zookeeper/proto"
+)
+
+// SchemeX509 identifies clients by the certificate they connect with.
+const SchemeX509 = "x509"
+
+// X509Provider authenticates clients by the subject of the certificate they used to connect over TLS.
+// i.e. "CN=alice,O=tenant1". The certificate has to have been verified by the server.
+type X509Provider struct{}
+
+func (p *X509Provider) Scheme() string {
+	return SchemeX509
+}
+
+func (p *X509Provider) Authenticate(conn *ConnInfo, _ []byte) (*pbzk.Id, error) {
+	return p.AuthenticateConnection(conn)
+}
+
+func (p *X509Provider) AuthenticateConnection(conn *ConnInfo) (*pbzk.Id, error) {
+	if conn == nil || conn.TLS == nil {
+		return nil, fmt.Errorf("client is not connected over TLS")
+	}
+	// Only trust certificates we verified against our CAs. Unverified certificates are only present if
+	// the server doesn't require client certificates.
+	if len(conn.TLS.VerifiedChains) == 0 || len(conn.TLS.VerifiedChains[0]) == 0 {
+		return nil, fmt.Errorf("client did not present a verified certificate")
+	}
+	cert := conn.TLS.VerifiedChains[0][0]
+	return &pbzk.Id{Scheme: SchemeX509, Id: cert.Subject.String()}, nil
+}
+
+func (p *X509Provider) IsValid(id string) bool {
+	return id != ""
+}
+
+func (p *X509Provider) Matches(id string, aclID string) bool {
+	return id == aclID
+}
@@ -0,0 +1,280 @@
+package certs_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mikekulinski/zookeeper/pkg/auth"
+	"github.com/mikekulinski/zookeeper/pkg/certs"
+	zkc "github.com/mikekulinski/zookeeper/pkg/client"
+	"github.com/mikekulinski/zookeeper/pkg/server"
+	pbzk "github.com/mikekulinski/zookeeper/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// serverName is the name in the server's certificate.
+const serverName = "zk1"
+
+// ca is a self-signed certificate authority that issues certificates for the tests.
+type ca struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	file string
+}
+
+func newCA(t *testing.T, dir string) *ca {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	file := filepath.Join(dir, "ca.crt")
+	writePEM(t, file, "CERTIFICATE", der)
+	return &ca{cert: cert, key: key, file: file}
+}
+
+// issue writes a certificate signed by the CA and its key to dir, and returns the paths to them.
+func (c *ca) issue(t *testing.T, dir string, name string, serial int64) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name, Organization: []string{"tenant1"}},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, c.cert, &key.PublicKey, c.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return certFile, keyFile
+}
+
+func writePEM(t *testing.T, file string, blockType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	require.NoError(t, os.WriteFile(file, data, 0o600))
+}
+
+// touch moves the modification time of the files forward, so the reloader notices them even if they were
+// rewritten within the resolution of the file system's clock.
+func touch(t *testing.T, files ...string) {
+	later := time.Now().Add(time.Minute)
+	for _, file := range files {
+		require.NoError(t, os.Chtimes(file, later, later))
+	}
+}
+
+// servedSerial connects to the listener and returns the serial number of the certificate the server presents.
+func servedSerial(t *testing.T, address string, roots *x509.CertPool) int64 {
+	conn, err := tls.Dial("tcp", address, &tls.Config{RootCAs: roots, ServerName: serverName, MinVersion: tls.VersionTLS12})
+	require.NoError(t, err)
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0].SerialNumber.Int64()
+}
+
+func TestReloader_ReloadsChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	authority := newCA(t, dir)
+	certFile, keyFile := authority.issue(t, dir, serverName, 100)
+
+	r, err := certs.NewReloader(certFile, keyFile, authority.file)
+	require.NoError(t, err)
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", r.ServerConfig(tls.NoClientCert))
+	require.NoError(t, err)
+	defer lis.Close()
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			// Finish the handshake so the client can see the certificate.
+			_ = conn.(*tls.Conn).Handshake()
+			_ = conn.Close()
+		}
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(authority.cert)
+	assert.EqualValues(t, 100, servedSerial(t, lis.Addr().String(), roots))
+
+	// Rotating the certificate on disk takes effect on the next connection.
+	authority.issue(t, dir, serverName, 101)
+	touch(t, certFile, keyFile)
+	assert.EqualValues(t, 101, servedSerial(t, lis.Addr().String(), roots))
+
+	// A broken certificate is ignored, and we keep serving the last good one.
+	require.NoError(t, os.WriteFile(certFile, []byte("not a certificate"), 0o600))
+	touch(t, certFile)
+	assert.EqualValues(t, 101, servedSerial(t, lis.Addr().String(), roots))
+}
+
+func TestNewReloader_InvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	authority := newCA(t, dir)
+	certFile, keyFile := authority.issue(t, dir, serverName, 100)
+
+	_, err := certs.NewReloader(certFile, filepath.Join(dir, "missing.key"), "")
+	assert.Error(t, err)
+	_, err = certs.NewReloader(certFile, keyFile, keyFile)
+	assert.Error(t, err)
+	_, err = certs.NewReloader(keyFile, certFile, "")
+	assert.Error(t, err)
+}
+
+// startServer serves a Zookeeper server with the given credentials, and returns the options clients need to
+// connect to it.
+func startServer(t *testing.T, creds credentials.TransportCredentials) []grpc.DialOption {
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer(grpc.Creds(creds))
+	zk := server.NewServer()
+	pbzk.RegisterZookeeperServer(s, zk)
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(func() {
+		s.Stop()
+		zk.Close()
+	})
+	return []grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+	}
+}
+
+func newClient(t *testing.T, dialOptions []grpc.DialOption, tlsConfig *tls.Config) *zkc.Client {
+	opts := []zkc.Option{
+		zkc.WithDialOptions(dialOptions...),
+		zkc.WithDialTimeout(time.Second),
+	}
+	if tlsConfig != nil {
+		opts = append(opts, zkc.WithTLSConfig(tlsConfig))
+	}
+	client, err := zkc.NewClient([]string{"passthrough:///" + serverName}, opts...)
+	require.NoError(t, err)
+	return client
+}
+
+func request(client *zkc.Client, req *pbzk.ZookeeperRequest) (*pbzk.ZookeeperResponse, error) {
+	err := client.Send(req)
+	if err != nil {
+		return nil, err
+	}
+	return client.Recv()
+}
+
+func TestMutualTLS(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	authority := newCA(t, dir)
+	serverCert, serverKey := authority.issue(t, dir, serverName, 100)
+	clientCert, clientKey := authority.issue(t, dir, "alice", 200)
+
+	serverReloader, err := certs.NewReloader(serverCert, serverKey, authority.file)
+	require.NoError(t, err)
+	dialOptions := startServer(t, certs.NewServerCredentials(serverReloader.ServerConfig(tls.RequireAndVerifyClientCert), false))
+
+	clientReloader, err := certs.NewReloader(clientCert, clientKey, authority.file)
+	require.NoError(t, err)
+	client := newClient(t, dialOptions, clientReloader.ClientConfig())
+	require.NoError(t, client.Connect(ctx))
+
+	// The client is identified by the subject of its certificate, so it can use nodes only it can access.
+	_, err = request(client, &pbzk.ZookeeperRequest{
+		Message: &pbzk.ZookeeperRequest_Create{
+			Create: &pbzk.CreateRequest{
+				Path: "/alice",
+				Data: []byte("data"),
+				Acl: []*pbzk.ACL{{
+					Perms: int32(pbzk.Permission_PERMISSION_ALL),
+					Id:    &pbzk.Id{Scheme: auth.SchemeX509, Id: "CN=alice,O=tenant1"},
+				}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	resp, err := request(client, &pbzk.ZookeeperRequest{
+		Message: &pbzk.ZookeeperRequest_GetData{
+			GetData: &pbzk.GetDataRequest{Path: "/alice"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), resp.GetGetData().GetData())
+	require.NoError(t, client.Close())
+
+	// Clients without a certificate are turned away.
+	roots := x509.NewCertPool()
+	roots.AddCert(authority.cert)
+	client = newClient(t, dialOptions, &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12})
+	assert.Error(t, client.Connect(ctx))
+
+	// So are plaintext clients.
+	client = newClient(t, dialOptions, nil)
+	assert.Error(t, client.Connect(ctx))
+}
+
+func TestNewServerCredentials_AllowPlaintext(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	authority := newCA(t, dir)
+	serverCert, serverKey := authority.issue(t, dir, serverName, 100)
+
+	r, err := certs.NewReloader(serverCert, serverKey, authority.file)
+	require.NoError(t, err)
+	dialOptions := startServer(t, certs.NewServerCredentials(r.ServerConfig(tls.NoClientCert), true))
+
+	roots := x509.NewCertPool()
+	roots.AddCert(authority.cert)
+	for name, tlsConfig := range map[string]*tls.Config{
+		"tls":       {RootCAs: roots, MinVersion: tls.VersionTLS12},
+		"plaintext": nil,
+	} {
+		t.Run(name, func(t *testing.T) {
+			client := newClient(t, dialOptions, tlsConfig)
+			require.NoError(t, client.Connect(ctx))
+			_, err := request(client, &pbzk.ZookeeperRequest{
+				Message: &pbzk.ZookeeperRequest_Exists{
+					Exists: &pbzk.ExistsRequest{Path: "/zookeeper"},
+				},
+			})
+			assert.NoError(t, err)
+			require.NoError(t, client.Close())
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package certs
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"net"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// tlsRecordTypeHandshake is the first byte a client sends when it starts a TLS handshake.
+const tlsRecordTypeHandshake = 0x16
+
+// NewServerCredentials returns the gRPC credentials for serving over TLS. If allowPlaintext is set, then
+// clients can also connect without TLS on the same port. This is useful while moving clients over to TLS.
+func NewServerCredentials(config *tls.Config, allowPlaintext bool) credentials.TransportCredentials {
+	creds := credentials.NewTLS(config)
+	if !allowPlaintext {
+		return creds
+	}
+	return &unifiedCredentials{
+		TransportCredentials: creds,
+		plaintext:            insecure.NewCredentials(),
+	}
+}
+
+// unifiedCredentials looks at the first byte the client sends to decide whether it is starting a TLS
+// handshake, or talking to us in plaintext.
+type unifiedCredentials struct {
+	credentials.TransportCredentials
+	plaintext credentials.TransportCredentials
+}
+
+func (c *unifiedCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	r := bufio.NewReader(conn)
+	first, err := r.Peek(1)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The byte we peeked at still has to be read by whoever handles the connection.
+	conn = &peekedConn{Conn: conn, r: r}
+	if first[0] == tlsRecordTypeHandshake {
+		return c.TransportCredentials.ServerHandshake(conn)
+	}
+	return c.plaintext.ServerHandshake(conn)
+}
+
+func (c *unifiedCredentials) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return c.TransportCredentials.ClientHandshake(ctx, authority, conn)
+}
+
+func (c *unifiedCredentials) Clone() credentials.TransportCredentials {
+	return &unifiedCredentials{
+		TransportCredentials: c.TransportCredentials.Clone(),
+		plaintext:            c.plaintext.Clone(),
+	}
+}
+
+// peekedConn reads through the buffered reader we used to peek at the start of the connection.
+type peekedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *peekedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
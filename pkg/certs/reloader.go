@@ -0,0 +1,156 @@
+// Package certs loads the certificates we use for TLS, and reloads them when they change on disk so
+// certificates can be rotated without restarting the server.
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Reloader holds a certificate, its private key, and the CAs we trust. Every TLS handshake checks whether
+// the files have changed, and reloads them if they have. If the new files can't be loaded, such as while
+// they are only partly written, then we keep using the old ones.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	// caFile is optional. Without it, we use the system's CAs.
+	caFile string
+
+	mu   *sync.Mutex
+	cert *tls.Certificate
+	cas  *x509.CertPool
+	// modTimes are the modification times of the files when we last loaded them.
+	modTimes map[string]time.Time
+}
+
+// NewReloader loads the files. Unlike later reloads, this fails if any of the files are invalid.
+func NewReloader(certFile string, keyFile string, caFile string) (*Reloader, error) {
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+		mu:       &sync.Mutex{},
+	}
+	err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Reloader) files() []string {
+	files := []string{r.certFile, r.keyFile}
+	if r.caFile != "" {
+		files = append(files, r.caFile)
+	}
+	return files
+}
+
+// load reads every file, and only replaces what we have if they are all valid.
+func (r *Reloader) load() error {
+	modTimes := map[string]time.Time{}
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("error reading [%s]: %w", file, err)
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("error loading certificate: %w", err)
+	}
+	var cas *x509.CertPool
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("error reading CA file: %w", err)
+		}
+		cas = x509.NewCertPool()
+		if !cas.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in CA file [%s]", r.caFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.cas = cas
+	r.modTimes = modTimes
+	return nil
+}
+
+// changed returns whether any of the files were modified since we last loaded them.
+func (r *Reloader) changed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			// The file is likely being replaced. Try again on the next handshake.
+			return false
+		}
+		if !info.ModTime().Equal(r.modTimes[file]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Reloader) reloadIfChanged() {
+	if !r.changed() {
+		return
+	}
+	err := r.load()
+	if err != nil {
+		log.Printf("Keeping the current certificate since the new one failed to load: %v\n", err)
+		return
+	}
+	log.Printf("Reloaded certificate [%s]\n", r.certFile)
+}
+
+func (r *Reloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.reloadIfChanged()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cert, r.cas
+}
+
+// ServerConfig returns a TLS config for accepting connections. Client certificates are verified against
+// the CAs according to clientAuth.
+func (r *Reloader) ServerConfig(clientAuth tls.ClientAuthType) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// Build a new config for each connection so that it uses the latest files.
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, cas := r.current()
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+				ClientCAs:    cas,
+				ClientAuth:   clientAuth,
+			}, nil
+		},
+	}
+}
+
+// ClientConfig returns a TLS config for making connections. We present our certificate if the server asks
+// for one, and verify the server against the CAs.
+func (r *Reloader) ClientConfig() *tls.Config {
+	_, cas := r.current()
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// The trusted CAs can't change after the config is created, so new CAs only take effect for new clients.
+		RootCAs: cas,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := r.current()
+			return cert, nil
+		},
+	}
+}
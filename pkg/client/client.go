@@ -5,26 +5,48 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"sync"
 	"time"
 
-	"github.com/google/uuid"
 	pbzk "github.com/mikekulinski/zookeeper/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 )
 
 const (
+	// IdleTimeout is how long we wait to hear from the server before it has told us our session timeout. Once
+	// it has, we wait for two thirds of the session timeout instead, the same as Zookeeper, so we move on to
+	// another server before the session expires.
 	IdleTimeout = 3000 * time.Millisecond
+	// ReconnectTimeout is how long we keep trying to reconnect after losing the connection to the server
+	// with the default retry policy.
+	ReconnectTimeout = IdleTimeout
 )
 
 var (
 	ErrIdleTimeout = fmt.Errorf("timed out waiting for server to respond")
+	// ErrSessionExpired is returned once the server has closed our session, so we can't resume it.
+	ErrSessionExpired = fmt.Errorf("session expired")
+	// ErrAuthFailed is returned once the server has rejected our credentials.
+	ErrAuthFailed = fmt.Errorf("authentication failed")
+	// ErrNoAddresses is returned when creating a client without any servers to connect to.
+	ErrNoAddresses = fmt.Errorf("no server addresses")
+	// ErrNoWatcher is returned when removing a watch that we haven't set.
+	ErrNoWatcher = fmt.Errorf("no watcher")
+	// ErrNotConnected is returned for requests that need a live connection to a server.
+	ErrNotConnected = fmt.Errorf("not connected to a server")
 )
 
 type internalResponse struct {
 	zkResponse *pbzk.ZookeeperResponse
 	err        error
+	// eof is set once the server has finished sending us messages after we closed the stream.
+	eof bool
+	// generation is the connection this response came from. We ignore anything from older connections.
+	generation int
 }
 
 // GetZkResponse returns the zookeeper response. We take advantage of nil pointer receivers
@@ -37,10 +59,46 @@ func (i *internalResponse) GetZkResponse() *pbzk.ZookeeperResponse {
 }
 
 type Client struct {
+	// ZookeeperClient is the server we are currently connected to.
 	pbzk.ZookeeperClient
+	// servers are all the servers we can connect to. We move on to the next one whenever we lose
+	// the connection to the current one.
+	servers []pbzk.ZookeeperClient
+	// server is the index of the current server in servers.
+	server int
+	// conns are the connections to each of the servers.
+	conns   []*grpc.ClientConn
+	options options
 
-	clientID string
-	stream   pbzk.Zookeeper_MessageClient
+	// ctx is the context passed to Connect. Every stream we open is derived from it.
+	ctx context.Context
+
+	// mu protects all the fields below, which are shared between the goroutines sending and receiving
+	// messages and the one reconnecting to the servers.
+	mu     *sync.Mutex
+	stream pbzk.Zookeeper_MessageClient
+	// cancelStream cancels the context of the current stream.
+	cancelStream context.CancelFunc
+	// generation is incremented each time we open a new stream.
+	generation int
+	// sessionID and password identify our session. We need both to resume the session.
+	sessionID string
+	password  string
+	// lastZxid is the latest zxid we've seen from any server.
+	lastZxid int64
+	// sessionTimeout is the session timeout the server gave us.
+	sessionTimeout time.Duration
+	// nextXid is the id we'll give the next request we send.
+	nextXid int64
+	// pending are the requests we've sent but haven't gotten a response for yet, in the order we sent them.
+	// We resend these after reconnecting.
+	pending []*pbzk.ZookeeperRequest
+	// state is the current state of our session.
+	state State
+	// stateChanges gets every state we move to.
+	stateChanges chan State
+	// watches are the watches we've set on the server.
+	watches *watchRegistry
 
 	// Channel of outgoing requests.
 	out chan *pbzk.ZookeeperRequest
@@ -48,58 +106,293 @@ type Client struct {
 	in chan *internalResponse
 	// Channel of the responses to return to the client.
 	responses chan *internalResponse
+	// done is closed once we stop reading from in, so the goroutines receiving messages don't block forever.
+	done chan struct{}
 	// Channel used during clean up to indicate that we have finished flushing all outgoing messages and
 	// we can safely call SendClose().
 	outboundFlushed chan bool
 }
 
-func NewClient(endpoint string) *Client {
-	clientID := uuid.New().String()
+// NewClient creates a client for the servers at the given addresses. i.e. "zk1:2181". We connect to
+// the first one, and move on to the next one each time we lose the connection.
+func NewClient(addresses []string, opts ...Option) (*Client, error) {
+	if len(addresses) == 0 {
+		return nil, ErrNoAddresses
+	}
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
 
-	// Set up a connection to the server.
-	conn, err := grpc.Dial(
-		endpoint+":8080",
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithChainStreamInterceptor(clientIDStreamInterceptor(clientID)))
-	if err != nil {
-		log.Fatalf("did not connect: %v", err)
+	creds := insecure.NewCredentials()
+	if o.tlsConfig != nil {
+		creds = credentials.NewTLS(o.tlsConfig)
+	}
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+	}
+	dialOpts = append(dialOpts, o.dialOptions...)
+
+	// Set up a connection to each server. Dialing doesn't block, so this is cheap.
+	var conns []*grpc.ClientConn
+	var servers []pbzk.ZookeeperClient
+	for _, address := range addresses {
+		conn, err := grpc.Dial(address, dialOpts...)
+		if err != nil {
+			for _, c := range conns {
+				_ = c.Close()
+			}
+			return nil, fmt.Errorf("error dialing server [%s]: %w", address, err)
+		}
+		conns = append(conns, conn)
+		servers = append(servers, pbzk.NewZookeeperClient(conn))
 	}
-	grpcClient := pbzk.NewZookeeperClient(conn)
 
-	c := &Client{
-		ZookeeperClient: grpcClient,
-		clientID:        clientID,
+	c := newClient(servers, o)
+	c.conns = conns
+	return c, nil
+}
+
+func newClient(servers []pbzk.ZookeeperClient, o options) *Client {
+	return &Client{
+		ZookeeperClient: servers[0],
+		servers:         servers,
+		options:         o,
+		mu:              &sync.Mutex{},
+		stateChanges:    make(chan State, StateChangesBufferSize),
+		watches:         newWatchRegistry(),
 		out:             make(chan *pbzk.ZookeeperRequest),
 		in:              make(chan *internalResponse),
 		responses:       make(chan *internalResponse),
+		done:            make(chan struct{}),
 		outboundFlushed: make(chan bool),
 	}
-	return c
 }
 
 // Connect actually establishes a live connection with the Zookeeper server.
 func (c *Client) Connect(ctx context.Context) error {
+	// TODO: Find a way to prevent this from being called twice.
+	c.ctx = ctx
+
 	// Initiate the stream with the Zookeeper server.
-	stream, err := c.ZookeeperClient.Message(ctx)
+	err := c.connect()
 	if err != nil {
-		return fmt.Errorf("error initializing the stream with the server")
+		err = c.handleConnectionError(err)
+		return fmt.Errorf("error initializing the stream with the server: %w", err)
 	}
 
-	// TODO: Find a way to prevent this from being called twice.
-	c.stream = stream
-
 	go c.continuouslySendMessages()
-	go c.continuouslyReceiveMessages()
 	go c.continuouslyReturnMessagesToClient()
 	return nil
 }
 
+// connect opens a new stream with the current server. If we already have a session, then we resume it
+// and resend every request that we haven't gotten a response for.
+func (c *Client) connect() error {
+	c.mu.Lock()
+	connect := &pbzk.ConnectRequest{
+		TimeoutMs: c.options.sessionTimeout.Milliseconds(),
+		SessionId: c.sessionID,
+		Password:  c.password,
+		LastZxid:  c.lastZxid,
+	}
+	c.mu.Unlock()
+	// The server forgets our watches along with the connection, so set them again. This is empty when
+	// we first connect.
+	c.watches.setWatches(connect)
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	stream, err := c.ZookeeperClient.Message(ctx)
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	// Don't wait forever for the server to accept the connection.
+	timer := time.AfterFunc(c.options.dialTimeout, cancel)
+	resp, err := handshake(stream, connect)
+	timer.Stop()
+	if err != nil {
+		cancel()
+		if status.Code(err) == codes.NotFound {
+			return ErrSessionExpired
+		}
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cancelStream != nil {
+		c.cancelStream()
+	}
+	c.stream = stream
+	c.cancelStream = cancel
+	c.sessionID = resp.GetConnect().GetSessionId()
+	c.password = resp.GetConnect().GetPassword()
+	c.sessionTimeout = time.Duration(resp.GetConnect().GetTimeoutMs()) * time.Millisecond
+	c.lastZxid = max(c.lastZxid, resp.GetZxid())
+	c.setStateLocked(State_CONNECTED)
+	c.generation++
+	for _, req := range c.pending {
+		err := c.stream.Send(req)
+		if err != nil {
+			// We'll try again on the next connection.
+			c.options.logger.Printf("Error resending request to the client stream: %+v\n", err)
+			break
+		}
+	}
+	go c.continuouslyReceiveMessages(stream, c.generation)
+	return nil
+}
+
+// handshake sends the connect request, which has to be the first message on the stream, and waits
+// for the server to accept it.
+func handshake(stream pbzk.Zookeeper_MessageClient, connect *pbzk.ConnectRequest) (*pbzk.ZookeeperResponse, error) {
+	err := stream.Send(&pbzk.ZookeeperRequest{
+		Message: &pbzk.ZookeeperRequest_Connect{
+			Connect: connect,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if resp.GetConnect() == nil {
+		return nil, fmt.Errorf("expected a connect response from the server, got [%+v]", resp)
+	}
+	return resp, nil
+}
+
+// reconnect tries to resume our session on the next server, cycling through the servers until one
+// accepts us or we run out of time.
+func (c *Client) reconnect() error {
+	policy := c.options.retryPolicy
+	deadline := time.Now().Add(policy.Timeout)
+	for attempt := 0; time.Now().Before(deadline); attempt++ {
+		if len(c.servers) > 0 {
+			c.server = (c.server + 1) % len(c.servers)
+			c.ZookeeperClient = c.servers[c.server]
+		}
+		c.setState(State_CONNECTING)
+		err := c.connect()
+		if err == nil {
+			return nil
+		}
+		if isSessionError(err) {
+			return err
+		}
+		c.options.logger.Printf("Error reconnecting to the server: %+v\n", err)
+		time.Sleep(policy.backoff(attempt))
+	}
+	return ErrIdleTimeout
+}
+
+// isSessionError returns whether the error means that our session can't be used anymore, no matter
+// which server we connect to.
+func isSessionError(err error) bool {
+	code := status.Code(err)
+	return errors.Is(err, ErrSessionExpired) || code == codes.NotFound || code == codes.PermissionDenied || code == codes.Unauthenticated
+}
+
+// isConnectionLost returns whether the stream ended because of the connection rather than our session, so we
+// can resume the session on another connection. i.e. The server drops the connection without closing the
+// session when we fall too far behind on reading our messages.
+func isConnectionLost(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Internal:
+		return true
+	default:
+		return false
+	}
+}
+
+// handleConnectionError moves to the state that matches why we lost the connection, and returns the
+// error to give the caller.
+func (c *Client) handleConnectionError(err error) error {
+	switch {
+	case errors.Is(err, ErrSessionExpired) || status.Code(err) == codes.NotFound:
+		c.setState(State_EXPIRED)
+		return ErrSessionExpired
+	case status.Code(err) == codes.PermissionDenied || status.Code(err) == codes.Unauthenticated:
+		c.setState(State_AUTH_FAILED)
+		return fmt.Errorf("%w: %w", ErrAuthFailed, err)
+	default:
+		c.setState(State_DISCONNECTED)
+		return err
+	}
+}
+
+// State returns the current state of our session.
+func (c *Client) State() State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// StateChanges returns a channel that gets every state the client moves to, in order. Callers
+// should keep reading from it, since we drop the oldest state changes once the buffer fills up.
+// The channel is never closed, so stop reading once you see a terminal state.
+func (c *Client) StateChanges() <-chan State {
+	return c.stateChanges
+}
+
+func (c *Client) setState(state State) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setStateLocked(state)
+}
+
+func (c *Client) setStateLocked(state State) {
+	// Once we are in a terminal state, there's nowhere else to go.
+	if c.state == state || c.state.IsTerminal() {
+		return
+	}
+	c.state = state
+	for {
+		select {
+		case c.stateChanges <- state:
+			return
+		default:
+			// Nobody is keeping up with the state changes, so make room by dropping the oldest one.
+			select {
+			case dropped := <-c.stateChanges:
+				c.options.logger.Printf("Dropping state change to [%s] since nobody is reading state changes\n", dropped)
+			default:
+			}
+		}
+	}
+}
+
 // Send will enqueue a new message to be sent to the server.
 func (c *Client) Send(request *pbzk.ZookeeperRequest) error {
 	c.out <- request
 	return nil
 }
 
+// RemoveWatches removes the watches of the given type that we've set on path. Their events stop being returned
+// by Recv as soon as this returns, and the server is told to stop sending them. The server's response is
+// returned by Recv like any other. If local is false, then this fails while we aren't connected to a server.
+// If local is true, then the watches are removed anyway, and the server is told once we reconnect.
+func (c *Client) RemoveWatches(path string, watcherType pbzk.RemoveWatchesRequest_WatcherType, local bool) error {
+	if !local && c.State() != State_CONNECTED {
+		return ErrNotConnected
+	}
+	if !c.watches.remove(path, watcherType) {
+		return fmt.Errorf("%w: no watches of type [%s] on [%s]", ErrNoWatcher, watcherType, path)
+	}
+	return c.Send(&pbzk.ZookeeperRequest{
+		Message: &pbzk.ZookeeperRequest_RemoveWatches{
+			RemoveWatches: &pbzk.RemoveWatchesRequest{
+				Path: path,
+				Type: watcherType,
+			},
+		},
+	})
+}
+
 // Recv tries to receive the latest message from the channel of response we have
 // gotten from the server.
 func (c *Client) Recv() (*pbzk.ZookeeperResponse, error) {
@@ -114,10 +407,30 @@ func (c *Client) Recv() (*pbzk.ZookeeperResponse, error) {
 	return resp.zkResponse, nil
 }
 
+// ResponseError returns the error the server sent in place of the response to a request that failed, or nil
+// if the request succeeded. A failed request doesn't affect the session, so the client can keep using it.
+func ResponseError(resp *pbzk.ZookeeperResponse) error {
+	e := resp.GetError()
+	if e == nil {
+		return nil
+	}
+	return status.Error(codes.Code(e.GetCode()), e.GetMessage())
+}
+
 // Close will close the stream to tell the server that we're no longer going to be sending
 // more messages. It will also close the channel we use for sending outgoing messages
 // so we can properly clean up the goroutine that reads from it.
 func (c *Client) Close() error {
+	// If we never connected, then there's no stream to close, and nothing is running in the background.
+	c.mu.Lock()
+	connected := c.stream != nil
+	c.mu.Unlock()
+	if !connected {
+		c.setState(State_CLOSED)
+		c.closeConns()
+		return nil
+	}
+
 	// Close the outgoing channel, so we will stop our long-running goroutines.
 	close(c.out)
 
@@ -125,6 +438,9 @@ func (c *Client) Close() error {
 	<-c.outboundFlushed
 
 	// Close the send side of the stream to clean up the gRPC connection with the server.
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setStateLocked(State_CLOSED)
 	err := c.stream.CloseSend()
 	if err != nil {
 		return fmt.Errorf("error closing send: %w", err)
@@ -132,6 +448,33 @@ func (c *Client) Close() error {
 	return nil
 }
 
+func (c *Client) closeConns() {
+	for _, conn := range c.conns {
+		err := conn.Close()
+		if err != nil {
+			c.options.logger.Printf("Error closing the connection to the server: %+v\n", err)
+		}
+	}
+}
+
+// send sends the request on the current stream. Requests from the client are remembered until we get
+// a response, so we can resend them if we lose the connection.
+func (c *Client) send(req *pbzk.ZookeeperRequest) error {
+	c.mu.Lock()
+	if _, ok := req.GetMessage().(*pbzk.ZookeeperRequest_Heartbeat); !ok {
+		c.nextXid++
+		req.Xid = c.nextXid
+		c.pending = append(c.pending, req)
+	}
+	c.watches.add(req)
+	stream := c.stream
+	c.mu.Unlock()
+
+	// Send blocks while the server isn't reading, so don't hold the lock and stop us from reconnecting. If
+	// we reconnect in the meantime, the request is already pending, so it is resent on the new stream.
+	return stream.Send(req)
+}
+
 // continuouslySendMessages will continuously try to send messages from our client to the server.
 // If we haven't received anything from the client to send, then we will send heartbeat messages
 // to keep the connection alive.
@@ -146,14 +489,13 @@ func (c *Client) continuouslySendMessages() {
 			if !ok {
 				return
 			}
-			// The client elected to send a message. Send that to the server.
-			err := c.stream.Send(m)
+			// The client elected to send a message. Send that to the server. If this fails, then the
+			// request is resent once we reconnect.
+			err := c.send(m)
 			if err != nil {
-				// TODO: Find a way to get this back to the client.
-				log.Printf("Error sending message to the client stream: %+v\n", err)
-				return
+				c.options.logger.Printf("Error sending message to the client stream: %+v\n", err)
 			}
-		case <-time.After(IdleTimeout / 3):
+		case <-time.After(c.heartbeatInterval()):
 			// Send a heartbeat to keep the connection alive since we haven't sent a message in a bit.
 			heartbeat := &pbzk.ZookeeperRequest{
 				Message: &pbzk.ZookeeperRequest_Heartbeat{
@@ -162,61 +504,149 @@ func (c *Client) continuouslySendMessages() {
 					},
 				},
 			}
-			err := c.stream.Send(heartbeat)
+			err := c.send(heartbeat)
 			if err != nil {
-				log.Printf("Error sending heartbeat to the client stream: %+v\n", err)
-				return
+				c.options.logger.Printf("Error sending heartbeat to the client stream: %+v\n", err)
+				continue
 			}
-			log.Println("Sent heartbeat")
+			c.options.logger.Println("Sent heartbeat")
+		}
+	}
+}
+
+// idleTimeout returns how long we wait to hear from the server before we give up on it.
+func (c *Client) idleTimeout() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sessionTimeout == 0 {
+		return IdleTimeout
+	}
+	return c.sessionTimeout * 2 / 3
+}
+
+// heartbeatInterval returns how long we wait before sending a heartbeat. We send a few heartbeats
+// within our idle timeout, which is shorter than the session timeout, so neither side gives up on the other.
+func (c *Client) heartbeatInterval() time.Duration {
+	return c.idleTimeout() / 3
+}
+
+// handleResponse records what we learned from a response from the server. It returns false if the
+// response came from a connection we've already replaced.
+func (c *Client) handleResponse(resp *internalResponse) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if resp.generation != c.generation {
+		return false
+	}
+	zkResp := resp.GetZkResponse()
+	c.lastZxid = max(c.lastZxid, zkResp.GetZxid())
+	// The server answers requests in order, so every request before this one has either been answered, or was
+	// dropped by the server because it no longer had the response to a request we resent.
+	xid := zkResp.GetXid()
+	for xid != 0 && len(c.pending) > 0 && c.pending[0].GetXid() <= xid {
+		req := c.pending[0]
+		c.pending = c.pending[1:]
+		if req.GetXid() == xid {
+			c.watches.existsResponse(req, zkResp)
+		} else {
+			c.options.logger.Printf("Never got a response to request [%d]\n", req.GetXid())
 		}
 	}
+	return true
 }
 
 // continuouslyReturnMessagesToClient will try reading from our channel of incoming
 // responses from the server. We use this channel so that we can time out if we haven't
-// received a response in a long time.
+// received a response in a long time. If we lose the connection, then we try to resume
+// our session on another server.
 func (c *Client) continuouslyReturnMessagesToClient() {
 	defer close(c.responses)
+	defer close(c.done)
+	// We're done with the servers once we stop getting messages from them.
+	defer c.closeConns()
 	for {
 		select {
-		case resp, ok := <-c.in:
-			// If our other goroutine closed the channel, then stop trying to process messages.
-			if !ok {
+		case resp := <-c.in:
+			if !c.handleResponse(resp) {
+				// This is from a stream we already replaced, so ignore it.
+				continue
+			}
+			if resp.eof {
+				// The server is done sending messages now that we closed the stream.
 				return
 			}
+			if resp.err != nil {
+				if !isConnectionLost(resp.err) {
+					c.responses <- &internalResponse{err: c.handleConnectionError(resp.err)}
+					return
+				}
+				// We lost the connection to the server. Try to pick up where we left off on another server.
+				c.options.logger.Printf("Lost connection to the server: %+v\n", resp.err)
+				c.setState(State_DISCONNECTED)
+				if err := c.reconnect(); err != nil {
+					c.responses <- &internalResponse{err: c.handleConnectionError(err)}
+					return
+				}
+				continue
+			}
 
 			switch resp.GetZkResponse().GetMessage().(type) {
 			case *pbzk.ZookeeperResponse_Heartbeat:
 				// Do nothing for heartbeat responses.
 				continue
+			case *pbzk.ZookeeperResponse_WatchEvent:
+				// Drop the events for watches we've removed, since the server may have sent them before it
+				// heard about the removal.
+				if !c.watches.fire(resp.GetZkResponse().GetWatchEvent()) {
+					continue
+				}
+				c.responses <- resp
 			default:
 				// Enqueue the response to be sent back to the client.
 				c.responses <- resp
 			}
-		case <-time.After(IdleTimeout):
-			// We timed out waiting for the server to respond.
-			// TODO: Find a new server once the server is distributed.
-			c.responses <- &internalResponse{err: ErrIdleTimeout}
-			return
+		case <-time.After(c.idleTimeout()):
+			// We timed out waiting for the server to respond, so try another server.
+			c.options.logger.Println("Timed out waiting for the server to respond")
+			c.setState(State_DISCONNECTED)
+			if err := c.reconnect(); err != nil {
+				c.responses <- &internalResponse{err: c.handleConnectionError(err)}
+				return
+			}
 		}
 	}
 }
 
 // continuouslyReceiveMessages will try to receive the responses we get from the server
 // and will enqueue them to be sent back to the client.
-func (c *Client) continuouslyReceiveMessages() {
-	defer close(c.in)
+func (c *Client) continuouslyReceiveMessages(stream pbzk.Zookeeper_MessageClient, generation int) {
 	for {
-		resp, err := c.stream.Recv()
+		resp, err := stream.Recv()
 		if errors.Is(err, io.EOF) {
+			c.deliver(&internalResponse{eof: true, generation: generation})
 			return
 		}
 		if err != nil {
-			c.in <- &internalResponse{
-				err: fmt.Errorf("error receiving message from client stream: %w", err),
-			}
+			c.deliver(&internalResponse{
+				err:        fmt.Errorf("error receiving message from client stream: %w", err),
+				generation: generation,
+			})
+			return
+		}
+		if !c.deliver(&internalResponse{zkResponse: resp, generation: generation}) {
 			return
 		}
-		c.in <- &internalResponse{zkResponse: resp}
+	}
+}
+
+// deliver hands the message to continuouslyReturnMessagesToClient. It returns false if that has already
+// stopped, since nobody is left to read the message.
+func (c *Client) deliver(resp *internalResponse) bool {
+	select {
+	case c.in <- resp:
+		return true
+	case <-c.done:
+		return false
 	}
 }
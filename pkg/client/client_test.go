@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"io"
 	"testing"
 	"time"
 
@@ -10,8 +11,30 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// testSessionTimeout is the session timeout the mock servers give clients. It's short so that tests waiting
+// for the client to give up on a server don't take long.
+const testSessionTimeout = 1500 * time.Millisecond
+
+func newTestClient(servers ...pbzk.ZookeeperClient) *Client {
+	return newClient(servers, defaultOptions())
+}
+
+func connectResponse(sessionID string, password string) *pbzk.ZookeeperResponse {
+	return &pbzk.ZookeeperResponse{
+		Message: &pbzk.ZookeeperResponse_Connect{
+			Connect: &pbzk.ConnectResponse{
+				TimeoutMs: testSessionTimeout.Milliseconds(),
+				SessionId: sessionID,
+				Password:  password,
+			},
+		},
+	}
+}
+
 func TestClient_IdleTimeout(t *testing.T) {
 	ctx := context.Background()
 	ctrl := gomock.NewController(t)
@@ -19,27 +42,321 @@ func TestClient_IdleTimeout(t *testing.T) {
 	mockGrpcClient := mock_proto.NewMockZookeeperClient(ctrl)
 	mockStream := mock_proto.NewMockZookeeper_MessageClient(ctrl)
 
-	client := &Client{
-		ZookeeperClient: mockGrpcClient,
-		out:             make(chan *pbzk.ZookeeperRequest),
-		in:              make(chan *internalResponse),
-		responses:       make(chan *internalResponse),
-	}
+	client := newTestClient(mockGrpcClient)
 
-	// Set up connect to a mock version of the stream.
-	mockGrpcClient.EXPECT().Message(ctx).Return(mockStream, nil)
-	err := client.Connect(ctx)
-	require.NoError(t, err)
-
-	// We expect the client to try sending some heartbeats to the server.
+	// Set up connect to a mock version of the stream. Once we time out, we can't reach the server again.
+	mockGrpcClient.EXPECT().Message(gomock.Any()).Return(mockStream, nil)
+	mockGrpcClient.EXPECT().Message(gomock.Any()).Return(nil, status.Error(codes.Unavailable, "unavailable")).AnyTimes()
+	// We expect the client to connect, and then try sending some heartbeats to the server.
 	mockStream.EXPECT().Send(gomock.Any()).Return(nil).AnyTimes()
+	mockStream.EXPECT().Recv().Return(connectResponse("session", "password"), nil)
 	// Have Recv wait for longer than the timeout to verify that we will actually time out.
-	mockStream.EXPECT().Recv().DoAndReturn(func() {
-		time.Sleep(2 * IdleTimeout)
-	})
+	mockStream.EXPECT().Recv().DoAndReturn(func() (*pbzk.ZookeeperResponse, error) {
+		time.Sleep(2 * testSessionTimeout)
+		return nil, status.Error(codes.Canceled, "canceled")
+	}).AnyTimes()
+	err := client.Connect(ctx)
+	require.NoError(t, err)
+	// The idle timeout comes from the session timeout the server gave us.
+	assert.Equal(t, testSessionTimeout*2/3, client.idleTimeout())
 
 	// We expect to timeout here.
 	resp, err := client.Recv()
 	assert.Nil(t, resp)
 	assert.ErrorIs(t, err, ErrIdleTimeout)
 }
+
+func TestClient_CloseWithoutConnecting(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockGrpcClient := mock_proto.NewMockZookeeperClient(ctrl)
+	mockGrpcClient.EXPECT().Message(gomock.Any()).Return(nil, status.Error(codes.Unavailable, "unavailable"))
+
+	client := newTestClient(mockGrpcClient)
+	require.Error(t, client.Connect(context.Background()))
+	assert.NoError(t, client.Close())
+	assert.Equal(t, State_CLOSED, client.State())
+}
+
+func TestClient_DroppedRequest(t *testing.T) {
+	client := newTestClient(mock_proto.NewMockZookeeperClient(gomock.NewController(t)))
+	for xid := int64(1); xid <= 3; xid++ {
+		client.pending = append(client.pending, &pbzk.ZookeeperRequest{Xid: xid})
+	}
+	// The server dropped request 1 since it no longer had the response, and answered request 2.
+	assert.True(t, client.handleResponse(&internalResponse{zkResponse: &pbzk.ZookeeperResponse{Xid: 2}}))
+	require.Len(t, client.pending, 1)
+	assert.Equal(t, int64(3), client.pending[0].GetXid())
+}
+
+func TestClient_Reconnect(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+
+	firstServer := mock_proto.NewMockZookeeperClient(ctrl)
+	firstStream := mock_proto.NewMockZookeeper_MessageClient(ctrl)
+	secondServer := mock_proto.NewMockZookeeperClient(ctrl)
+	secondStream := mock_proto.NewMockZookeeper_MessageClient(ctrl)
+
+	client := newTestClient(firstServer, secondServer)
+
+	// The first server accepts the connection, but then goes away after getting our request.
+	sent := make(chan struct{})
+	firstServer.EXPECT().Message(gomock.Any()).Return(firstStream, nil)
+	firstStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *pbzk.ZookeeperRequest) error {
+		if req.GetCreate() != nil {
+			close(sent)
+		}
+		return nil
+	}).AnyTimes()
+	firstStream.EXPECT().Recv().Return(connectResponse("session", "password"), nil)
+	firstStream.EXPECT().Recv().DoAndReturn(func() (*pbzk.ZookeeperResponse, error) {
+		<-sent
+		return nil, status.Error(codes.Unavailable, "server went away")
+	})
+	require.NoError(t, client.Connect(ctx))
+
+	// We resume the session on the second server and resend the request we never got a response for.
+	secondServer.EXPECT().Message(gomock.Any()).Return(secondStream, nil)
+	resent := make(chan int64, 1)
+	secondStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *pbzk.ZookeeperRequest) error {
+		if req.GetConnect() != nil {
+			assert.Equal(t, "session", req.GetConnect().GetSessionId())
+			assert.Equal(t, "password", req.GetConnect().GetPassword())
+		}
+		if req.GetCreate() != nil {
+			resent <- req.GetXid()
+		}
+		return nil
+	}).AnyTimes()
+	secondStream.EXPECT().Recv().Return(connectResponse("session", "password"), nil)
+	secondStream.EXPECT().Recv().DoAndReturn(func() (*pbzk.ZookeeperResponse, error) {
+		return &pbzk.ZookeeperResponse{
+			Xid:  <-resent,
+			Zxid: 1,
+			Message: &pbzk.ZookeeperResponse_Create{
+				Create: &pbzk.CreateResponse{ZNodeName: "/zoo"},
+			},
+		}, nil
+	})
+	// The server is done once we close the stream.
+	closed := make(chan struct{})
+	secondStream.EXPECT().CloseSend().DoAndReturn(func() error {
+		close(closed)
+		return nil
+	})
+	secondStream.EXPECT().Recv().DoAndReturn(func() (*pbzk.ZookeeperResponse, error) {
+		<-closed
+		return nil, io.EOF
+	})
+
+	err := client.Send(&pbzk.ZookeeperRequest{
+		Message: &pbzk.ZookeeperRequest_Create{
+			Create: &pbzk.CreateRequest{Path: "/zoo"},
+		},
+	})
+	require.NoError(t, err)
+	resp, err := client.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "/zoo", resp.GetCreate().GetZNodeName())
+	assert.Equal(t, int64(1), resp.GetXid())
+
+	// Callers can see that we were briefly disconnected.
+	assert.Equal(t, State_CONNECTED, client.State())
+	assert.Equal(t, []State{
+		State_CONNECTED,
+		State_DISCONNECTED,
+		State_CONNECTING,
+		State_CONNECTED,
+	}, drainStateChanges(client))
+	require.NoError(t, client.Close())
+}
+
+// TestClient_SendBlockedDuringReconnect verifies that a send blocked on a stream that went away doesn't stop
+// us from reconnecting, and that the request is resent on the new stream.
+func TestClient_SendBlockedDuringReconnect(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+
+	firstServer := mock_proto.NewMockZookeeperClient(ctrl)
+	firstStream := mock_proto.NewMockZookeeper_MessageClient(ctrl)
+	secondServer := mock_proto.NewMockZookeeperClient(ctrl)
+	secondStream := mock_proto.NewMockZookeeper_MessageClient(ctrl)
+
+	client := newTestClient(firstServer, secondServer)
+
+	// The first server stops reading our messages and then goes away, so our request blocks until we let it go.
+	sending := make(chan struct{})
+	release := make(chan struct{})
+	firstServer.EXPECT().Message(gomock.Any()).Return(firstStream, nil)
+	firstStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *pbzk.ZookeeperRequest) error {
+		if req.GetCreate() != nil {
+			close(sending)
+			<-release
+			return status.Error(codes.Unavailable, "server went away")
+		}
+		return nil
+	}).AnyTimes()
+	firstStream.EXPECT().Recv().Return(connectResponse("session", "password"), nil)
+	firstStream.EXPECT().Recv().DoAndReturn(func() (*pbzk.ZookeeperResponse, error) {
+		<-sending
+		return nil, status.Error(codes.Unavailable, "server went away")
+	})
+	require.NoError(t, client.Connect(ctx))
+
+	secondServer.EXPECT().Message(gomock.Any()).Return(secondStream, nil)
+	resent := make(chan int64, 1)
+	secondStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *pbzk.ZookeeperRequest) error {
+		if req.GetCreate() != nil {
+			resent <- req.GetXid()
+		}
+		return nil
+	}).AnyTimes()
+	secondStream.EXPECT().Recv().Return(connectResponse("session", "password"), nil)
+	closed := make(chan struct{})
+	secondStream.EXPECT().CloseSend().DoAndReturn(func() error {
+		close(closed)
+		return nil
+	})
+	// We might close the client before the second stream gets to waiting for another message.
+	secondStream.EXPECT().Recv().DoAndReturn(func() (*pbzk.ZookeeperResponse, error) {
+		<-closed
+		return nil, io.EOF
+	}).MaxTimes(1)
+
+	err := client.Send(&pbzk.ZookeeperRequest{
+		Message: &pbzk.ZookeeperRequest_Create{
+			Create: &pbzk.CreateRequest{Path: "/zoo"},
+		},
+	})
+	require.NoError(t, err)
+
+	// We reconnect to the second server while the send to the first one is still blocked.
+	var states []State
+	timeout := time.After(5 * time.Second)
+	for len(states) < 4 {
+		select {
+		case state := <-client.StateChanges():
+			states = append(states, state)
+		case <-timeout:
+			require.FailNow(t, "timed out reconnecting", "states: %v", states)
+		}
+	}
+	assert.Equal(t, []State{
+		State_CONNECTED,
+		State_DISCONNECTED,
+		State_CONNECTING,
+		State_CONNECTED,
+	}, states)
+	assert.Equal(t, State_CONNECTED, client.State())
+	select {
+	case xid := <-resent:
+		assert.Equal(t, int64(1), xid)
+	case <-timeout:
+		require.FailNow(t, "timed out waiting for the request to be resent")
+	}
+
+	close(release)
+	require.NoError(t, client.Close())
+}
+
+func drainStateChanges(client *Client) []State {
+	var states []State
+	for {
+		select {
+		case state := <-client.StateChanges():
+			states = append(states, state)
+		default:
+			return states
+		}
+	}
+}
+
+func TestIsConnectionLost(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "server unavailable",
+			err:      status.Error(codes.Unavailable, "server went away"),
+			expected: true,
+		},
+		{
+			name:     "fell behind on reading messages",
+			err:      status.Error(codes.ResourceExhausted, "too many messages waiting to be sent to the client"),
+			expected: true,
+		},
+		{
+			name:     "stream reset",
+			err:      status.Error(codes.Internal, "stream terminated by RST_STREAM"),
+			expected: true,
+		},
+		{
+			name: "session expired",
+			err:  status.Error(codes.NotFound, "session expired"),
+		},
+		{
+			name: "auth failed",
+			err:  status.Error(codes.Unauthenticated, "authentication failed"),
+		},
+		{
+			name: "session moved",
+			err:  status.Error(codes.Aborted, "session moved to a new connection"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, isConnectionLost(test.err))
+		})
+	}
+}
+
+func TestClient_SessionExpired(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+
+	mockGrpcClient := mock_proto.NewMockZookeeperClient(ctrl)
+	mockStream := mock_proto.NewMockZookeeper_MessageClient(ctrl)
+
+	client := newTestClient(mockGrpcClient)
+
+	mockGrpcClient.EXPECT().Message(gomock.Any()).Return(mockStream, nil)
+	mockStream.EXPECT().Send(gomock.Any()).Return(nil).AnyTimes()
+	mockStream.EXPECT().Recv().Return(connectResponse("session", "password"), nil)
+	// The server expires our session.
+	mockStream.EXPECT().Recv().Return(nil, status.Error(codes.NotFound, "session expired"))
+	require.NoError(t, client.Connect(ctx))
+
+	_, err := client.Recv()
+	assert.ErrorIs(t, err, ErrSessionExpired)
+	assert.Equal(t, State_EXPIRED, client.State())
+	assert.Equal(t, []State{State_CONNECTED, State_EXPIRED}, drainStateChanges(client))
+
+	// We can't leave the expired state.
+	client.setState(State_CONNECTED)
+	assert.Equal(t, State_EXPIRED, client.State())
+}
+
+func TestClient_StateChangesDropOldest(t *testing.T) {
+	client := newTestClient(mock_proto.NewMockZookeeperClient(gomock.NewController(t)))
+	for i := 0; i < StateChangesBufferSize; i++ {
+		client.setState(State_DISCONNECTED)
+		client.setState(State_CONNECTING)
+	}
+	client.setState(State_CLOSED)
+
+	states := drainStateChanges(client)
+	assert.Len(t, states, StateChangesBufferSize)
+	assert.Equal(t, State_CLOSED, states[len(states)-1])
+	assert.Equal(t, State_CLOSED, client.State())
+}
+
+func TestClient_RemoveWatches_NotConnected(t *testing.T) {
+	client := newTestClient(mock_proto.NewMockZookeeperClient(gomock.NewController(t)))
+	err := client.RemoveWatches("/zoo", pbzk.RemoveWatchesRequest_WATCHER_TYPE_ANY, false)
+	assert.ErrorIs(t, err, ErrNotConnected)
+	// Removing locally doesn't need a connection, but there has to be a watch to remove.
+	err = client.RemoveWatches("/zoo", pbzk.RemoveWatchesRequest_WATCHER_TYPE_ANY, true)
+	assert.ErrorIs(t, err, ErrNoWatcher)
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"crypto/tls"
+	"log"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	// DefaultDialTimeout is how long we wait for a server to accept a connection by default.
+	DefaultDialTimeout = IdleTimeout
+	// DefaultSessionTimeout is the session timeout we ask the server for by default.
+	DefaultSessionTimeout = 10 * time.Second
+)
+
+// DefaultRetryPolicy is the retry policy we use to reconnect if the caller doesn't pick one.
+var DefaultRetryPolicy = RetryPolicy{
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     time.Second,
+	Timeout:        ReconnectTimeout,
+}
+
+// RetryPolicy decides how we retry connecting to the servers after losing the connection.
+type RetryPolicy struct {
+	// InitialBackoff is how long we wait after the first failed attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff is the longest we wait between attempts. The backoff doubles after each failed
+	// attempt until it reaches MaxBackoff.
+	MaxBackoff time.Duration
+	// Timeout is how long we keep trying before giving up.
+	Timeout time.Duration
+}
+
+// backoff returns how long to wait after the given failed attempt, starting from 0.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 0; i < attempt && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, p.MaxBackoff)
+}
+
+type options struct {
+	dialTimeout    time.Duration
+	sessionTimeout time.Duration
+	tlsConfig      *tls.Config
+	logger         *log.Logger
+	dialOptions    []grpc.DialOption
+	retryPolicy    RetryPolicy
+}
+
+func defaultOptions() options {
+	return options{
+		dialTimeout:    DefaultDialTimeout,
+		sessionTimeout: DefaultSessionTimeout,
+		logger:         log.Default(),
+		retryPolicy:    DefaultRetryPolicy,
+	}
+}
+
+// Option configures a Client.
+type Option func(*options)
+
+// WithDialTimeout sets how long we wait for a server to accept a connection before trying the next one.
+func WithDialTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.dialTimeout = timeout
+	}
+}
+
+// WithSessionTimeout sets the session timeout we ask the server for. The server may give us a
+// different timeout if this is outside the range it allows.
+func WithSessionTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.sessionTimeout = timeout
+	}
+}
+
+// WithTLSConfig connects to the servers over TLS with the given config. Without this, we connect
+// without any transport security.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *options) {
+		o.tlsConfig = config
+	}
+}
+
+// WithLogger sets the logger the client writes to.
+func WithLogger(logger *log.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithDialOptions adds gRPC dial options. These are applied after our own, so they can be used to
+// override how we connect to the servers.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *options) {
+		o.dialOptions = append(o.dialOptions, opts...)
+	}
+}
+
+// WithRetryPolicy sets how we retry connecting to the servers after losing the connection.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retryPolicy = policy
+	}
+}
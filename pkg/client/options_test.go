@@ -0,0 +1,90 @@
+package client
+
+import (
+	"bytes"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name          string
+		addresses     []string
+		errorExpected bool
+	}{
+		{
+			name:          "no addresses",
+			errorExpected: true,
+		},
+		{
+			name:      "single address",
+			addresses: []string{"zk1:2181"},
+		},
+		{
+			name:      "multiple addresses",
+			addresses: []string{"zk1:2181", "zk2:2181", "zk3:2181"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, err := NewClient(test.addresses)
+			if test.errorExpected {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, client.servers, len(test.addresses))
+			client.closeConns()
+		})
+	}
+}
+
+func TestNewClient_Options(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := log.New(buf, "", 0)
+	policy := RetryPolicy{
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Second,
+		Timeout:        time.Minute,
+	}
+
+	client, err := NewClient(
+		[]string{"zk1:2181"},
+		WithDialTimeout(time.Second),
+		WithSessionTimeout(time.Minute),
+		WithLogger(logger),
+		WithRetryPolicy(policy),
+	)
+	require.NoError(t, err)
+	defer client.closeConns()
+
+	assert.Equal(t, time.Second, client.options.dialTimeout)
+	assert.Equal(t, time.Minute, client.options.sessionTimeout)
+	assert.Equal(t, policy, client.options.retryPolicy)
+	client.options.logger.Println("hello")
+	assert.Equal(t, "hello\n", buf.String())
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+	}
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{attempt: 0, expected: 100 * time.Millisecond},
+		{attempt: 1, expected: 200 * time.Millisecond},
+		{attempt: 3, expected: 800 * time.Millisecond},
+		{attempt: 4, expected: time.Second},
+		{attempt: 100, expected: time.Second},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, policy.backoff(test.attempt), "attempt [%d]", test.attempt)
+	}
+}
@@ -0,0 +1,53 @@
+package client
+
+// State is the state of the client's session with the servers.
+type State int
+
+const (
+	// State_CONNECTING means we are trying to establish or resume a session with one of the servers.
+	// Every client starts out in this state.
+	State_CONNECTING State = iota
+	// State_CONNECTED means we have a live connection to a server, and our session is active.
+	State_CONNECTED
+	// State_DISCONNECTED means we lost the connection to the server. The session may still be alive,
+	// but we can't be sure until we reconnect, so anything relying on it (like ephemeral nodes used
+	// for leadership) should be treated as suspended.
+	State_DISCONNECTED
+	// State_EXPIRED means the servers closed our session. Its ephemeral nodes and watches are gone,
+	// and the client can't be used anymore.
+	State_EXPIRED
+	// State_CLOSED means the client closed the session.
+	State_CLOSED
+	// State_AUTH_FAILED means the servers rejected our credentials.
+	State_AUTH_FAILED
+)
+
+const (
+	// StateChangesBufferSize is how many state changes we hold on to for a caller who isn't reading
+	// them. Once the buffer is full, we drop the oldest state change. State always returns the latest state.
+	StateChangesBufferSize = 16
+)
+
+func (s State) String() string {
+	switch s {
+	case State_CONNECTING:
+		return "CONNECTING"
+	case State_CONNECTED:
+		return "CONNECTED"
+	case State_DISCONNECTED:
+		return "DISCONNECTED"
+	case State_EXPIRED:
+		return "EXPIRED"
+	case State_CLOSED:
+		return "CLOSED"
+	case State_AUTH_FAILED:
+		return "AUTH_FAILED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// IsTerminal returns whether the client can never leave this state.
+func (s State) IsTerminal() bool {
+	return s == State_EXPIRED || s == State_CLOSED || s == State_AUTH_FAILED
+}
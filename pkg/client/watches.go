@@ -0,0 +1,209 @@
+package client
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	pbzk "github.com/mikekulinski/zookeeper/proto"
+)
+
+type watchKind int
+
+const (
+	// watchKind_DATA watches are set by exists and getData, and fire once.
+	watchKind_DATA watchKind = iota
+	// watchKind_EXIST watches are set by exists on a node that doesn't exist yet, and fire once it's created.
+	watchKind_EXIST
+	// watchKind_CHILDREN watches are set by getChildren, and fire once.
+	watchKind_CHILDREN
+	// watchKind_PERSISTENT and watchKind_PERSISTENT_RECURSIVE watches are set by addWatch, and fire until removed.
+	watchKind_PERSISTENT
+	watchKind_PERSISTENT_RECURSIVE
+)
+
+type watch struct {
+	path string
+	kind watchKind
+}
+
+// watchRegistry keeps track of the watches we've set on the server. We only return the watch events for the
+// watches in the registry, so a watch stops firing as soon as it's removed, even before the server knows.
+type watchRegistry struct {
+	mu      *sync.Mutex
+	watches map[watch]bool
+}
+
+func newWatchRegistry() *watchRegistry {
+	return &watchRegistry{
+		mu:      &sync.Mutex{},
+		watches: map[watch]bool{},
+	}
+}
+
+// add records the watch set by the request, if there is one.
+func (r *watchRegistry) add(req *pbzk.ZookeeperRequest) {
+	var w watch
+	switch m := req.GetMessage().(type) {
+	case *pbzk.ZookeeperRequest_Exists:
+		if !m.Exists.GetWatch() {
+			return
+		}
+		w = watch{path: m.Exists.GetPath(), kind: watchKind_DATA}
+	case *pbzk.ZookeeperRequest_GetData:
+		if !m.GetData.GetWatch() {
+			return
+		}
+		w = watch{path: m.GetData.GetPath(), kind: watchKind_DATA}
+	case *pbzk.ZookeeperRequest_GetChildren:
+		if !m.GetChildren.GetWatch() {
+			return
+		}
+		w = watch{path: m.GetChildren.GetPath(), kind: watchKind_CHILDREN}
+	case *pbzk.ZookeeperRequest_AddWatch:
+		switch m.AddWatch.GetMode() {
+		case pbzk.AddWatchRequest_MODE_PERSISTENT:
+			w = watch{path: m.AddWatch.GetPath(), kind: watchKind_PERSISTENT}
+		case pbzk.AddWatchRequest_MODE_PERSISTENT_RECURSIVE:
+			w = watch{path: m.AddWatch.GetPath(), kind: watchKind_PERSISTENT_RECURSIVE}
+		default:
+			return
+		}
+	default:
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.watches[w] = true
+}
+
+// existsResponse moves the watch set by an exists request on a missing node over to the exists watches. The
+// server needs to know which watches are waiting for the node to be created when we reconnect.
+func (r *watchRegistry) existsResponse(req *pbzk.ZookeeperRequest, resp *pbzk.ZookeeperResponse) {
+	exists := req.GetExists()
+	if !exists.GetWatch() || resp.GetExists() == nil || resp.GetExists().GetExists() {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	w := watch{path: exists.GetPath(), kind: watchKind_DATA}
+	if r.watches[w] {
+		delete(r.watches, w)
+		r.watches[watch{path: exists.GetPath(), kind: watchKind_EXIST}] = true
+	}
+}
+
+// remove removes the watches of the given type on path. It returns false if there weren't any.
+func (r *watchRegistry) remove(path string, watcherType pbzk.RemoveWatchesRequest_WatcherType) bool {
+	var kinds []watchKind
+	switch watcherType {
+	case pbzk.RemoveWatchesRequest_WATCHER_TYPE_DATA:
+		kinds = []watchKind{watchKind_DATA, watchKind_EXIST}
+	case pbzk.RemoveWatchesRequest_WATCHER_TYPE_CHILDREN:
+		kinds = []watchKind{watchKind_CHILDREN}
+	case pbzk.RemoveWatchesRequest_WATCHER_TYPE_PERSISTENT:
+		kinds = []watchKind{watchKind_PERSISTENT}
+	case pbzk.RemoveWatchesRequest_WATCHER_TYPE_PERSISTENT_RECURSIVE:
+		kinds = []watchKind{watchKind_PERSISTENT_RECURSIVE}
+	case pbzk.RemoveWatchesRequest_WATCHER_TYPE_ANY:
+		kinds = []watchKind{watchKind_DATA, watchKind_EXIST, watchKind_CHILDREN, watchKind_PERSISTENT, watchKind_PERSISTENT_RECURSIVE}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	removed := false
+	for _, kind := range kinds {
+		w := watch{path: path, kind: kind}
+		if r.watches[w] {
+			delete(r.watches, w)
+			removed = true
+		}
+	}
+	return removed
+}
+
+// fire returns whether any of our watches want the event. The watches that only fire once are removed.
+func (r *watchRegistry) fire(event *pbzk.WatchEvent) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path := event.GetPath()
+	var oneShot []watchKind
+	switch event.GetType() {
+	case pbzk.WatchEvent_EVENT_TYPE_ZNODE_CREATED:
+		oneShot = []watchKind{watchKind_DATA, watchKind_EXIST}
+	case pbzk.WatchEvent_EVENT_TYPE_ZNODE_DATA_CHANGED:
+		oneShot = []watchKind{watchKind_DATA}
+	case pbzk.WatchEvent_EVENT_TYPE_ZNODE_DELETED:
+		oneShot = []watchKind{watchKind_DATA, watchKind_CHILDREN}
+	case pbzk.WatchEvent_EVENT_TYPE_ZNODE_CHILDREN_CHANGED:
+		oneShot = []watchKind{watchKind_CHILDREN}
+	}
+	fired := false
+	for _, kind := range oneShot {
+		w := watch{path: path, kind: kind}
+		if r.watches[w] {
+			delete(r.watches, w)
+			fired = true
+		}
+	}
+	if r.watches[watch{path: path, kind: watchKind_PERSISTENT}] {
+		fired = true
+	}
+	// Recursive watches on the node or any of its ancestors fire for everything but children changing.
+	if event.GetType() != pbzk.WatchEvent_EVENT_TYPE_ZNODE_CHILDREN_CHANGED {
+		for ancestor := path; ancestor != ""; ancestor = parentPath(ancestor) {
+			if r.watches[watch{path: ancestor, kind: watchKind_PERSISTENT_RECURSIVE}] {
+				fired = true
+			}
+		}
+	}
+	return fired
+}
+
+// setWatches adds every watch in the registry to the connect request, so the server sets them again when
+// we reconnect.
+func (r *watchRegistry) setWatches(connect *pbzk.ConnectRequest) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	connect.DataWatches = nil
+	connect.ExistWatches = nil
+	connect.ChildWatches = nil
+	connect.PersistentWatches = nil
+	connect.PersistentRecursiveWatches = nil
+	for w := range r.watches {
+		switch w.kind {
+		case watchKind_DATA:
+			connect.DataWatches = append(connect.DataWatches, w.path)
+		case watchKind_EXIST:
+			connect.ExistWatches = append(connect.ExistWatches, w.path)
+		case watchKind_CHILDREN:
+			connect.ChildWatches = append(connect.ChildWatches, w.path)
+		case watchKind_PERSISTENT:
+			connect.PersistentWatches = append(connect.PersistentWatches, w.path)
+		case watchKind_PERSISTENT_RECURSIVE:
+			connect.PersistentRecursiveWatches = append(connect.PersistentRecursiveWatches, w.path)
+		}
+	}
+	// Sort so the request doesn't depend on the order of the map.
+	sort.Strings(connect.DataWatches)
+	sort.Strings(connect.ExistWatches)
+	sort.Strings(connect.ChildWatches)
+	sort.Strings(connect.PersistentWatches)
+	sort.Strings(connect.PersistentRecursiveWatches)
+}
+
+// parentPath returns the path to the parent of the node at path. The parent of the root is "".
+func parentPath(path string) string {
+	if path == "/" {
+		return ""
+	}
+	i := strings.LastIndex(path, "/")
+	if i <= 0 {
+		return "/"
+	}
+	return path[:i]
+}
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"testing"
+
+	pbzk "github.com/mikekulinski/zookeeper/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchRegistry_Fire(t *testing.T) {
+	getData := &pbzk.ZookeeperRequest{
+		Message: &pbzk.ZookeeperRequest_GetData{GetData: &pbzk.GetDataRequest{Path: "/a", Watch: true}},
+	}
+	getChildren := &pbzk.ZookeeperRequest{
+		Message: &pbzk.ZookeeperRequest_GetChildren{GetChildren: &pbzk.GetChildrenRequest{Path: "/a", Watch: true}},
+	}
+	recursive := &pbzk.ZookeeperRequest{
+		Message: &pbzk.ZookeeperRequest_AddWatch{AddWatch: &pbzk.AddWatchRequest{Path: "/", Mode: pbzk.AddWatchRequest_MODE_PERSISTENT_RECURSIVE}},
+	}
+	tests := []struct {
+		name     string
+		requests []*pbzk.ZookeeperRequest
+		events   []*pbzk.WatchEvent
+		fired    []bool
+	}{
+		{
+			name:   "no watches",
+			events: []*pbzk.WatchEvent{{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_DATA_CHANGED, Path: "/a"}},
+			fired:  []bool{false},
+		},
+		{
+			name: "request without a watch",
+			requests: []*pbzk.ZookeeperRequest{{
+				Message: &pbzk.ZookeeperRequest_GetData{GetData: &pbzk.GetDataRequest{Path: "/a"}},
+			}},
+			events: []*pbzk.WatchEvent{{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_DATA_CHANGED, Path: "/a"}},
+			fired:  []bool{false},
+		},
+		{
+			name:     "data watches fire once",
+			requests: []*pbzk.ZookeeperRequest{getData},
+			events: []*pbzk.WatchEvent{
+				{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_CHILDREN_CHANGED, Path: "/a"},
+				{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_DATA_CHANGED, Path: "/a"},
+				{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_DATA_CHANGED, Path: "/a"},
+			},
+			fired: []bool{false, true, false},
+		},
+		{
+			name:     "deletes fire data and child watches",
+			requests: []*pbzk.ZookeeperRequest{getData, getChildren},
+			events: []*pbzk.WatchEvent{
+				{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_DELETED, Path: "/a"},
+				{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_CHILDREN_CHANGED, Path: "/a"},
+			},
+			fired: []bool{true, false},
+		},
+		{
+			name:     "recursive watches fire for descendants until removed",
+			requests: []*pbzk.ZookeeperRequest{recursive},
+			events: []*pbzk.WatchEvent{
+				{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_CREATED, Path: "/a/b"},
+				{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_CREATED, Path: "/a/b"},
+				{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_CHILDREN_CHANGED, Path: "/a"},
+			},
+			fired: []bool{true, true, false},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := newWatchRegistry()
+			for _, req := range test.requests {
+				r.add(req)
+			}
+			for j, event := range test.events {
+				assert.Equal(t, test.fired[j], r.fire(event), "event %d", j)
+			}
+		})
+	}
+}
+
+func TestWatchRegistry_Remove(t *testing.T) {
+	r := newWatchRegistry()
+	r.add(&pbzk.ZookeeperRequest{
+		Message: &pbzk.ZookeeperRequest_Exists{Exists: &pbzk.ExistsRequest{Path: "/a", Watch: true}},
+	})
+	r.add(&pbzk.ZookeeperRequest{
+		Message: &pbzk.ZookeeperRequest_AddWatch{AddWatch: &pbzk.AddWatchRequest{Path: "/a", Mode: pbzk.AddWatchRequest_MODE_PERSISTENT}},
+	})
+
+	assert.False(t, r.remove("/a", pbzk.RemoveWatchesRequest_WATCHER_TYPE_CHILDREN))
+	assert.False(t, r.remove("/b", pbzk.RemoveWatchesRequest_WATCHER_TYPE_ANY))
+	assert.True(t, r.remove("/a", pbzk.RemoveWatchesRequest_WATCHER_TYPE_DATA))
+	// The persistent watch is still there.
+	assert.True(t, r.fire(&pbzk.WatchEvent{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_DATA_CHANGED, Path: "/a"}))
+	assert.True(t, r.remove("/a", pbzk.RemoveWatchesRequest_WATCHER_TYPE_ANY))
+	assert.False(t, r.fire(&pbzk.WatchEvent{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_DATA_CHANGED, Path: "/a"}))
+}
+
+func TestWatchRegistry_SetWatches(t *testing.T) {
+	r := newWatchRegistry()
+	connect := &pbzk.ConnectRequest{SessionId: "session"}
+	r.setWatches(connect)
+	assert.Empty(t, connect.GetDataWatches())
+
+	for _, req := range []*pbzk.ZookeeperRequest{
+		{Message: &pbzk.ZookeeperRequest_GetData{GetData: &pbzk.GetDataRequest{Path: "/b", Watch: true}}},
+		{Message: &pbzk.ZookeeperRequest_Exists{Exists: &pbzk.ExistsRequest{Path: "/a", Watch: true}}},
+		{Message: &pbzk.ZookeeperRequest_GetChildren{GetChildren: &pbzk.GetChildrenRequest{Path: "/a", Watch: true}}},
+		{Message: &pbzk.ZookeeperRequest_AddWatch{AddWatch: &pbzk.AddWatchRequest{Path: "/c", Mode: pbzk.AddWatchRequest_MODE_PERSISTENT}}},
+		{Message: &pbzk.ZookeeperRequest_AddWatch{AddWatch: &pbzk.AddWatchRequest{Path: "/", Mode: pbzk.AddWatchRequest_MODE_PERSISTENT_RECURSIVE}}},
+	} {
+		r.add(req)
+	}
+	r.setWatches(connect)
+	assert.Equal(t, "session", connect.GetSessionId())
+	assert.Equal(t, []string{"/a", "/b"}, connect.GetDataWatches())
+	assert.Equal(t, []string{"/a"}, connect.GetChildWatches())
+	assert.Equal(t, []string{"/c"}, connect.GetPersistentWatches())
+	assert.Equal(t, []string{"/"}, connect.GetPersistentRecursiveWatches())
+
+	// Watches that fired aren't set again.
+	r.fire(&pbzk.WatchEvent{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_DELETED, Path: "/a"})
+	r.setWatches(connect)
+	assert.Equal(t, []string{"/b"}, connect.GetDataWatches())
+	assert.Empty(t, connect.GetChildWatches())
+	assert.Empty(t, connect.GetExistWatches())
+
+	// An exists watch on a missing node is waiting for the node to be created.
+	exists := &pbzk.ZookeeperRequest{
+		Message: &pbzk.ZookeeperRequest_Exists{Exists: &pbzk.ExistsRequest{Path: "/d", Watch: true}},
+	}
+	r.add(exists)
+	r.existsResponse(exists, &pbzk.ZookeeperResponse{
+		Message: &pbzk.ZookeeperResponse_Exists{Exists: &pbzk.ExistsResponse{Exists: false}},
+	})
+	r.setWatches(connect)
+	assert.Equal(t, []string{"/b"}, connect.GetDataWatches())
+	assert.Equal(t, []string{"/d"}, connect.GetExistWatches())
+	assert.True(t, r.fire(&pbzk.WatchEvent{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_CREATED, Path: "/d"}))
+	r.setWatches(connect)
+	assert.Empty(t, connect.GetExistWatches())
+}
@@ -0,0 +1,371 @@
+// Package config loads the settings for a Zookeeper server. The config file uses the same
+// "key=value" format as Zookeeper's zoo.cfg, with one setting per line and "#" for comments.
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mikekulinski/zookeeper/pkg/auth"
+	"github.com/mikekulinski/zookeeper/pkg/quorum"
+)
+
+const (
+	DefaultClientPort = 8080
+	DefaultTickTime   = 2 * time.Second
+	// DefaultMinSessionTimeoutTicks is the default shortest session timeout we give a client, in ticks.
+	DefaultMinSessionTimeoutTicks = 2
+	// DefaultMaxSessionTimeoutTicks is the default longest session timeout we give a client, in ticks.
+	DefaultMaxSessionTimeoutTicks = 20
+	// DefaultSnapCount is the default number of transactions we log between snapshots.
+	DefaultSnapCount = 100000
+	DefaultAdminPort = 8081
+	// DefaultMaxClientConnections is the default number of connections we allow from a single IP address.
+	DefaultMaxClientConnections = 60
+	// RequestSizeHeadroom is how much bigger than MaxDataSize a message is allowed to be by default. This leaves
+	// room for everything else in a request, like the path and the ACL, so a request carrying the largest data
+	// we allow isn't rejected before we get to check the data itself.
+	RequestSizeHeadroom = 64 * 1024
+	// DefaultMaxDataSize is the default largest data in bytes we store in a single node, the same as
+	// Zookeeper's jute.maxbuffer.
+	DefaultMaxDataSize = 1024 * 1024
+	// DefaultReaperInterval is the default time between checks for nodes that have expired, such as TTL nodes.
+	DefaultReaperInterval = time.Minute
+	// DefaultContainerGracePeriod is the default time a container can go without ever having a child before
+	// it is deleted.
+	DefaultContainerGracePeriod = time.Minute
+	// MyIDFile is the file in the data directory that holds the id of the server, if the config doesn't set it.
+	MyIDFile = "myid"
+)
+
+// Config is every setting for a single server.
+type Config struct {
+	// ClientPort is the port we listen on for clients.
+	ClientPort int
+	// DataDir is where we store the myid file.
+	DataDir string
+	// DataLogDir is where we store the transaction log. It defaults to DataDir. If neither is set, the tree
+	// is only kept in memory.
+	DataLogDir string
+	// TickTime is the basic unit of time for the server. Heartbeats and session timeouts are
+	// measured in ticks.
+	TickTime time.Duration
+	// MinSessionTimeout and MaxSessionTimeout are the range of session timeouts we give clients.
+	// They default to DefaultMinSessionTimeoutTicks and DefaultMaxSessionTimeoutTicks ticks.
+	MinSessionTimeout time.Duration
+	MaxSessionTimeout time.Duration
+	// SnapCount is the number of transactions we log between snapshots. We don't take snapshots yet, so the
+	// whole log is replayed on restart.
+	SnapCount int
+	// ForceSync is whether we fsync the transaction log before acknowledging each transaction.
+	ForceSync bool
+	// ServerID is the id of this server in the ensemble (myid).
+	ServerID int64
+	// Ensemble is every member of the ensemble, including this server. It is empty when running standalone.
+	Ensemble *quorum.Config
+	// TLS is how we secure connections from clients.
+	TLS TLS
+	// AdminEnabled is whether we serve the admin commands over HTTP on AdminPort.
+	AdminEnabled bool
+	AdminPort    int
+	// MaxClientConnections is the most connections we allow from a single IP address. 0 means no limit.
+	MaxClientConnections int
+	// MaxRequestSize is the largest message in bytes we accept from a client. It defaults to MaxDataSize
+	// plus RequestSizeHeadroom, and has to be bigger than MaxDataSize.
+	MaxRequestSize int
+	// MaxDataSize is the largest data in bytes we store in a single node.
+	MaxDataSize int
+	// ReaperInterval is the time between checks for nodes that have expired, such as TTL nodes.
+	ReaperInterval time.Duration
+	// ContainerGracePeriod is how long a container can go without ever having a child before it is deleted.
+	ContainerGracePeriod time.Duration
+	// ReconfigAdmin is the digest id ("user:hash", see auth.GenerateDigest) of the user allowed to change the
+	// ensemble. Anyone can read the config, but nobody can reconfig the ensemble if this isn't set.
+	ReconfigAdmin string
+}
+
+// Default returns the config we use for any setting that isn't set.
+func Default() *Config {
+	ensemble, _ := quorum.NewConfig()
+	return &Config{
+		ClientPort:           DefaultClientPort,
+		TickTime:             DefaultTickTime,
+		SnapCount:            DefaultSnapCount,
+		ForceSync:            true,
+		Ensemble:             ensemble,
+		TLS:                  TLS{ClientAuth: ClientAuth_NONE},
+		AdminPort:            DefaultAdminPort,
+		MaxClientConnections: DefaultMaxClientConnections,
+		MaxDataSize:          DefaultMaxDataSize,
+		ReaperInterval:       DefaultReaperInterval,
+		ContainerGracePeriod: DefaultContainerGracePeriod,
+	}
+}
+
+// Load reads the config file at path. If the file doesn't set the server id, then we read it from
+// the myid file in the data directory, the same as Zookeeper.
+func Load(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening config file: %w", err)
+	}
+	defer f.Close()
+
+	c, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing config file [%s]: %w", path, err)
+	}
+	if c.ServerID == 0 && c.DataDir != "" {
+		id, err := readMyID(filepath.Join(c.DataDir, MyIDFile))
+		if err != nil {
+			return nil, err
+		}
+		c.ServerID = id
+	}
+	return c, nil
+}
+
+func readMyID(path string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error reading myid file: %w", err)
+	}
+	id, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid server id [%s] in [%s]", strings.TrimSpace(string(data)), path)
+	}
+	return id, nil
+}
+
+// Parse parses a config file. Any setting that isn't in the file keeps its default value.
+func Parse(r io.Reader) (*Config, error) {
+	c := Default()
+	// The ensemble lines are handed to the quorum package all at once, since groups and weights
+	// can only be checked against the full list of servers.
+	var ensemble bytes.Buffer
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key=value, got [%s]", lineNum, line)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		if strings.HasPrefix(key, "server.") || strings.HasPrefix(key, "group.") || strings.HasPrefix(key, "weight.") {
+			ensemble.WriteString(key + "=" + value + "\n")
+			continue
+		}
+		err := c.set(key, value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if ensemble.Len() > 0 {
+		var err error
+		c.Ensemble, err = quorum.ParseConfig(ensemble.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("invalid ensemble: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// set sets a single setting from the config file.
+func (c *Config) set(key string, value string) error {
+	if name, ok := strings.CutPrefix(key, clientTLSPrefix); ok {
+		return c.TLS.set(clientTLSPrefix, name, value)
+	}
+
+	var err error
+	switch key {
+	case "clientPort":
+		c.ClientPort, err = parseInt(key, value)
+	case "dataDir":
+		c.DataDir = value
+	case "dataLogDir":
+		c.DataLogDir = value
+	case "tickTime":
+		c.TickTime, err = parseMillis(key, value)
+	case "minSessionTimeout":
+		c.MinSessionTimeout, err = parseMillis(key, value)
+	case "maxSessionTimeout":
+		c.MaxSessionTimeout, err = parseMillis(key, value)
+	case "snapCount":
+		c.SnapCount, err = parseInt(key, value)
+	case "forceSync":
+		c.ForceSync, err = parseYesNo(key, value)
+	case "myid":
+		c.ServerID, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			err = fmt.Errorf("invalid %s [%s]: expected a number", key, value)
+		}
+	case "admin.enableServer":
+		c.AdminEnabled, err = parseYesNo(key, value)
+	case "admin.serverPort":
+		c.AdminPort, err = parseInt(key, value)
+	case "maxClientCnxns":
+		c.MaxClientConnections, err = parseInt(key, value)
+	case "maxRequestSize":
+		c.MaxRequestSize, err = parseInt(key, value)
+	case "maxDataSize":
+		c.MaxDataSize, err = parseInt(key, value)
+	case "znode.container.checkIntervalMs":
+		c.ReaperInterval, err = parseMillis(key, value)
+	case "znode.container.maxNeverUsedIntervalMs":
+		c.ContainerGracePeriod, err = parseMillis(key, value)
+	case "reconfigAdmin":
+		c.ReconfigAdmin = value
+	default:
+		return fmt.Errorf("unknown setting [%s]", key)
+	}
+	return err
+}
+
+func parseInt(key string, value string) (int, error) {
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s [%s]: expected a number", key, value)
+	}
+	return i, nil
+}
+
+func parseMillis(key string, value string) (time.Duration, error) {
+	ms, err := parseInt(key, value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+func parseYesNo(key string, value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "yes", "true":
+		return true, nil
+	case "no", "false":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid %s [%s]: expected yes or no", key, value)
+	}
+}
+
+// Validate fills in the settings that default to the value of other settings, and then checks
+// that every setting is usable. This should be called once all the settings are in place.
+func (c *Config) Validate() error {
+	c.TLS.fillDefaults()
+	if c.DataLogDir == "" {
+		c.DataLogDir = c.DataDir
+	}
+	if c.MinSessionTimeout == 0 {
+		c.MinSessionTimeout = DefaultMinSessionTimeoutTicks * c.TickTime
+	}
+	if c.MaxSessionTimeout == 0 {
+		c.MaxSessionTimeout = DefaultMaxSessionTimeoutTicks * c.TickTime
+	}
+	if c.MaxRequestSize == 0 {
+		c.MaxRequestSize = c.MaxDataSize + RequestSizeHeadroom
+	}
+
+	var errs []error
+	if err := validatePort("clientPort", c.ClientPort); err != nil {
+		errs = append(errs, err)
+	}
+	if c.TickTime <= 0 {
+		errs = append(errs, fmt.Errorf("invalid tickTime [%s]: must be positive", c.TickTime))
+	}
+	if c.MinSessionTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("invalid minSessionTimeout [%s]: must be positive", c.MinSessionTimeout))
+	}
+	if c.MinSessionTimeout > c.MaxSessionTimeout {
+		errs = append(errs, fmt.Errorf("minSessionTimeout [%s] must not be greater than maxSessionTimeout [%s]", c.MinSessionTimeout, c.MaxSessionTimeout))
+	}
+	if c.SnapCount <= 0 {
+		errs = append(errs, fmt.Errorf("invalid snapCount [%d]: must be positive", c.SnapCount))
+	}
+	if err := validateDir("dataDir", c.DataDir); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateDir("dataLogDir", c.DataLogDir); err != nil {
+		errs = append(errs, err)
+	}
+	if len(c.Ensemble.Servers) > 0 {
+		if _, ok := c.Ensemble.Servers[c.ServerID]; !ok {
+			errs = append(errs, fmt.Errorf("server id [%d] is not in the ensemble. Set myid to one of the server.<id> lines", c.ServerID))
+		}
+	}
+	errs = append(errs, c.TLS.validate(clientTLSPrefix)...)
+	if c.AdminEnabled {
+		if err := validatePort("admin.serverPort", c.AdminPort); err != nil {
+			errs = append(errs, err)
+		}
+		if c.AdminPort == c.ClientPort {
+			errs = append(errs, fmt.Errorf("admin.serverPort [%d] must be different from clientPort", c.AdminPort))
+		}
+	}
+	if c.MaxClientConnections < 0 {
+		errs = append(errs, fmt.Errorf("invalid maxClientCnxns [%d]: must not be negative", c.MaxClientConnections))
+	}
+	if c.MaxRequestSize <= 0 {
+		errs = append(errs, fmt.Errorf("invalid maxRequestSize [%d]: must be positive", c.MaxRequestSize))
+	} else if c.MaxRequestSize <= c.MaxDataSize {
+		errs = append(errs, fmt.Errorf("maxRequestSize [%d] must be greater than maxDataSize [%d], or requests with the largest data are rejected", c.MaxRequestSize, c.MaxDataSize))
+	}
+	if c.MaxDataSize <= 0 {
+		errs = append(errs, fmt.Errorf("invalid maxDataSize [%d]: must be positive", c.MaxDataSize))
+	}
+	if c.ReaperInterval <= 0 {
+		errs = append(errs, fmt.Errorf("invalid znode.container.checkIntervalMs [%s]: must be positive", c.ReaperInterval))
+	}
+	if c.ContainerGracePeriod < 0 {
+		errs = append(errs, fmt.Errorf("invalid znode.container.maxNeverUsedIntervalMs [%s]: must not be negative", c.ContainerGracePeriod))
+	}
+	if c.ReconfigAdmin != "" && !(&auth.DigestProvider{}).IsValid(c.ReconfigAdmin) {
+		errs = append(errs, fmt.Errorf("invalid reconfigAdmin [%s]: expected a digest id in the form user:hash", c.ReconfigAdmin))
+	}
+	return errors.Join(errs...)
+}
+
+func validatePort(key string, port int) error {
+	if port <= 0 || port > 65535 {
+		return fmt.Errorf("invalid %s [%d]: must be between 1 and 65535", key, port)
+	}
+	return nil
+}
+
+// validateDir checks that the directory is usable if it is set. It's fine if it doesn't exist yet,
+// since we'll create it.
+func validateDir(key string, dir string) error {
+	if dir == "" {
+		return nil
+	}
+	info, err := os.Stat(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s [%s]: %w", key, dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("invalid %s [%s]: not a directory", key, dir)
+	}
+	return nil
+}
@@ -0,0 +1,346 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mikekulinski/zookeeper/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name          string
+		file          string
+		expected      func(c *Config)
+		errorExpected bool
+	}{
+		{
+			name: "empty file uses the defaults",
+			file: "",
+		},
+		{
+			name: "all settings",
+			file: `
+# The basics.
+clientPort=2181
+dataDir=/var/lib/zookeeper
+dataLogDir = /var/log/zookeeper
+tickTime=500
+minSessionTimeout=1000
+maxSessionTimeout=30000
+snapCount=10
+forceSync=no
+myid=3
+ssl.certFile=server.crt
+ssl.keyFile=server.key
+ssl.caFile=ca.crt
+ssl.clientAuth=NEED
+ssl.mode=allow
+admin.enableServer=yes
+admin.serverPort=9090
+maxClientCnxns=0
+maxRequestSize=8192
+maxDataSize=4096
+znode.container.checkIntervalMs=5000
+znode.container.maxNeverUsedIntervalMs=10000
+reconfigAdmin=admin:x1nq8J5GOJVPY6zgzhtTtA9izLc=
+`,
+			expected: func(c *Config) {
+				c.ClientPort = 2181
+				c.DataDir = "/var/lib/zookeeper"
+				c.DataLogDir = "/var/log/zookeeper"
+				c.TickTime = 500 * time.Millisecond
+				c.MinSessionTimeout = time.Second
+				c.MaxSessionTimeout = 30 * time.Second
+				c.SnapCount = 10
+				c.ForceSync = false
+				c.ServerID = 3
+				c.TLS = TLS{CertFile: "server.crt", KeyFile: "server.key", CAFile: "ca.crt", ClientAuth: ClientAuth_NEED, Mode: TLSMode_ALLOW}
+				c.AdminEnabled = true
+				c.AdminPort = 9090
+				c.MaxClientConnections = 0
+				c.MaxRequestSize = 8192
+				c.MaxDataSize = 4096
+				c.ReaperInterval = 5 * time.Second
+				c.ContainerGracePeriod = 10 * time.Second
+				c.ReconfigAdmin = "admin:x1nq8J5GOJVPY6zgzhtTtA9izLc="
+			},
+		},
+		{
+			name:          "unknown setting",
+			file:          "initLimit=10",
+			errorExpected: true,
+		},
+		{
+			name:          "missing equals",
+			file:          "clientPort 2181",
+			errorExpected: true,
+		},
+		{
+			name:          "unknown TLS setting",
+			file:          "ssl.trustStore=store.jks",
+			errorExpected: true,
+		},
+		{
+			name:          "quorum TLS setting",
+			file:          "ssl.quorum.certFile=peer.crt",
+			errorExpected: true,
+		},
+		{
+			name:          "not a number",
+			file:          "tickTime=2s",
+			errorExpected: true,
+		},
+		{
+			name:          "not yes or no",
+			file:          "forceSync=maybe",
+			errorExpected: true,
+		},
+		{
+			name:          "invalid ensemble",
+			file:          "server.1=zk1",
+			errorExpected: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := Parse(strings.NewReader(test.file))
+			if test.errorExpected {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			expected := Default()
+			if test.expected != nil {
+				test.expected(expected)
+			}
+			assert.Equal(t, expected, c)
+		})
+	}
+}
+
+func TestParse_Ensemble(t *testing.T) {
+	c, err := Parse(strings.NewReader(`
+myid=2
+server.1=zk1:2888:3888
+server.2=zk2:2888:3888
+server.3=zk3:2888:3888
+`))
+	require.NoError(t, err)
+	assert.Len(t, c.Ensemble.Servers, 3)
+	assert.NoError(t, c.Validate())
+}
+
+func TestLoad_MyIDFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, MyIDFile), []byte("7\n"), 0o644))
+	path := filepath.Join(dir, "zoo.cfg")
+	require.NoError(t, os.WriteFile(path, []byte("dataDir="+dir+"\n"), 0o644))
+
+	c, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), c.ServerID)
+}
+
+func TestValidate(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o644))
+
+	tests := []struct {
+		name          string
+		modify        func(c *Config)
+		errorExpected bool
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name:          "invalid client port",
+			modify:        func(c *Config) { c.ClientPort = 70000 },
+			errorExpected: true,
+		},
+		{
+			name:          "zero tick time",
+			modify:        func(c *Config) { c.TickTime = 0 },
+			errorExpected: true,
+		},
+		{
+			name:          "negative snap count",
+			modify:        func(c *Config) { c.SnapCount = -1 },
+			errorExpected: true,
+		},
+		{
+			name: "min session timeout above max",
+			modify: func(c *Config) {
+				c.MinSessionTimeout = time.Minute
+				c.MaxSessionTimeout = time.Second
+			},
+			errorExpected: true,
+		},
+		{
+			name:          "data dir is a file",
+			modify:        func(c *Config) { c.DataDir = file },
+			errorExpected: true,
+		},
+		{
+			name:   "data dir doesn't exist yet",
+			modify: func(c *Config) { c.DataDir = filepath.Join(t.TempDir(), "new") },
+		},
+		{
+			name:          "cert without key",
+			modify:        func(c *Config) { c.TLS.CertFile = file },
+			errorExpected: true,
+		},
+		{
+			name:          "missing cert file",
+			modify:        func(c *Config) { c.TLS = TLS{CertFile: "missing.crt", KeyFile: file, ClientAuth: ClientAuth_NONE} },
+			errorExpected: true,
+		},
+		{
+			name:          "client auth without ca",
+			modify:        func(c *Config) { c.TLS = TLS{CertFile: file, KeyFile: file, ClientAuth: ClientAuth_NEED} },
+			errorExpected: true,
+		},
+		{
+			name:   "client auth without ca while TLS is disabled",
+			modify: func(c *Config) { c.TLS = TLS{ClientAuth: ClientAuth_NEED} },
+		},
+		{
+			name:          "TLS required without a cert",
+			modify:        func(c *Config) { c.TLS.Mode = TLSMode_REQUIRE },
+			errorExpected: true,
+		},
+		{
+			name: "unknown TLS mode",
+			modify: func(c *Config) {
+				c.TLS = TLS{CertFile: file, KeyFile: file, ClientAuth: ClientAuth_NONE, Mode: "sometimes"}
+			},
+			errorExpected: true,
+		},
+		{
+			name:          "unknown client auth",
+			modify:        func(c *Config) { c.TLS.ClientAuth = "sometimes" },
+			errorExpected: true,
+		},
+		{
+			name: "admin port same as client port",
+			modify: func(c *Config) {
+				c.AdminEnabled = true
+				c.AdminPort = c.ClientPort
+			},
+			errorExpected: true,
+		},
+		{
+			name:          "negative max client connections",
+			modify:        func(c *Config) { c.MaxClientConnections = -1 },
+			errorExpected: true,
+		},
+		{
+			name:          "negative max request size",
+			modify:        func(c *Config) { c.MaxRequestSize = -1 },
+			errorExpected: true,
+		},
+		{
+			name:          "max request size not above max data size",
+			modify:        func(c *Config) { c.MaxRequestSize = c.MaxDataSize },
+			errorExpected: true,
+		},
+		{
+			name: "max request size above max data size",
+			modify: func(c *Config) {
+				c.MaxDataSize = 4096
+				c.MaxRequestSize = 4097
+			},
+		},
+		{
+			name:          "zero max data size",
+			modify:        func(c *Config) { c.MaxDataSize = 0 },
+			errorExpected: true,
+		},
+		{
+			name:          "zero reaper interval",
+			modify:        func(c *Config) { c.ReaperInterval = 0 },
+			errorExpected: true,
+		},
+		{
+			name:          "negative container grace period",
+			modify:        func(c *Config) { c.ContainerGracePeriod = -time.Second },
+			errorExpected: true,
+		},
+		{
+			name:          "reconfig admin without a digest",
+			modify:        func(c *Config) { c.ReconfigAdmin = "admin" },
+			errorExpected: true,
+		},
+		{
+			name:   "reconfig admin",
+			modify: func(c *Config) { c.ReconfigAdmin = auth.GenerateDigest("admin", "secret") },
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := Default()
+			if test.modify != nil {
+				test.modify(c)
+			}
+			err := c.Validate()
+			if test.errorExpected {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidate_DerivedDefaults(t *testing.T) {
+	c := Default()
+	c.DataDir = t.TempDir()
+	c.TickTime = time.Second
+	require.NoError(t, c.Validate())
+	assert.Equal(t, c.DataDir, c.DataLogDir)
+	assert.Equal(t, DefaultMinSessionTimeoutTicks*time.Second, c.MinSessionTimeout)
+	assert.Equal(t, DefaultMaxSessionTimeoutTicks*time.Second, c.MaxSessionTimeout)
+	assert.Equal(t, DefaultMaxDataSize+RequestSizeHeadroom, c.MaxRequestSize)
+
+	// The request size follows the data size, so raising only the data size still leaves room for the rest
+	// of the request.
+	c = Default()
+	c.MaxDataSize = 4 * DefaultMaxDataSize
+	require.NoError(t, c.Validate())
+	assert.Equal(t, 4*DefaultMaxDataSize+RequestSizeHeadroom, c.MaxRequestSize)
+}
+
+func TestValidate_TLSMode(t *testing.T) {
+	c := Default()
+	require.NoError(t, c.Validate())
+	assert.Equal(t, TLSMode_DISABLED, c.TLS.Mode)
+	assert.False(t, c.TLS.Enabled())
+
+	// Setting a certificate turns on TLS unless the mode says otherwise.
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o644))
+	c = Default()
+	c.TLS.CertFile = file
+	c.TLS.KeyFile = file
+	require.NoError(t, c.Validate())
+	assert.Equal(t, TLSMode_REQUIRE, c.TLS.Mode)
+	assert.True(t, c.TLS.Enabled())
+}
+
+func TestValidate_ReportsEveryError(t *testing.T) {
+	c := Default()
+	c.ClientPort = 0
+	c.SnapCount = 0
+	c.MaxDataSize = 0
+	err := c.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "clientPort")
+	assert.Contains(t, err.Error(), "snapCount")
+	assert.Contains(t, err.Error(), "maxDataSize")
+}
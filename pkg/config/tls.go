@@ -0,0 +1,163 @@
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mikekulinski/zookeeper/pkg/certs"
+)
+
+// clientTLSPrefix is the prefix of the settings for securing connections from clients. i.e. "ssl.certFile"
+// The servers don't connect to each other yet, so there are no ssl.quorum settings.
+const clientTLSPrefix = "ssl."
+
+// ClientAuth is whether we ask clients for a certificate when they connect over TLS.
+type ClientAuth string
+
+const (
+	// ClientAuth_NONE doesn't ask clients for a certificate.
+	ClientAuth_NONE ClientAuth = "none"
+	// ClientAuth_WANT asks clients for a certificate, and verifies it if they send one.
+	ClientAuth_WANT ClientAuth = "want"
+	// ClientAuth_NEED requires clients to send a valid certificate.
+	ClientAuth_NEED ClientAuth = "need"
+)
+
+// TLSMode is whether connections have to use TLS.
+type TLSMode string
+
+const (
+	// TLSMode_DISABLED only accepts plaintext connections.
+	TLSMode_DISABLED TLSMode = "disabled"
+	// TLSMode_ALLOW accepts both TLS and plaintext connections on the same port. This is useful while
+	// moving clients over to TLS.
+	TLSMode_ALLOW TLSMode = "allow"
+	// TLSMode_REQUIRE only accepts TLS connections.
+	TLSMode_REQUIRE TLSMode = "require"
+)
+
+// TLS is where we find the files we need to secure connections, and how strict we are about it.
+type TLS struct {
+	// CertFile and KeyFile are the server's certificate and private key. These are reloaded whenever they
+	// change on disk.
+	CertFile string
+	KeyFile  string
+	// CAFile holds the certificates we use to verify the other side of the connection.
+	CAFile     string
+	ClientAuth ClientAuth
+	// Mode defaults to TLSMode_REQUIRE if there is a certificate, and TLSMode_DISABLED otherwise.
+	Mode TLSMode
+}
+
+// Enabled returns whether we should accept TLS connections.
+func (t TLS) Enabled() bool {
+	return t.Mode == TLSMode_ALLOW || t.Mode == TLSMode_REQUIRE
+}
+
+// set sets a single TLS setting. The name doesn't include the prefix.
+func (t *TLS) set(prefix string, name string, value string) error {
+	switch name {
+	case "certFile":
+		t.CertFile = value
+	case "keyFile":
+		t.KeyFile = value
+	case "caFile":
+		t.CAFile = value
+	case "clientAuth":
+		t.ClientAuth = ClientAuth(strings.ToLower(value))
+	case "mode":
+		t.Mode = TLSMode(strings.ToLower(value))
+	default:
+		return fmt.Errorf("unknown setting [%s%s]", prefix, name)
+	}
+	return nil
+}
+
+func (t *TLS) fillDefaults() {
+	if t.Mode != "" {
+		return
+	}
+	t.Mode = TLSMode_DISABLED
+	if t.CertFile != "" || t.KeyFile != "" {
+		t.Mode = TLSMode_REQUIRE
+	}
+}
+
+func (t TLS) validate(prefix string) []error {
+	var errs []error
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		errs = append(errs, fmt.Errorf("%scertFile and %skeyFile must be set together", prefix, prefix))
+	}
+	files := []struct {
+		key  string
+		file string
+	}{
+		{key: prefix + "certFile", file: t.CertFile},
+		{key: prefix + "keyFile", file: t.KeyFile},
+		{key: prefix + "caFile", file: t.CAFile},
+	}
+	for _, f := range files {
+		if f.file == "" {
+			continue
+		}
+		if _, err := os.Stat(f.file); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s [%s]: %w", f.key, f.file, err))
+		}
+	}
+	switch t.Mode {
+	case TLSMode_DISABLED:
+	case TLSMode_ALLOW, TLSMode_REQUIRE:
+		if t.CertFile == "" {
+			errs = append(errs, fmt.Errorf("%smode [%s] requires %scertFile and %skeyFile", prefix, t.Mode, prefix, prefix))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("invalid %smode [%s]: expected disabled, allow, or require", prefix, t.Mode))
+	}
+	switch t.ClientAuth {
+	case ClientAuth_NONE:
+	case ClientAuth_WANT, ClientAuth_NEED:
+		if t.Enabled() && t.CAFile == "" {
+			errs = append(errs, fmt.Errorf("%sclientAuth [%s] requires %scaFile to verify client certificates", prefix, t.ClientAuth, prefix))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("invalid %sclientAuth [%s]: expected none, want, or need", prefix, t.ClientAuth))
+	}
+	return errs
+}
+
+// Reloader loads the files, and keeps them up to date as they change on disk. This returns nil if TLS is disabled.
+func (t TLS) Reloader() (*certs.Reloader, error) {
+	if !t.Enabled() {
+		return nil, nil
+	}
+	return certs.NewReloader(t.CertFile, t.KeyFile, t.CAFile)
+}
+
+// ServerConfig builds the TLS config for accepting connections. This returns nil if TLS is disabled.
+func (t TLS) ServerConfig() (*tls.Config, error) {
+	r, err := t.Reloader()
+	if err != nil || r == nil {
+		return nil, err
+	}
+	var clientAuth tls.ClientAuthType
+	switch t.ClientAuth {
+	case ClientAuth_WANT:
+		clientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuth_NEED:
+		clientAuth = tls.RequireAndVerifyClientCert
+	default:
+		clientAuth = tls.NoClientCert
+	}
+	return r.ServerConfig(clientAuth), nil
+}
+
+// ClientConfig builds the TLS config for connecting to another server. This returns nil if TLS is disabled.
+func (t TLS) ClientConfig() (*tls.Config, error) {
+	r, err := t.Reloader()
+	if err != nil || r == nil {
+		return nil, err
+	}
+	return r.ClientConfig(), nil
+}
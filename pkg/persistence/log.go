@@ -3,6 +3,8 @@ package persistence
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -27,6 +29,8 @@ type LogManager struct {
 	mu       *sync.Mutex
 	logPath  string
 	LastZxid int64
+	// ForceSync is whether Append syncs each transaction to disk before returning.
+	ForceSync bool
 }
 
 func NewLogManager(logPath string) (*LogManager, error) {
@@ -76,9 +80,64 @@ func (l *LogManager) Append(txn *pbzk.Transaction) error {
 	if err != nil {
 		return fmt.Errorf("error writing transaction to file")
 	}
+	if l.ForceSync {
+		err = file.Sync()
+		if err != nil {
+			return fmt.Errorf("error syncing file: %w", err)
+		}
+	}
 
 	// Update the last seen ZXID to be equal to the transaction we just wrote.
 	// Do this after successfully writing the transaction to a file.
 	l.LastZxid = txn.GetZxid()
 	return nil
 }
+
+// ReadAll reads every transaction in the log, in zxid order. Files in the directory that aren't part of
+// the log are skipped. New transactions can only be appended after the last one that was read.
+func (l *LogManager) ReadAll() ([]*pbzk.Transaction, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries, err := os.ReadDir(l.logPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading log directory: %w", err)
+	}
+	var zxids []int64
+	for _, entry := range entries {
+		suffix, ok := strings.CutPrefix(entry.Name(), LogFilePrefix+"_")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		zxid, err := strconv.ParseInt(suffix, 10, 64)
+		if err != nil {
+			continue
+		}
+		zxids = append(zxids, zxid)
+	}
+	sort.Slice(zxids, func(i, j int) bool {
+		return zxids[i] < zxids[j]
+	})
+
+	var txns []*pbzk.Transaction
+	for _, zxid := range zxids {
+		fileName := fmt.Sprintf("%s/%s_%d", l.logPath, LogFilePrefix, zxid)
+		bytes, err := os.ReadFile(fileName)
+		if err != nil {
+			return nil, fmt.Errorf("error reading file [%s]: %w", fileName, err)
+		}
+		txn := &pbzk.Transaction{}
+		err = proto.Unmarshal(bytes, txn)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshalling txn from file [%s]: %w", fileName, err)
+		}
+		if txn.GetZxid() != zxid {
+			return nil, fmt.Errorf("file [%s] holds txn with zxid [%d]", fileName, txn.GetZxid())
+		}
+		txns = append(txns, txn)
+	}
+	if len(zxids) > 0 && zxids[len(zxids)-1] > l.LastZxid {
+		l.LastZxid = zxids[len(zxids)-1]
+	}
+	return txns, nil
+}
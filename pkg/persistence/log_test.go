@@ -6,7 +6,9 @@ import (
 	"strings"
 	"testing"
 
+	pbzk "github.com/mikekulinski/zookeeper/proto"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -26,3 +28,34 @@ func TestLogManager_Append(t *testing.T) {
 	_, err := NewLogManager(cwd + "/logs")
 	assert.NoError(t, err)
 }
+
+func TestLogManager_ReadAll(t *testing.T) {
+	dir := t.TempDir()
+	l, err := NewLogManager(dir)
+	require.NoError(t, err)
+	l.ForceSync = true
+	for _, zxid := range []int64{1, 2, 10} {
+		err := l.Append(&pbzk.Transaction{
+			Zxid: zxid,
+			Txn: &pbzk.Transaction_Create{
+				Create: &pbzk.CreateTxn{Path: "/zoo"},
+			},
+		})
+		require.NoError(t, err)
+	}
+	// Anything that isn't part of the log is skipped.
+	require.NoError(t, os.WriteFile(dir+"/myid", []byte("1"), 0o644))
+
+	// A new log manager picks up where the old one left off.
+	l, err = NewLogManager(dir)
+	require.NoError(t, err)
+	txns, err := l.ReadAll()
+	require.NoError(t, err)
+	var zxids []int64
+	for _, txn := range txns {
+		zxids = append(zxids, txn.GetZxid())
+	}
+	assert.Equal(t, []int64{1, 2, 10}, zxids)
+	assert.Equal(t, int64(10), l.LastZxid)
+	assert.Error(t, l.Append(&pbzk.Transaction{Zxid: 10}))
+}
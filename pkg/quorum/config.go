@@ -0,0 +1,376 @@
+package quorum
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// roleParticipant is the role of a server that votes in leader elections and counts towards the quorum
+// needed to commit a proposal. It is the only role we support, so every member of the ensemble is one.
+const roleParticipant = "participant"
+
+// Server is a single member of the ensemble.
+type Server struct {
+	// ID is the unique id of the server in the ensemble (myid).
+	ID int64
+	// PeerAddress is the address followers use to connect to the leader.
+	PeerAddress string
+	// ElectionAddress is the address used for leader election.
+	ElectionAddress string
+	// ClientAddress is the address clients use to connect to this server.
+	ClientAddress string
+}
+
+// ParseServer parses a single member of the ensemble using the same format as Zookeeper.
+// id is the number after "server." and spec is the value.
+//
+//	server.<id>=<host>:<peer port>:<election port>[:participant][;[<client host>:]<client port>]
+func ParseServer(id int64, spec string) (*Server, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("server id must be positive, got [%d]", id)
+	}
+	serverPart, clientPart, hasClient := strings.Cut(spec, ";")
+
+	parts := strings.Split(serverPart, ":")
+	if len(parts) != 3 && len(parts) != 4 {
+		return nil, fmt.Errorf("server.%d: expected host:peerPort:electionPort[:participant], got [%s]", id, serverPart)
+	}
+	host := parts[0]
+	if host == "" {
+		return nil, fmt.Errorf("server.%d: missing host", id)
+	}
+	for _, port := range parts[1:3] {
+		if err := validatePort(port); err != nil {
+			return nil, fmt.Errorf("server.%d: %w", id, err)
+		}
+	}
+
+	s := &Server{
+		ID:              id,
+		PeerAddress:     net.JoinHostPort(host, parts[1]),
+		ElectionAddress: net.JoinHostPort(host, parts[2]),
+	}
+	if len(parts) == 4 && !strings.EqualFold(parts[3], roleParticipant) {
+		return nil, fmt.Errorf("server.%d: unknown role [%s]", id, parts[3])
+	}
+
+	if hasClient {
+		clientHost, clientPort := "0.0.0.0", clientPart
+		if h, p, err := net.SplitHostPort(clientPart); err == nil {
+			clientHost, clientPort = h, p
+		}
+		if err := validatePort(clientPort); err != nil {
+			return nil, fmt.Errorf("server.%d: client %w", id, err)
+		}
+		s.ClientAddress = net.JoinHostPort(clientHost, clientPort)
+	}
+	return s, nil
+}
+
+func validatePort(port string) error {
+	p, err := strconv.Atoi(port)
+	if err != nil || p <= 0 || p > 65535 {
+		return fmt.Errorf("invalid port [%s]", port)
+	}
+	return nil
+}
+
+// String formats the server the same way ParseServer expects it, minus the "server.<id>=" prefix.
+func (s *Server) String() string {
+	host, peerPort, _ := net.SplitHostPort(s.PeerAddress)
+	_, electionPort, _ := net.SplitHostPort(s.ElectionAddress)
+	spec := fmt.Sprintf("%s:%s:%s:%s", host, peerPort, electionPort, roleParticipant)
+	if s.ClientAddress != "" {
+		spec += ";" + s.ClientAddress
+	}
+	return spec
+}
+
+// Config is the membership of the ensemble.
+type Config struct {
+	// Servers is a map of server id to each member of the ensemble.
+	Servers map[int64]*Server
+	// Groups is an optional map of group id to the ids of the servers in that group.
+	// If this is set, then we use hierarchical quorums.
+	Groups map[int64][]int64
+	// Weights is an optional map of server id to the weight of its vote. Servers that are
+	// missing from this map get a single vote.
+	Weights map[int64]int64
+	// Version is incremented each time the membership changes through a reconfig.
+	Version int64
+}
+
+func NewConfig(servers ...*Server) (*Config, error) {
+	c := &Config{
+		Servers: map[int64]*Server{},
+	}
+	for _, s := range servers {
+		if _, ok := c.Servers[s.ID]; ok {
+			return nil, fmt.Errorf("duplicate server id [%d]", s.ID)
+		}
+		c.Servers[s.ID] = s
+	}
+	return c, nil
+}
+
+// Voters returns the ids of all the servers that vote in elections and acknowledge proposals, sorted in
+// ascending order. Every member of the ensemble is a participant, so this is every server.
+func (c *Config) Voters() []int64 {
+	return sortedIDs(c.Servers)
+}
+
+// IsVoter returns whether the given server is allowed to vote.
+func (c *Config) IsVoter(id int64) bool {
+	_, ok := c.Servers[id]
+	return ok
+}
+
+// ParseServerLine parses a single "server.<id>=<spec>" line from the config.
+func ParseServerLine(line string) (*Server, error) {
+	key, spec, ok := strings.Cut(strings.TrimSpace(line), "=")
+	if !ok {
+		return nil, fmt.Errorf("expected server.<id>=<spec>, got [%s]", line)
+	}
+	idStr, ok := strings.CutPrefix(key, "server.")
+	if !ok {
+		return nil, fmt.Errorf("expected server.<id>=<spec>, got [%s]", line)
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server id [%s]", idStr)
+	}
+	return ParseServer(id, spec)
+}
+
+// ParseConfig parses the dynamic config as it is stored in /zookeeper/config. This is one
+// "server.<id>=<spec>" line per member, optional "group.<id>=<server ids>" and "weight.<id>=<weight>"
+// lines for hierarchical and weighted quorums, followed by a "version=<version>" line.
+func ParseConfig(data []byte) (*Config, error) {
+	var servers []*Server
+	var version int64
+	groups := map[int64][]int64{}
+	weights := map[int64]int64{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(line, "=")
+		switch {
+		case key == "version":
+			var err error
+			version, err = strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid config version [%s]", value)
+			}
+		case strings.HasPrefix(key, "group."):
+			gid, members, err := ParseGroupLine(line)
+			if err != nil {
+				return nil, err
+			}
+			groups[gid] = members
+		case strings.HasPrefix(key, "weight."):
+			id, weight, err := ParseWeightLine(line)
+			if err != nil {
+				return nil, err
+			}
+			weights[id] = weight
+		default:
+			s, err := ParseServerLine(line)
+			if err != nil {
+				return nil, err
+			}
+			servers = append(servers, s)
+		}
+	}
+	c, err := NewConfig(servers...)
+	if err != nil {
+		return nil, err
+	}
+	c.Version = version
+	if len(groups) > 0 {
+		c.Groups = groups
+	}
+	if len(weights) > 0 {
+		c.Weights = weights
+	}
+	// Make sure the groups and weights actually describe a valid quorum.
+	if len(c.Servers) > 0 {
+		if _, err := c.Verifier(); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// ParseGroupLine parses a single "group.<id>=<server id>:<server id>..." line from the config.
+func ParseGroupLine(line string) (int64, []int64, error) {
+	key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+	idStr, hasPrefix := strings.CutPrefix(key, "group.")
+	if !ok || !hasPrefix {
+		return 0, nil, fmt.Errorf("expected group.<id>=<server ids>, got [%s]", line)
+	}
+	gid, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid group id [%s]", idStr)
+	}
+	var members []int64
+	for _, m := range strings.Split(value, ":") {
+		id, err := strconv.ParseInt(m, 10, 64)
+		if err != nil {
+			return 0, nil, fmt.Errorf("group.%d: invalid server id [%s]", gid, m)
+		}
+		members = append(members, id)
+	}
+	return gid, members, nil
+}
+
+// ParseWeightLine parses a single "weight.<server id>=<weight>" line from the config.
+func ParseWeightLine(line string) (int64, int64, error) {
+	key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+	idStr, hasPrefix := strings.CutPrefix(key, "weight.")
+	if !ok || !hasPrefix {
+		return 0, 0, fmt.Errorf("expected weight.<id>=<weight>, got [%s]", line)
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid server id [%s]", idStr)
+	}
+	weight, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || weight < 0 {
+		return 0, 0, fmt.Errorf("weight.%d: invalid weight [%s]", id, value)
+	}
+	return id, weight, nil
+}
+
+// Bytes serializes the config into the format read by ParseConfig.
+func (c *Config) Bytes() []byte {
+	var b bytes.Buffer
+	for _, id := range sortedIDs(c.Servers) {
+		fmt.Fprintf(&b, "server.%d=%s\n", id, c.Servers[id])
+	}
+	for _, gid := range sortedIDs(c.Groups) {
+		members := make([]string, 0, len(c.Groups[gid]))
+		for _, id := range c.Groups[gid] {
+			members = append(members, strconv.FormatInt(id, 10))
+		}
+		fmt.Fprintf(&b, "group.%d=%s\n", gid, strings.Join(members, ":"))
+	}
+	for _, id := range sortedIDs(c.Weights) {
+		fmt.Fprintf(&b, "weight.%d=%d\n", id, c.Weights[id])
+	}
+	fmt.Fprintf(&b, "version=%d\n", c.Version)
+	return b.Bytes()
+}
+
+// Reconfig returns a new config with the joining servers added and the leaving servers removed.
+// A joining server that already exists replaces the old entry, which is how we change the address
+// of a server. Leaving servers are also removed from their groups and weights. groups sets the
+// members of each of the given groups, moving the members out of any group they were in before, and
+// weights sets the weight of each of the given servers. This is how joining participants are placed in
+// a hierarchical or weighted quorum. The current config is left untouched.
+func (c *Config) Reconfig(joining []*Server, leaving []int64, groups map[int64][]int64, weights map[int64]int64) (*Config, error) {
+	servers := map[int64]*Server{}
+	for id, s := range c.Servers {
+		servers[id] = s
+	}
+	for _, id := range leaving {
+		if _, ok := servers[id]; !ok {
+			return nil, fmt.Errorf("server [%d] is not part of the ensemble", id)
+		}
+		delete(servers, id)
+	}
+	seen := map[int64]bool{}
+	for _, s := range joining {
+		if seen[s.ID] {
+			return nil, fmt.Errorf("server [%d] is joining more than once", s.ID)
+		}
+		seen[s.ID] = true
+		servers[s.ID] = s
+	}
+
+	all := make([]*Server, 0, len(servers))
+	for _, s := range servers {
+		all = append(all, s)
+	}
+	newConfig, err := NewConfig(all...)
+	if err != nil {
+		return nil, err
+	}
+	if len(all) == 0 {
+		return nil, fmt.Errorf("cannot remove every server from the ensemble")
+	}
+	newConfig.Version = c.Version + 1
+
+	// Carry over the groups and weights for the servers that are still part of the ensemble, unless the
+	// reconfig moves them to a new group.
+	regrouped := map[int64]bool{}
+	for _, members := range groups {
+		for _, id := range members {
+			regrouped[id] = true
+		}
+	}
+	for gid, members := range c.Groups {
+		if _, ok := groups[gid]; ok {
+			continue
+		}
+		var remaining []int64
+		for _, id := range members {
+			// Servers that left no longer belong to a group.
+			if newConfig.IsVoter(id) && !regrouped[id] {
+				remaining = append(remaining, id)
+			}
+		}
+		if len(remaining) > 0 {
+			if newConfig.Groups == nil {
+				newConfig.Groups = map[int64][]int64{}
+			}
+			newConfig.Groups[gid] = remaining
+		}
+	}
+	for gid, members := range groups {
+		if len(members) == 0 {
+			continue
+		}
+		if newConfig.Groups == nil {
+			newConfig.Groups = map[int64][]int64{}
+		}
+		newConfig.Groups[gid] = members
+	}
+	for id, weight := range c.Weights {
+		if _, ok := servers[id]; ok {
+			if newConfig.Weights == nil {
+				newConfig.Weights = map[int64]int64{}
+			}
+			newConfig.Weights[id] = weight
+		}
+	}
+	for id, weight := range weights {
+		if _, ok := servers[id]; !ok {
+			return nil, fmt.Errorf("cannot set the weight of server [%d] which is not part of the ensemble", id)
+		}
+		if newConfig.Weights == nil {
+			newConfig.Weights = map[int64]int64{}
+		}
+		newConfig.Weights[id] = weight
+	}
+	if _, err := newConfig.Verifier(); err != nil {
+		return nil, fmt.Errorf("new config does not have a valid quorum: %w", err)
+	}
+	return newConfig, nil
+}
+
+func sortedIDs[V any](m map[int64]V) []int64 {
+	ids := make([]int64, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
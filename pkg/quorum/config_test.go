@@ -0,0 +1,231 @@
+package quorum
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseServer(t *testing.T) {
+	tests := []struct {
+		name          string
+		id            int64
+		spec          string
+		expected      *Server
+		errorExpected bool
+	}{
+		{
+			name: "participant by default",
+			id:   1,
+			spec: "zk1:2888:3888",
+			expected: &Server{
+				ID:              1,
+				PeerAddress:     "zk1:2888",
+				ElectionAddress: "zk1:3888",
+			},
+		},
+		{
+			name: "client port",
+			id:   4,
+			spec: "remote:2888:3888;2181",
+			expected: &Server{
+				ID:              4,
+				PeerAddress:     "remote:2888",
+				ElectionAddress: "remote:3888",
+				ClientAddress:   "0.0.0.0:2181",
+			},
+		},
+		{
+			name: "explicit participant with client address",
+			id:   2,
+			spec: "zk2:2888:3888:participant;10.0.0.2:2181",
+			expected: &Server{
+				ID:              2,
+				PeerAddress:     "zk2:2888",
+				ElectionAddress: "zk2:3888",
+				ClientAddress:   "10.0.0.2:2181",
+			},
+		},
+		{
+			name:          "invalid id",
+			id:            0,
+			spec:          "zk1:2888:3888",
+			errorExpected: true,
+		},
+		{
+			name:          "missing election port",
+			id:            1,
+			spec:          "zk1:2888",
+			errorExpected: true,
+		},
+		{
+			name:          "invalid port",
+			id:            1,
+			spec:          "zk1:abc:3888",
+			errorExpected: true,
+		},
+		{
+			name:          "unknown role",
+			id:            1,
+			spec:          "zk1:2888:3888:leader",
+			errorExpected: true,
+		},
+		{
+			name:          "observers aren't supported",
+			id:            1,
+			spec:          "zk1:2888:3888:observer",
+			errorExpected: true,
+		},
+		{
+			name:          "invalid client port",
+			id:            1,
+			spec:          "zk1:2888:3888;99999",
+			errorExpected: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := ParseServer(test.id, test.spec)
+			if test.errorExpected {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, s)
+
+			// Formatting the server should give us something we can parse again.
+			roundTrip, err := ParseServer(test.id, s.String())
+			require.NoError(t, err)
+			assert.Equal(t, s, roundTrip)
+		})
+	}
+}
+
+func TestConfig_Voters(t *testing.T) {
+	c, err := NewConfig(&Server{ID: 3}, &Server{ID: 1}, &Server{ID: 2})
+	require.NoError(t, err)
+
+	assert.Equal(t, []int64{1, 2, 3}, c.Voters())
+	assert.True(t, c.IsVoter(1))
+	assert.False(t, c.IsVoter(100))
+}
+
+func TestNewConfig_Invalid(t *testing.T) {
+	_, err := NewConfig(&Server{ID: 1}, &Server{ID: 1})
+	assert.Error(t, err)
+}
+
+func TestParseConfig_RoundTrip(t *testing.T) {
+	data := []byte("server.2=zk2:2888:3888:participant;2181\nserver.1=zk1:2888:3888\n\nversion=4\n")
+	c, err := ParseConfig(data)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), c.Version)
+	assert.Equal(t, []int64{1, 2}, c.Voters())
+
+	expected := "server.1=zk1:2888:3888:participant\n" +
+		"server.2=zk2:2888:3888:participant;0.0.0.0:2181\n" +
+		"version=4\n"
+	assert.Equal(t, expected, string(c.Bytes()))
+
+	_, err = ParseConfig([]byte("server.1=zk1:2888:3888\nversion=abc\n"))
+	assert.Error(t, err)
+	_, err = ParseConfig([]byte("bogus=1\n"))
+	assert.Error(t, err)
+}
+
+func TestConfig_Reconfig(t *testing.T) {
+	c, err := NewConfig(
+		&Server{ID: 1, PeerAddress: "zk1:2888", ElectionAddress: "zk1:3888"},
+		&Server{ID: 2, PeerAddress: "zk2:2888", ElectionAddress: "zk2:3888"},
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		joining       []*Server
+		leaving       []int64
+		voters        []int64
+		errorExpected bool
+	}{
+		{
+			name:    "add a voter",
+			joining: []*Server{{ID: 3, PeerAddress: "zk3:2888", ElectionAddress: "zk3:3888"}},
+			voters:  []int64{1, 2, 3},
+		},
+		{
+			name:    "remove a voter",
+			leaving: []int64{2},
+			voters:  []int64{1},
+		},
+		{
+			name:    "change the address of a server",
+			joining: []*Server{{ID: 2, PeerAddress: "zk2b:2888", ElectionAddress: "zk2b:3888"}},
+			voters:  []int64{1, 2},
+		},
+		{
+			name:          "remove a missing server",
+			leaving:       []int64{5},
+			errorExpected: true,
+		},
+		{
+			name:          "remove every voter",
+			leaving:       []int64{1, 2},
+			errorExpected: true,
+		},
+		{
+			name:          "same server joining twice",
+			joining:       []*Server{{ID: 3}, {ID: 3}},
+			errorExpected: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			newConfig, err := c.Reconfig(test.joining, test.leaving, nil, nil)
+			if test.errorExpected {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.voters, newConfig.Voters())
+			assert.Equal(t, c.Version+1, newConfig.Version)
+			// The original config should be left untouched.
+			assert.Equal(t, []int64{1, 2}, c.Voters())
+		})
+	}
+}
+
+func TestConfig_Reconfig_Hierarchical(t *testing.T) {
+	c, err := ParseConfig([]byte(`server.1=zk1:2888:3888
+server.2=zk2:2888:3888
+server.3=zk3:2888:3888
+group.1=1:2
+group.2=3
+weight.3=2
+`))
+	require.NoError(t, err)
+
+	// A participant that isn't in any group isn't part of the quorum.
+	_, err = c.Reconfig([]*Server{{ID: 4}}, nil, nil, nil)
+	assert.Error(t, err)
+	// A weight for a server that isn't in the ensemble is rejected.
+	_, err = c.Reconfig(nil, nil, nil, map[int64]int64{5: 1})
+	assert.Error(t, err)
+
+	newConfig, err := c.Reconfig([]*Server{{ID: 4}}, nil, map[int64][]int64{2: {3, 4}}, map[int64]int64{4: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3, 4}, newConfig.Voters())
+	assert.Equal(t, map[int64][]int64{1: {1, 2}, 2: {3, 4}}, newConfig.Groups)
+	assert.Equal(t, map[int64]int64{3: 2, 4: 2}, newConfig.Weights)
+	v, err := newConfig.Verifier()
+	require.NoError(t, err)
+	require.IsType(t, &HierarchicalVerifier{}, v)
+	// A majority of both groups is needed, and the new server's vote counts as much as server 3's.
+	assert.True(t, v.ContainsQuorum(map[int64]struct{}{1: {}, 2: {}, 3: {}, 4: {}}))
+	assert.False(t, v.ContainsQuorum(map[int64]struct{}{1: {}, 2: {}, 3: {}}))
+
+	// Moving a server to another group takes it out of its old group.
+	moved, err := newConfig.Reconfig(nil, nil, map[int64][]int64{1: {1, 2, 4}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[int64][]int64{1: {1, 2, 4}, 2: {3}}, moved.Groups)
+}
@@ -0,0 +1,198 @@
+package quorum
+
+import (
+	"fmt"
+)
+
+// Verifier decides whether a set of servers is enough for the ensemble to make progress. Once the servers
+// replicate to each other, the leader will use this to decide when a proposal has been ACKed by enough
+// servers to commit it, and the election will use it to decide when enough servers have agreed on a leader.
+// Until then, building one is how we check that a config describes a quorum that can be reached.
+type Verifier interface {
+	// ContainsQuorum returns whether the given set of server ids make up a quorum.
+	ContainsQuorum(ids map[int64]struct{}) bool
+	// Weight returns how much the vote of the given server counts for. Servers that are not
+	// part of the ensemble have a weight of 0.
+	Weight(id int64) int64
+}
+
+// MajorityVerifier is the default verifier, where every participant has a single vote and we need
+// more than half of the participants to form a quorum.
+type MajorityVerifier struct {
+	voters map[int64]struct{}
+}
+
+func NewMajorityVerifier(c *Config) *MajorityVerifier {
+	voters := map[int64]struct{}{}
+	for _, id := range c.Voters() {
+		voters[id] = struct{}{}
+	}
+	return &MajorityVerifier{
+		voters: voters,
+	}
+}
+
+func (m *MajorityVerifier) ContainsQuorum(ids map[int64]struct{}) bool {
+	var votes int
+	for id := range ids {
+		if _, ok := m.voters[id]; ok {
+			votes++
+		}
+	}
+	return votes > len(m.voters)/2
+}
+
+func (m *MajorityVerifier) Weight(id int64) int64 {
+	if _, ok := m.voters[id]; ok {
+		return 1
+	}
+	return 0
+}
+
+// WeightedVerifier gives each participant a configurable number of votes. We need more than half of
+// the total weight to form a quorum. Participants without an explicit weight get a single vote, and a
+// weight of 0 means the server never counts towards a quorum.
+type WeightedVerifier struct {
+	weights     map[int64]int64
+	totalWeight int64
+}
+
+func NewWeightedVerifier(c *Config) (*WeightedVerifier, error) {
+	w := &WeightedVerifier{
+		weights: map[int64]int64{},
+	}
+	for _, id := range c.Voters() {
+		weight, err := c.weightOf(id)
+		if err != nil {
+			return nil, err
+		}
+		w.weights[id] = weight
+		w.totalWeight += weight
+	}
+	if w.totalWeight == 0 {
+		return nil, fmt.Errorf("total weight of the participants must be positive")
+	}
+	return w, nil
+}
+
+func (w *WeightedVerifier) ContainsQuorum(ids map[int64]struct{}) bool {
+	var votes int64
+	for id := range ids {
+		votes += w.weights[id]
+	}
+	return 2*votes > w.totalWeight
+}
+
+func (w *WeightedVerifier) Weight(id int64) int64 {
+	return w.weights[id]
+}
+
+// HierarchicalVerifier splits the participants into groups, like Zookeeper's hierarchical quorums. This
+// is useful for ensembles that span several data centers. A quorum needs a weighted majority within a
+// majority of the groups, so losing a whole data center doesn't stop the ensemble. Groups where every
+// server has a weight of 0 are ignored.
+type HierarchicalVerifier struct {
+	// groups is a map of group id to the ids of the servers in that group.
+	groups  map[int64][]int64
+	weights map[int64]int64
+	// groupWeights is the total weight of each group.
+	groupWeights map[int64]int64
+	// activeGroups is the number of groups with a positive weight.
+	activeGroups int
+}
+
+func NewHierarchicalVerifier(c *Config) (*HierarchicalVerifier, error) {
+	if len(c.Groups) == 0 {
+		return nil, fmt.Errorf("hierarchical quorums need at least one group")
+	}
+	h := &HierarchicalVerifier{
+		groups:       map[int64][]int64{},
+		weights:      map[int64]int64{},
+		groupWeights: map[int64]int64{},
+	}
+
+	// Every participant has to be in exactly one group.
+	groupOf := map[int64]int64{}
+	for gid, members := range c.Groups {
+		for _, id := range members {
+			if other, ok := groupOf[id]; ok {
+				return nil, fmt.Errorf("server [%d] is in both group [%d] and group [%d]", id, other, gid)
+			}
+			if !c.IsVoter(id) {
+				return nil, fmt.Errorf("group [%d] contains server [%d] which is not part of the ensemble", gid, id)
+			}
+			groupOf[id] = gid
+		}
+	}
+	for _, id := range c.Voters() {
+		gid, ok := groupOf[id]
+		if !ok {
+			return nil, fmt.Errorf("participant [%d] is not in any group", id)
+		}
+		weight, err := c.weightOf(id)
+		if err != nil {
+			return nil, err
+		}
+		h.groups[gid] = append(h.groups[gid], id)
+		h.weights[id] = weight
+		h.groupWeights[gid] += weight
+	}
+	for _, weight := range h.groupWeights {
+		if weight > 0 {
+			h.activeGroups++
+		}
+	}
+	if h.activeGroups == 0 {
+		return nil, fmt.Errorf("at least one group must have a positive weight")
+	}
+	return h, nil
+}
+
+func (h *HierarchicalVerifier) ContainsQuorum(ids map[int64]struct{}) bool {
+	var groupsWithMajority int
+	for gid, members := range h.groups {
+		groupWeight := h.groupWeights[gid]
+		if groupWeight == 0 {
+			continue
+		}
+		var votes int64
+		for _, id := range members {
+			if _, ok := ids[id]; ok {
+				votes += h.weights[id]
+			}
+		}
+		if 2*votes > groupWeight {
+			groupsWithMajority++
+		}
+	}
+	return 2*groupsWithMajority > h.activeGroups
+}
+
+func (h *HierarchicalVerifier) Weight(id int64) int64 {
+	return h.weights[id]
+}
+
+// Verifier builds the verifier described by the config. We use hierarchical quorums if any groups
+// are configured, weighted quorums if any weights are configured, and a simple majority otherwise.
+func (c *Config) Verifier() (Verifier, error) {
+	switch {
+	case len(c.Groups) > 0:
+		return NewHierarchicalVerifier(c)
+	case len(c.Weights) > 0:
+		return NewWeightedVerifier(c)
+	default:
+		return NewMajorityVerifier(c), nil
+	}
+}
+
+// weightOf returns the weight of the given participant, defaulting to a single vote.
+func (c *Config) weightOf(id int64) (int64, error) {
+	weight, ok := c.Weights[id]
+	if !ok {
+		return 1, nil
+	}
+	if weight < 0 {
+		return 0, fmt.Errorf("server [%d] has a negative weight [%d]", id, weight)
+	}
+	return weight, nil
+}
@@ -0,0 +1,213 @@
+package quorum
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func idSet(ids ...int64) map[int64]struct{} {
+	set := map[int64]struct{}{}
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+	return set
+}
+
+func newTestConfig(t *testing.T, ids ...int64) *Config {
+	var servers []*Server
+	for _, id := range ids {
+		servers = append(servers, &Server{ID: id})
+	}
+	c, err := NewConfig(servers...)
+	require.NoError(t, err)
+	return c
+}
+
+func TestMajorityVerifier(t *testing.T) {
+	c := newTestConfig(t, 1, 2, 3, 4, 5)
+	v, err := c.Verifier()
+	require.NoError(t, err)
+	require.IsType(t, &MajorityVerifier{}, v)
+
+	tests := []struct {
+		name     string
+		ids      map[int64]struct{}
+		expected bool
+	}{
+		{
+			name:     "empty",
+			ids:      idSet(),
+			expected: false,
+		},
+		{
+			name:     "minority",
+			ids:      idSet(1, 2),
+			expected: false,
+		},
+		{
+			name:     "majority",
+			ids:      idSet(1, 3, 5),
+			expected: true,
+		},
+		{
+			name:     "unknown servers don't count",
+			ids:      idSet(1, 2, 100),
+			expected: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, v.ContainsQuorum(test.ids))
+		})
+	}
+	assert.Equal(t, int64(1), v.Weight(1))
+	assert.Equal(t, int64(0), v.Weight(6))
+}
+
+func TestWeightedVerifier(t *testing.T) {
+	c := newTestConfig(t, 1, 2, 3, 4)
+	// Total weight of the participants is 3 + 1 + 1 + 0 = 5, so we need more than 2.5.
+	c.Weights = map[int64]int64{1: 3, 4: 0, 5: 10}
+	v, err := c.Verifier()
+	require.NoError(t, err)
+	require.IsType(t, &WeightedVerifier{}, v)
+
+	tests := []struct {
+		name     string
+		ids      map[int64]struct{}
+		expected bool
+	}{
+		{
+			name:     "heavy server alone",
+			ids:      idSet(1),
+			expected: true,
+		},
+		{
+			name:     "light servers",
+			ids:      idSet(2, 3, 4),
+			expected: false,
+		},
+		{
+			name:     "weight of unknown servers is ignored",
+			ids:      idSet(2, 5),
+			expected: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, v.ContainsQuorum(test.ids))
+		})
+	}
+	assert.Equal(t, int64(3), v.Weight(1))
+	assert.Equal(t, int64(1), v.Weight(2))
+	assert.Equal(t, int64(0), v.Weight(4))
+	assert.Equal(t, int64(0), v.Weight(5))
+}
+
+func TestHierarchicalVerifier(t *testing.T) {
+	// Three data centers with three servers each.
+	c := newTestConfig(t, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+	c.Groups = map[int64][]int64{
+		1: {1, 2, 3},
+		2: {4, 5, 6},
+		3: {7, 8, 9},
+	}
+	v, err := c.Verifier()
+	require.NoError(t, err)
+	require.IsType(t, &HierarchicalVerifier{}, v)
+
+	tests := []struct {
+		name     string
+		ids      map[int64]struct{}
+		expected bool
+	}{
+		{
+			name:     "majority in a majority of groups",
+			ids:      idSet(1, 2, 4, 5),
+			expected: true,
+		},
+		{
+			name:     "one whole data center down",
+			ids:      idSet(1, 2, 3, 4, 5, 6),
+			expected: true,
+		},
+		{
+			name:     "majority of servers but only one group",
+			ids:      idSet(1, 2, 3, 4, 7),
+			expected: false,
+		},
+		{
+			name:     "unknown server doesn't help",
+			ids:      idSet(1, 2, 4, 10),
+			expected: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, v.ContainsQuorum(test.ids))
+		})
+	}
+
+	// Groups where every server has no weight don't count towards the majority of groups.
+	c.Weights = map[int64]int64{7: 0, 8: 0, 9: 0, 1: 2}
+	v, err = c.Verifier()
+	require.NoError(t, err)
+	assert.True(t, v.ContainsQuorum(idSet(1, 2, 4, 5)))
+	assert.False(t, v.ContainsQuorum(idSet(1, 4, 5, 7, 8, 9)))
+}
+
+func TestHierarchicalVerifier_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		groups map[int64][]int64
+	}{
+		{
+			name:   "participant missing from the groups",
+			groups: map[int64][]int64{1: {1, 2}},
+		},
+		{
+			name:   "participant in two groups",
+			groups: map[int64][]int64{1: {1, 2}, 2: {2, 3}},
+		},
+		{
+			name:   "unknown server in a group",
+			groups: map[int64][]int64{1: {1, 2, 3}, 2: {4}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newTestConfig(t, 1, 2, 3)
+			c.Groups = test.groups
+			_, err := c.Verifier()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseConfig_GroupsAndWeights(t *testing.T) {
+	data := []byte("server.1=zk1:2888:3888:participant\n" +
+		"server.2=zk2:2888:3888:participant\n" +
+		"server.3=zk3:2888:3888:participant\n" +
+		"group.1=1:2\n" +
+		"group.2=3\n" +
+		"weight.1=2\n" +
+		"version=1\n")
+	c, err := ParseConfig(data)
+	require.NoError(t, err)
+	assert.Equal(t, map[int64][]int64{1: {1, 2}, 2: {3}}, c.Groups)
+	assert.Equal(t, map[int64]int64{1: 2}, c.Weights)
+	assert.Equal(t, string(data), string(c.Bytes()))
+
+	// Removing a server also removes it from its group.
+	newConfig, err := c.Reconfig(nil, []int64{2}, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[int64][]int64{1: {1}, 2: {3}}, newConfig.Groups)
+
+	// Groups that don't cover every participant are rejected.
+	_, err = ParseConfig([]byte("server.1=zk1:2888:3888\nserver.2=zk2:2888:3888\ngroup.1=1\n"))
+	assert.Error(t, err)
+	_, err = ParseConfig([]byte("server.1=zk1:2888:3888\nweight.1=-1\n"))
+	assert.Error(t, err)
+}
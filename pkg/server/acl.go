@@ -0,0 +1,221 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/mikekulinski/zookeeper/pkg/auth"
+	"github.com/mikekulinski/zookeeper/pkg/utils"
+	"github.com/mikekulinski/zookeeper/pkg/znode"
+	pbzk "github.com/mikekulinski/zookeeper/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrNoAuth is returned when the client doesn't have permission to do something to a node.
+var ErrNoAuth = errors.New("not authorized")
+
+// connectionAuthIDsKey is the context key for the connectionAuthIDs of the client's connection.
+type connectionAuthIDsKey struct{}
+
+// connectionAuthIDs are the ids the client has because of its connection. They are only kept for as long as the
+// connection, since the client could resume the session from somewhere else.
+type connectionAuthIDs struct {
+	mu  *sync.Mutex
+	ids []*pbzk.Id
+}
+
+func (c *connectionAuthIDs) add(id *pbzk.Id) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, existing := range c.ids {
+		if existing.GetScheme() == id.GetScheme() && existing.GetId() == id.GetId() {
+			return
+		}
+	}
+	c.ids = append(c.ids, id)
+}
+
+func (c *connectionAuthIDs) list() []*pbzk.Id {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*pbzk.Id(nil), c.ids...)
+}
+
+// permissionNames is the order we list permissions in when describing a permission bitmask.
+var permissionNames = []struct {
+	perm pbzk.Permission
+	name string
+}{
+	{perm: pbzk.Permission_PERMISSION_READ, name: "READ"},
+	{perm: pbzk.Permission_PERMISSION_WRITE, name: "WRITE"},
+	{perm: pbzk.Permission_PERMISSION_CREATE, name: "CREATE"},
+	{perm: pbzk.Permission_PERMISSION_DELETE, name: "DELETE"},
+	{perm: pbzk.Permission_PERMISSION_ADMIN, name: "ADMIN"},
+}
+
+// describePerms returns a readable version of a permission bitmask. i.e. "READ|WRITE"
+func describePerms(perms int32) string {
+	var names []string
+	for _, p := range permissionNames {
+		if perms&int32(p.perm) != 0 {
+			names = append(names, p.name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// RegisterAuthProvider lets clients authenticate with the provider's scheme, and lets ACLs use ids from
+// it. This replaces any provider already registered for the scheme. The providers are read without a lock, so
+// this must only be called before the server starts serving clients.
+func (s *Server) RegisterAuthProvider(provider auth.AuthProvider) {
+	s.authProviders[provider.Scheme()] = provider
+}
+
+// configACL returns the ACL of the config node. Anyone can read the ensemble config, but only the reconfig
+// admin can change it. Without an admin, nobody can reconfig the ensemble.
+func configACL(admin string) []*pbzk.ACL {
+	if admin == "" {
+		return znode.ReadACLUnsafe
+	}
+	return append([]*pbzk.ACL{
+		{Perms: int32(pbzk.Permission_PERMISSION_ALL), Id: &pbzk.Id{Scheme: auth.SchemeDigest, Id: admin}},
+	}, znode.ReadACLUnsafe...)
+}
+
+// validateACL makes sure every entry of an ACL from the client grants valid permissions to an id we know
+// how to check.
+func (s *Server) validateACL(acl []*pbzk.ACL) error {
+	for _, entry := range acl {
+		if entry.GetPerms() == 0 || entry.GetPerms()&^int32(pbzk.Permission_PERMISSION_ALL) != 0 {
+			return fmt.Errorf("invalid ACL permissions [%d]", entry.GetPerms())
+		}
+		id := entry.GetId()
+		if id.GetScheme() == znode.SchemeWorld {
+			if id.GetId() != znode.IDAnyone {
+				return fmt.Errorf("invalid ACL id [%s:%s]: the only id in the world scheme is [%s]", id.GetScheme(), id.GetId(), znode.IDAnyone)
+			}
+			continue
+		}
+		provider, ok := s.authProviders[id.GetScheme()]
+		if !ok {
+			return fmt.Errorf("invalid ACL id [%s:%s]: unknown scheme", id.GetScheme(), id.GetId())
+		}
+		if !provider.IsValid(id.GetId()) {
+			return fmt.Errorf("invalid ACL id [%s:%s]", id.GetScheme(), id.GetId())
+		}
+	}
+	return nil
+}
+
+// authenticateConnection authenticates the client with every provider that can identify clients by their
+// connection. The ids are only kept for as long as the connection, since the client could resume the
+// session from somewhere else.
+func (s *Server) authenticateConnection(ctx context.Context) context.Context {
+	conn := auth.ConnInfoFromContext(ctx)
+	ids := &connectionAuthIDs{mu: &sync.Mutex{}}
+	for _, provider := range s.authProviders {
+		connProvider, ok := provider.(auth.ConnectionAuthProvider)
+		if !ok {
+			continue
+		}
+		// Not every connection can be identified by every provider. i.e. Clients that don't use TLS don't
+		// have a certificate.
+		id, err := connProvider.AuthenticateConnection(conn)
+		if err == nil {
+			ids.add(id)
+		}
+	}
+	return context.WithValue(ctx, connectionAuthIDsKey{}, ids)
+}
+
+// AddAuth authenticates the client with the credentials. If the credentials are invalid, then we return
+// Unauthenticated, which ends the session. Ids from providers that identify clients by their connection only
+// last as long as the connection, and every other id is kept for the rest of the session.
+func (s *Server) AddAuth(ctx context.Context, req *pbzk.AddAuthRequest) (*pbzk.AddAuthResponse, error) {
+	provider, ok := s.authProviders[req.GetScheme()]
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "unknown auth scheme [%s]", req.GetScheme())
+	}
+	id, err := provider.Authenticate(auth.ConnInfoFromContext(ctx), req.GetAuth())
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed for scheme [%s]: %v", req.GetScheme(), err)
+	}
+
+	clientID, _ := utils.ExtractClientIDHeader(ctx)
+	if _, ok := provider.(auth.ConnectionAuthProvider); ok {
+		connIDs, ok := ctx.Value(connectionAuthIDsKey{}).(*connectionAuthIDs)
+		if !ok {
+			return nil, fmt.Errorf("connection unexpectedly missing")
+		}
+		connIDs.add(id)
+		log.Printf("Connection of session [%s] authenticated as [%s:%s]\n", clientID, id.GetScheme(), id.GetId())
+		return &pbzk.AddAuthResponse{}, nil
+	}
+	s.sessionsMu.Lock()
+	sess, ok := s.sessions[clientID]
+	s.sessionsMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("session unexpectedly missing")
+	}
+	sess.AddAuthID(id)
+	log.Printf("Session [%s] authenticated as [%s:%s]\n", clientID, id.GetScheme(), id.GetId())
+	return &pbzk.AddAuthResponse{}, nil
+}
+
+// authIDs returns every identity the client has. Every client is part of the world scheme, and also has the
+// ids of its connection and the ids its session authenticated as.
+func (s *Server) authIDs(ctx context.Context) []*pbzk.Id {
+	ids := []*pbzk.Id{znode.AnyoneID}
+	if connIDs, ok := ctx.Value(connectionAuthIDsKey{}).(*connectionAuthIDs); ok {
+		ids = append(ids, connIDs.list()...)
+	}
+	clientID, _ := utils.ExtractClientIDHeader(ctx)
+	s.sessionsMu.Lock()
+	sess, ok := s.sessions[clientID]
+	s.sessionsMu.Unlock()
+	if ok {
+		ids = append(ids, sess.AuthIDs()...)
+	}
+	return ids
+}
+
+// matchesACL returns whether the client with the id is the id in the ACL entry.
+func (s *Server) matchesACL(id *pbzk.Id, aclID *pbzk.Id) bool {
+	if aclID.GetScheme() == znode.SchemeWorld {
+		return aclID.GetId() == znode.IDAnyone
+	}
+	if id.GetScheme() != aclID.GetScheme() {
+		return false
+	}
+	provider, ok := s.authProviders[aclID.GetScheme()]
+	if !ok {
+		return false
+	}
+	return provider.Matches(id.GetId(), aclID.GetId())
+}
+
+// checkACL makes sure the client has at least one of the permissions in perms on the node. This has to
+// be called before generating the transaction, so that a rejected request never changes the tree.
+func (s *Server) checkACL(ctx context.Context, node *znode.ZNode, perms pbzk.Permission) error {
+	ids := s.authIDs(ctx)
+	for _, entry := range node.ACL {
+		if entry.GetPerms()&int32(perms) == 0 {
+			continue
+		}
+		for _, id := range ids {
+			if s.matchesACL(id, entry.GetId()) {
+				return nil
+			}
+		}
+	}
+	path := node.Name
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Errorf("%w: missing %s permission on [%s]", ErrNoAuth, describePerms(int32(perms)), path)
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/mikekulinski/zookeeper/pkg/auth"
+	"github.com/mikekulinski/zookeeper/pkg/znode"
+	pbzk "github.com/mikekulinski/zookeeper/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateACL(t *testing.T) {
+	tests := []struct {
+		name          string
+		acl           []*pbzk.ACL
+		errorExpected bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "open",
+			acl:  znode.OpenACLUnsafe,
+		},
+		{
+			name: "no permissions",
+			acl: []*pbzk.ACL{
+				{Perms: 0, Id: znode.AnyoneID},
+			},
+			errorExpected: true,
+		},
+		{
+			name: "unknown permission",
+			acl: []*pbzk.ACL{
+				{Perms: 64, Id: znode.AnyoneID},
+			},
+			errorExpected: true,
+		},
+		{
+			name: "unknown id in the world scheme",
+			acl: []*pbzk.ACL{
+				{Perms: int32(pbzk.Permission_PERMISSION_READ), Id: &pbzk.Id{Scheme: znode.SchemeWorld, Id: "someone"}},
+			},
+			errorExpected: true,
+		},
+		{
+			name: "digest",
+			acl: []*pbzk.ACL{
+				{Perms: int32(pbzk.Permission_PERMISSION_ALL), Id: &pbzk.Id{Scheme: auth.SchemeDigest, Id: auth.GenerateDigest("alice", "secret")}},
+			},
+		},
+		{
+			name: "invalid digest",
+			acl: []*pbzk.ACL{
+				{Perms: int32(pbzk.Permission_PERMISSION_ALL), Id: &pbzk.Id{Scheme: auth.SchemeDigest, Id: "alice"}},
+			},
+			errorExpected: true,
+		},
+		{
+			name: "ip network",
+			acl: []*pbzk.ACL{
+				{Perms: int32(pbzk.Permission_PERMISSION_READ), Id: &pbzk.Id{Scheme: auth.SchemeIP, Id: "10.0.0.0/8"}},
+			},
+		},
+		{
+			name: "unknown scheme",
+			acl: []*pbzk.ACL{
+				{Perms: int32(pbzk.Permission_PERMISSION_READ), Id: &pbzk.Id{Scheme: "kerberos", Id: "alice"}},
+			},
+			errorExpected: true,
+		},
+	}
+	s := NewServer()
+	defer s.Close()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := s.validateACL(test.acl)
+			if test.errorExpected {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDescribePerms(t *testing.T) {
+	assert.Equal(t, "READ|ADMIN", describePerms(int32(pbzk.Permission_PERMISSION_READ|pbzk.Permission_PERMISSION_ADMIN)))
+	assert.Equal(t, "READ|WRITE|CREATE|DELETE|ADMIN", describePerms(int32(pbzk.Permission_PERMISSION_ALL)))
+	assert.Empty(t, describePerms(0))
+}
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/mikekulinski/zookeeper/pkg/quorum"
+	"github.com/mikekulinski/zookeeper/pkg/znode"
+	pbzk "github.com/mikekulinski/zookeeper/proto"
+)
+
+// ErrNoQuorum is returned when the servers that acknowledged a transaction don't make up a quorum of the
+// ensemble, so it can't be committed.
+var ErrNoQuorum = errors.New("no quorum")
+
+// commit gives the transaction the next zxid and applies it. s.applyMu must be held for writing, so
+// transactions are applied one at a time in zxid order, and whatever the caller checked before building the
+// transaction is still true when it is applied. The zxid is only used up if the transaction is applied.
+// It returns the node the transaction created, if any.
+// If the server has a log, the transaction is logged before the caller can respond to the client.
+func (s *Server) commit(txn *pbzk.Transaction) (*znode.ZNode, error) {
+	err := s.checkQuorum()
+	if err != nil {
+		return nil, err
+	}
+	txn.Zxid = s.lastZxid.Load() + 1
+	node, err := s.apply(txn)
+	if err != nil {
+		return nil, err
+	}
+	s.lastZxid.Store(txn.GetZxid())
+	if s.txnLog != nil {
+		err = s.txnLog.Append(txn)
+		if err != nil {
+			// The change is already in the tree, so we can't keep serving it without it being durable.
+			panic(fmt.Sprintf("unrecoverable: error logging txn [%d]: %v", txn.GetZxid(), err))
+		}
+	}
+	return node, nil
+}
+
+// checkQuorum checks that the servers that acknowledged a transaction make up a quorum of the current config.
+// Servers don't replicate yet, so we are the only one that ever acknowledges it. A reconfig is checked against
+// the config it replaces. A server without an ensemble runs standalone, so it is a quorum of one.
+// TODO: Count the acks from the other servers once they replicate, and use the verifier in leader election.
+func (s *Server) checkQuorum() error {
+	if len(s.config.Servers) == 0 {
+		return nil
+	}
+	verifier, err := s.config.Verifier()
+	if err != nil {
+		return fmt.Errorf("error building the quorum verifier: %w", err)
+	}
+	acks := map[int64]struct{}{s.serverID: {}}
+	if !verifier.ContainsQuorum(acks) {
+		return fmt.Errorf("%w: server [%d] isn't a quorum of config version [%d] on its own", ErrNoQuorum, s.serverID, s.config.Version)
+	}
+	return nil
+}
+
+// recover replays the transactions from the log, in zxid order. Sessions aren't logged, so the sessions
+// that still own ephemeral nodes after the replay can never come back. We close them, which deletes
+// their nodes.
+func (s *Server) recover(txns []*pbzk.Transaction) error {
+	s.applyMu.Lock()
+	defer s.applyMu.Unlock()
+
+	owners := map[string]bool{}
+	for _, txn := range txns {
+		_, err := s.apply(txn)
+		if err != nil {
+			return fmt.Errorf("error replaying txn [%d]: %w", txn.GetZxid(), err)
+		}
+		s.lastZxid.Store(txn.GetZxid())
+		switch {
+		case txn.GetCreate().GetEphemeral():
+			owners[txn.GetClientId()] = true
+		case txn.GetCloseSession() != nil:
+			delete(owners, txn.GetClientId())
+		}
+	}
+
+	var clientIDs []string
+	for clientID := range owners {
+		clientIDs = append(clientIDs, clientID)
+	}
+	sort.Strings(clientIDs)
+	for _, clientID := range clientIDs {
+		_, err := s.commit(&pbzk.Transaction{
+			ClientId:    clientID,
+			TimestampMs: time.Now().UnixMilli(),
+			Txn: &pbzk.Transaction_CloseSession{
+				CloseSession: &pbzk.CloseSessionTxn{},
+			},
+		})
+		if errors.Is(err, ErrNoQuorum) {
+			// We can't change the tree without a quorum, so the nodes stay until one deletes them.
+			log.Printf("Failed to close session [%s] after recovering: %v\n", clientID, err)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error closing session [%s]: %w", clientID, err)
+		}
+	}
+	return nil
+}
+
+// apply applies the transaction to the tree and queues the watch events it triggers. The events are queued
+// before s.applyMu is released, so no client can read the change before it gets the events for it.
+func (s *Server) apply(txn *pbzk.Transaction) (*znode.ZNode, error) {
+	switch t := txn.GetTxn().(type) {
+	case *pbzk.Transaction_Create:
+		node, err := s.db.Create(txn)
+		if err != nil {
+			return nil, err
+		}
+		s.triggerWatches(txn.GetZxid(), node.Name, pbzk.WatchEvent_EVENT_TYPE_ZNODE_CREATED)
+		return node, nil
+	case *pbzk.Transaction_Delete:
+		err := s.db.Delete(txn)
+		if err != nil {
+			return nil, err
+		}
+		s.triggerWatches(txn.GetZxid(), t.Delete.GetPath(), pbzk.WatchEvent_EVENT_TYPE_ZNODE_DELETED)
+	case *pbzk.Transaction_SetData:
+		err := s.db.SetData(txn)
+		if err != nil {
+			return nil, err
+		}
+		s.triggerWatches(txn.GetZxid(), t.SetData.GetPath(), pbzk.WatchEvent_EVENT_TYPE_ZNODE_DATA_CHANGED)
+	case *pbzk.Transaction_SetAcl:
+		err := s.db.SetACL(txn)
+		if err != nil {
+			return nil, err
+		}
+	case *pbzk.Transaction_Reconfig:
+		// The config comes from the transaction rather than the request, so replaying the log restores it.
+		config, err := quorum.ParseConfig(t.Reconfig.GetConfig())
+		if err != nil {
+			return nil, fmt.Errorf("invalid config in reconfig txn: %w", err)
+		}
+		err = s.db.Reconfig(txn)
+		if err != nil {
+			return nil, err
+		}
+		s.config = config
+		s.triggerWatches(txn.GetZxid(), znode.ConfigPath, pbzk.WatchEvent_EVENT_TYPE_ZNODE_DATA_CHANGED)
+	case *pbzk.Transaction_CloseSession:
+		deleted, err := s.db.CloseSession(txn)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range deleted {
+			s.triggerWatches(txn.GetZxid(), path, pbzk.WatchEvent_EVENT_TYPE_ZNODE_DELETED)
+		}
+	default:
+		return nil, fmt.Errorf("unknown transaction type [%T]", t)
+	}
+	return nil, nil
+}
@@ -11,49 +11,139 @@ import (
 	"github.com/mikekulinski/zookeeper/pkg/session"
 	"github.com/mikekulinski/zookeeper/pkg/utils"
 	pbzk "github.com/mikekulinski/zookeeper/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func (s *Server) Message(stream pbzk.Zookeeper_MessageServer) error {
-	ctx := stream.Context()
-	// Extract the clientID from the message headers.
-	clientID, ok := utils.ExtractClientIDHeader(ctx)
-	if !ok {
-		return fmt.Errorf("missing ClientID in the headers")
+	// The client has to start by telling us which session it wants to use.
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	connect := req.GetConnect()
+	if connect == nil {
+		return status.Errorf(codes.InvalidArgument, "the first message on the stream must be a connect request")
+	}
+	// Don't let the client connect if we haven't caught up to what it has already seen. Otherwise,
+	// it could read older data than it has read before.
+	if connect.GetLastZxid() > s.lastZxid.Load() {
+		return status.Errorf(codes.FailedPrecondition, "server is at zxid [%d] which is behind the client's zxid [%d]", s.lastZxid.Load(), connect.GetLastZxid())
 	}
 
-	// Establish a new session so that we have a channel we can use to safely process messages.
-	sess, err := s.StartSession(clientID)
+	// Establish a new session, or resume an existing one, so that we have a channel we can use to
+	// safely process messages.
+	timeout := time.Duration(connect.GetTimeoutMs()) * time.Millisecond
+	sess, superseded, err := s.attachSession(connect.GetSessionId(), connect.GetPassword(), timeout)
+	if err != nil {
+		return err
+	}
+	// Every request on this stream is made on behalf of the session, along with any ids the client has
+	// because of how it connected.
+	ctx := utils.SetIncomingClientIDHeader(stream.Context(), sess.ID)
+	ctx = s.authenticateConnection(ctx)
+
+	// Let the client know how to resume the session if it loses the connection, and how long it can
+	// go without talking to us before the session expires.
+	err = stream.Send(&pbzk.ZookeeperResponse{
+		Zxid: s.lastZxid.Load(),
+		Message: &pbzk.ZookeeperResponse_Connect{
+			Connect: &pbzk.ConnectResponse{
+				TimeoutMs: sess.Timeout.Milliseconds(),
+				SessionId: sess.ID,
+				Password:  sess.Password,
+			},
+		},
+	})
 	if err != nil {
-		return fmt.Errorf("error starting session: %w", err)
+		s.detachSession(sess.ID, superseded)
+		return err
+	}
+	// Set the watches the client had before it reconnected, and let it know about anything it missed.
+	err = s.setWatches(ctx, connect)
+	if err != nil {
+		s.detachSession(sess.ID, superseded)
+		return err
 	}
-	defer s.CloseSession(ctx)
 
-	go s.continuouslyReceiveMessages(sess, stream)
+	// Keep track of how the connection ends. If the client closed it, then we close the session too.
+	// Otherwise, we keep the session around in case the client reconnects.
+	closeSession := true
+	defer func() {
+		if closeSession {
+			s.CloseSession(ctx)
+		} else {
+			s.detachSession(sess.ID, superseded)
+		}
+	}()
+
+	// Messages from the client are only processed by this connection, so give them their own channel.
+	requests := make(chan *session.Event)
+	go s.continuouslyReceiveMessages(requests, stream)
 
 	for {
 		select {
-		case m := <-sess.Messages:
+		case m := <-requests:
+			if m.EOF {
+				// There are no more messages so safely close the connection. If the connection broke,
+				// then keep the session around for when the client reconnects.
+				closeSession = m.Err == nil
+				return m.Err
+			}
+
+			// Hearing from the client at all means it's still alive, so push back the expiry of its session.
+			s.sessionTracker.TouchSession(sess.ID)
+
 			var resp *pbzk.ZookeeperResponse
-			if m.ClientRequest != nil {
-				var err error
-				resp, err = s.handleClientRequest(ctx, m.ClientRequest)
+			req := m.ClientRequest
+			if req.GetXid() != 0 && req.GetXid() <= sess.LastXid {
+				// The client resent a request we already processed, likely because it lost the connection
+				// before getting the response. Send the same response instead of applying it again.
+				resp = sess.CachedResponse(req.GetXid())
+				if resp == nil {
+					log.Printf("Dropping duplicate request [%d] since its response is no longer cached\n", req.GetXid())
+					continue
+				}
+			} else {
+				// Only errors that end the session are returned. Any other failed request gets an error response.
+				resp, err = s.handleClientRequest(ctx, req)
 				if err != nil {
 					return err
 				}
-			} else if m.WatchEvent != nil {
-				resp = s.handleWatchEvent(m.WatchEvent)
-			} else if m.EOF {
-				// There are no more messages so safely close the connection.
-				return nil
+				if req.GetXid() != 0 {
+					resp.Xid = req.GetXid()
+					sess.LastXid = req.GetXid()
+					sess.CacheResponse(resp)
+				}
 			}
+			// Every transaction up to the last one we applied has already queued its watch events with an
+			// earlier zxid, so they are sent first.
+			resp.Zxid = s.lastZxid.Load()
+			sess.Outbox.Push(resp)
+		case <-sess.Outbox.Ready():
+			// Watch events for this session were queued by other connections.
+		case <-sess.Outbox.Overflowed():
+			// The client isn't reading its messages fast enough, so give up on this connection rather than
+			// queuing messages forever. The session is kept around in case the client reconnects.
+			closeSession = false
+			return status.Errorf(codes.ResourceExhausted, "too many messages waiting to be sent to the client")
+		case <-superseded:
+			// The client reconnected on a new connection, so leave the session to that connection.
+			closeSession = false
+			return status.Errorf(codes.Aborted, "session moved to a new connection")
+		case <-sess.Closed:
+			// We didn't hear from the client in time, so the session expired.
+			closeSession = false
+			return status.Errorf(codes.NotFound, "session expired")
+		}
 
-			// Send the response back to the client.
-			err = stream.Send(resp)
+		// Send everything that is queued for the client, in order.
+		for _, msg := range sess.Outbox.Drain() {
+			err = stream.Send(msg)
 			if err != nil {
+				closeSession = false
 				return err
 			}
-		case <-time.After(10 * time.Second):
-			return fmt.Errorf("timed out waiting for message to process")
 		}
 	}
 }
@@ -112,78 +202,215 @@ func (s *Server) handleClientRequest(ctx context.Context, req *pbzk.ZookeeperReq
 		mainResponse.Message = &pbzk.ZookeeperResponse_Sync{
 			Sync: resp,
 		}
+	case *pbzk.ZookeeperRequest_Connect:
+		return nil, status.Errorf(codes.FailedPrecondition, "the stream is already connected to a session")
+	case *pbzk.ZookeeperRequest_GetAcl:
+		var resp *pbzk.GetACLResponse
+		resp, err = s.GetACL(ctx, m.GetAcl)
+		mainResponse.Message = &pbzk.ZookeeperResponse_GetAcl{
+			GetAcl: resp,
+		}
+	case *pbzk.ZookeeperRequest_SetAcl:
+		var resp *pbzk.SetACLResponse
+		resp, err = s.SetACL(ctx, m.SetAcl)
+		mainResponse.Message = &pbzk.ZookeeperResponse_SetAcl{
+			SetAcl: resp,
+		}
+	case *pbzk.ZookeeperRequest_AddAuth:
+		var resp *pbzk.AddAuthResponse
+		resp, err = s.AddAuth(ctx, m.AddAuth)
+		mainResponse.Message = &pbzk.ZookeeperResponse_AddAuth{
+			AddAuth: resp,
+		}
+	case *pbzk.ZookeeperRequest_AddWatch:
+		var resp *pbzk.AddWatchResponse
+		resp, err = s.AddWatch(ctx, m.AddWatch)
+		mainResponse.Message = &pbzk.ZookeeperResponse_AddWatch{
+			AddWatch: resp,
+		}
+	case *pbzk.ZookeeperRequest_RemoveWatches:
+		var resp *pbzk.RemoveWatchesResponse
+		resp, err = s.RemoveWatches(ctx, m.RemoveWatches)
+		mainResponse.Message = &pbzk.ZookeeperResponse_RemoveWatches{
+			RemoveWatches: resp,
+		}
+	case *pbzk.ZookeeperRequest_Reconfig:
+		var resp *pbzk.ReconfigResponse
+		resp, err = s.Reconfig(ctx, m.Reconfig)
+		mainResponse.Message = &pbzk.ZookeeperResponse_Reconfig{
+			Reconfig: resp,
+		}
 	default:
 		return nil, fmt.Errorf("invalid message format: %+v", m)
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("error handling client request: %w", err)
+		// Failing to authenticate ends the session, the same as in Zookeeper. Any other error only fails the request.
+		if status.Code(err) == codes.Unauthenticated {
+			return nil, fmt.Errorf("error handling client request: %w", err)
+		}
+		return errorResponse(err), nil
 	}
 	return mainResponse, nil
 }
 
-func (s *Server) handleWatchEvent(event *pbzk.WatchEvent) *pbzk.ZookeeperResponse {
+// errorResponse is the response we send to a request that failed.
+func errorResponse(err error) *pbzk.ZookeeperResponse {
+	code := status.Code(err)
+	if errors.Is(err, ErrNoAuth) {
+		code = codes.PermissionDenied
+	}
 	return &pbzk.ZookeeperResponse{
-		Message: &pbzk.ZookeeperResponse_WatchEvent{
-			WatchEvent: event,
+		Message: &pbzk.ZookeeperResponse_Error{
+			Error: &pbzk.ErrorResponse{
+				Code:    int32(code),
+				Message: err.Error(),
+			},
 		},
 	}
 }
 
+// Heartbeat lets the client keep its session alive while it has nothing else to send. Like every other
+// request, it pushes back the expiry of the session.
 func (s *Server) Heartbeat(_ *pbzk.HeartbeatRequest) (*pbzk.HeartbeatResponse, error) {
-	// TODO: Implement some sort of timer reset here.
 	return &pbzk.HeartbeatResponse{
 		ReceivedTsMs: time.Now().UnixMilli(),
 	}, nil
 }
 
-func (s *Server) StartSession(clientID string) (*session.Session, error) {
-	if _, ok := s.sessions[clientID]; ok {
-		return nil, fmt.Errorf("session already exists for that clientID")
+// StartSession starts a new session with the timeout closest to the one the client asked for.
+func (s *Server) StartSession(timeout time.Duration) *session.Session {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	sess := session.NewSession(s.negotiateSessionTimeout(timeout))
+	s.sessions[sess.ID] = sess
+	s.sessionTracker.AddSession(sess.ID, sess.Timeout)
+	return sess
+}
+
+// negotiateSessionTimeout returns the timeout closest to the one the client asked for that we allow.
+func (s *Server) negotiateSessionTimeout(timeout time.Duration) time.Duration {
+	return min(max(timeout, s.minSessionTimeout), s.maxSessionTimeout)
+}
+
+// attachSession attaches a new connection to a session. If the client doesn't send a session ID, then we
+// start a new session with the given timeout. Otherwise, we resume the existing session, and take it over
+// from any connection that is still attached to it. We return a channel that is closed once a newer
+// connection takes over the session.
+func (s *Server) attachSession(sessionID string, password string, timeout time.Duration) (*session.Session, chan struct{}, error) {
+	if sessionID == "" {
+		sess := s.StartSession(timeout)
+		s.sessionsMu.Lock()
+		defer s.sessionsMu.Unlock()
+		sess.Superseded = make(chan struct{})
+		return sess, sess.Superseded, nil
+	}
+
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	sess, ok := s.sessions[sessionID]
+	if !ok {
+		return nil, nil, status.Errorf(codes.NotFound, "session expired")
+	}
+	if sess.Password != password {
+		return nil, nil, status.Errorf(codes.PermissionDenied, "invalid session password")
+	}
+	// The client is back, so give it the full timeout again before the session expires.
+	s.sessionTracker.TouchSession(sessionID)
+	// If the last connection fell too far behind, then start over with an empty outbox.
+	sess.Outbox.Reset()
+	// Kick out the old connection if the server hasn't noticed that it's gone yet.
+	if sess.Superseded != nil {
+		close(sess.Superseded)
+	}
+	sess.Superseded = make(chan struct{})
+	return sess, sess.Superseded, nil
+}
+
+// detachSession is called once the connection serving the session goes away without the client closing
+// the session. We keep the session, and its ephemeral nodes, around until it expires so the client can reconnect.
+func (s *Server) detachSession(clientID string, superseded chan struct{}) {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	sess, ok := s.sessions[clientID]
+	// Nothing to do if a newer connection has already taken over the session.
+	if !ok || sess.Superseded != superseded {
+		return
 	}
+	sess.Superseded = nil
+}
 
-	sess := session.NewSession()
-	s.sessions[clientID] = sess
-	return sess, nil
+// expireSession closes the session since we haven't heard from the client within the session timeout.
+func (s *Server) expireSession(clientID string) {
+	log.Printf("Session [%s] expired\n", clientID)
+	s.closeSession(clientID)
 }
 
+// CloseSession closes the session of the client that sent the request.
 func (s *Server) CloseSession(ctx context.Context) {
-	// Delete all ephemeral nodes associated with this session.
 	clientID, _ := utils.ExtractClientIDHeader(ctx)
-	if sess, ok := s.sessions[clientID]; ok {
-		for path, node := range sess.EphemeralNodes {
-			req := &pbzk.DeleteRequest{
-				Path:    path,
-				Version: node.Version,
-			}
-			// Try deleting the node from the tree. This will also clean up the reference to that node in this session.
-			// This is ok because it is safe to delete an entry from a map while iterating through it in Go.
-			_, err := s.Delete(ctx, req)
-			if err != nil {
-				panic("unrecoverable: error deleting the ephemeral nodes from tree")
-			}
-		}
-	}
-	// Then actually delete the session.
-	delete(s.sessions, clientID)
+	s.closeSession(clientID)
 }
 
-func (s *Server) continuouslyReceiveMessages(sess *session.Session, stream pbzk.Zookeeper_MessageServer) {
-	// Regardless of how we exit this function, we should send an EOF so that we
-	// close the stream once we have finished processing all messages.
-	defer func() {
-		sess.Messages <- &session.Event{EOF: true}
-	}()
+func (s *Server) closeSession(clientID string) {
+	s.sessionsMu.Lock()
+	sess, ok := s.sessions[clientID]
+	delete(s.sessions, clientID)
+	s.sessionsMu.Unlock()
+	if !ok {
+		// The session was already closed.
+		return
+	}
+	s.sessionTracker.RemoveSession(clientID)
+	s.watches.RemoveSession(clientID)
 
+	// Delete all ephemeral nodes associated with this session.
+	txn := &pbzk.Transaction{
+		ClientId:    clientID,
+		TimestampMs: time.Now().UnixMilli(),
+		Txn: &pbzk.Transaction_CloseSession{
+			CloseSession: &pbzk.CloseSessionTxn{},
+		},
+	}
+	s.applyMu.Lock()
+	_, err := s.commit(txn)
+	s.applyMu.Unlock()
+	if errors.Is(err, ErrNoQuorum) {
+		// We can't change the tree without a quorum, so the nodes stay until one deletes them.
+		log.Printf("Failed to delete the ephemeral nodes of session [%s]: %v\n", clientID, err)
+	} else if err != nil {
+		panic("unrecoverable: error deleting the ephemeral nodes from tree")
+	}
+	// Let the connection serving this session know that it's over.
+	close(sess.Closed)
+}
+
+func (s *Server) continuouslyReceiveMessages(requests chan<- *session.Event, stream pbzk.Zookeeper_MessageServer) {
+	ctx := stream.Context()
 	for {
 		req, err := stream.Recv()
-		if errors.Is(err, io.EOF) {
+		event := &session.Event{ClientRequest: req}
+		if err != nil {
+			// Regardless of how the stream ended, we should send an EOF so that we close the
+			// connection once we have finished processing all messages.
+			event = &session.Event{EOF: true}
+			if !errors.Is(err, io.EOF) {
+				log.Printf("Error receiving message from server stream: %+v\n", err)
+				event.Err = err
+			}
+		}
+
+		select {
+		case requests <- event:
+		case <-ctx.Done():
+			// The connection has already been closed, so nobody is listening anymore.
 			return
 		}
-		if err != nil {
-			log.Printf("Error receiving message from server stream: %+v\n", err)
+		if event.EOF {
 			return
 		}
-		sess.Messages <- &session.Event{ClientRequest: req}
 	}
 }
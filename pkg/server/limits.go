@@ -0,0 +1,74 @@
+package server
+
+import (
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// ConnectionLimiter limits how many streams a single IP address can have open at once. This keeps a
+// single misbehaving client from using up all the server's resources.
+type ConnectionLimiter struct {
+	mu *sync.Mutex
+	// max is the most streams we allow from a single IP address. 0 means no limit.
+	max int
+	// conns is the number of streams currently open from each IP address.
+	conns map[string]int
+}
+
+func NewConnectionLimiter(max int) *ConnectionLimiter {
+	return &ConnectionLimiter{
+		mu:    &sync.Mutex{},
+		max:   max,
+		conns: map[string]int{},
+	}
+}
+
+// StreamInterceptor rejects new streams from IP addresses that are already at the limit.
+func (l *ConnectionLimiter) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		host := remoteHost(ss)
+		if !l.acquire(host) {
+			return status.Errorf(codes.ResourceExhausted, "too many connections from [%s]", host)
+		}
+		defer l.release(host)
+		return handler(srv, ss)
+	}
+}
+
+func (l *ConnectionLimiter) acquire(host string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.max > 0 && l.conns[host] >= l.max {
+		return false
+	}
+	l.conns[host]++
+	return true
+}
+
+func (l *ConnectionLimiter) release(host string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.conns[host]--
+	if l.conns[host] <= 0 {
+		delete(l.conns, host)
+	}
+}
+
+// remoteHost returns the IP address the stream is coming from.
+func remoteHost(ss grpc.ServerStream) string {
+	p, ok := peer.FromContext(ss.Context())
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		// Not every address has a port, like in-memory connections in tests.
+		return p.Addr.String()
+	}
+	return host
+}
@@ -0,0 +1,26 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectionLimiter(t *testing.T) {
+	l := NewConnectionLimiter(2)
+	assert.True(t, l.acquire("10.0.0.1"))
+	assert.True(t, l.acquire("10.0.0.1"))
+	// The third stream from the same address is over the limit, but other addresses are fine.
+	assert.False(t, l.acquire("10.0.0.1"))
+	assert.True(t, l.acquire("10.0.0.2"))
+
+	// Closing a stream frees up room for another one.
+	l.release("10.0.0.1")
+	assert.True(t, l.acquire("10.0.0.1"))
+
+	// A limit of 0 means there is no limit.
+	unlimited := NewConnectionLimiter(0)
+	for i := 0; i < 100; i++ {
+		assert.True(t, unlimited.acquire("10.0.0.1"))
+	}
+}
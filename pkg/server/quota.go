@@ -0,0 +1,62 @@
+package server
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/mikekulinski/zookeeper/pkg/znode"
+)
+
+// ErrDataTooLarge is returned when a client tries to store more data in a node than the server allows.
+var ErrDataTooLarge = errors.New("data too large")
+
+// softQuotaExceeded counts the writes that went over the soft limit of a quota. These are allowed, so
+// this is the only way to notice them besides the logs.
+var softQuotaExceeded = expvar.NewInt("zookeeper_quota_soft_limit_exceeded")
+
+// validateDataSize makes sure the data fits in a single node.
+func (s *Server) validateDataSize(data []byte) error {
+	if len(data) > s.maxDataSize {
+		return fmt.Errorf("%w: [%d] bytes is more than the limit of [%d] bytes", ErrDataTooLarge, len(data), s.maxDataSize)
+	}
+	return nil
+}
+
+// validateQuotaLimits makes sure that if path is a limits node, then the data is a valid quota for a
+// subtree that exists. s.applyMu must be held, so the subtree can't be deleted before the quota is set.
+func (s *Server) validateQuotaLimits(path string, data []byte) error {
+	target, ok := znode.QuotaTarget(path)
+	if !ok {
+		return nil
+	}
+	if target == znode.ZookeeperPath || strings.HasPrefix(target, znode.ZookeeperPath+"/") {
+		return fmt.Errorf("cannot set a quota on [%s]", target)
+	}
+	_, err := znode.ParseQuota(data)
+	if err != nil {
+		return err
+	}
+	if s.db.Get(target) == nil {
+		return fmt.Errorf("cannot set a quota on [%s] since it does not exist", target)
+	}
+	return nil
+}
+
+// checkQuota makes sure adding countDelta nodes and bytesDelta bytes under path stays within the hard limit
+// of every quota that covers it. Going over a soft limit is only logged.
+func (s *Server) checkQuota(path string, countDelta int64, bytesDelta int64) error {
+	for _, usage := range s.db.Quotas(path) {
+		soft, err := usage.Check(countDelta, bytesDelta)
+		if err != nil {
+			return err
+		}
+		if soft {
+			softQuotaExceeded.Add(1)
+			log.Printf("Quota on [%s] exceeded: [%d] nodes and [%d] bytes, limits are [%s]\n", usage.Path, usage.Count+countDelta, usage.Bytes+bytesDelta, usage.Limits)
+		}
+	}
+	return nil
+}
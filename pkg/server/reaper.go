@@ -0,0 +1,81 @@
+package server
+
+import (
+	"log"
+	"time"
+
+	pbzk "github.com/mikekulinski/zookeeper/proto"
+)
+
+// reaper periodically deletes the nodes that have expired, such as TTL nodes and empty containers.
+type reaper struct {
+	interval time.Duration
+	reap     func()
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newReaper creates a reaper that calls reap once every interval. Nothing is reaped until Start is called.
+func newReaper(interval time.Duration, reap func()) *reaper {
+	return &reaper{
+		interval: interval,
+		reap:     reap,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start starts reaping in the background until Stop is called.
+func (r *reaper) Start() {
+	go r.run()
+}
+
+// Stop stops reaping, and waits for any reaping in progress to finish.
+func (r *reaper) Stop() {
+	close(r.stop)
+	<-r.done
+}
+
+func (r *reaper) run() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.reap()
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// reapExpiredNodes deletes every node that has expired. The deletes are normal transactions, so they are
+// replicated and fire watches the same as a delete from a client. A client could add a child or modify a
+// node after we found it, so each delete only goes through if the node is unchanged.
+func (s *Server) reapExpiredNodes() {
+	now := time.Now().UnixMilli()
+	for _, node := range s.db.ExpiredNodes(now, s.containerGracePeriod) {
+		txn := &pbzk.Transaction{
+			TimestampMs: now,
+			Txn: &pbzk.Transaction_Delete{
+				Delete: &pbzk.DeleteTxn{
+					Path: node.Name,
+					IfUnchanged: &pbzk.DeleteCondition{
+						Version:  node.Version,
+						Cversion: node.Cversion,
+					},
+				},
+			},
+		}
+		s.applyMu.Lock()
+		_, err := s.commit(txn)
+		s.applyMu.Unlock()
+		if err != nil {
+			log.Printf("Failed to delete expired node [%s]: %v\n", node.Name, err)
+			continue
+		}
+		log.Printf("Deleted expired node [%s]\n", node.Name)
+	}
+}
@@ -3,10 +3,17 @@ package server
 import (
 	"context"
 	"fmt"
-	"slices"
+	"log"
+	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/mikekulinski/zookeeper/pkg/auth"
+	"github.com/mikekulinski/zookeeper/pkg/config"
+	"github.com/mikekulinski/zookeeper/pkg/persistence"
+	"github.com/mikekulinski/zookeeper/pkg/quorum"
 	"github.com/mikekulinski/zookeeper/pkg/session"
 	"github.com/mikekulinski/zookeeper/pkg/utils"
 	"github.com/mikekulinski/zookeeper/pkg/znode"
@@ -15,66 +22,214 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-// TODO: All these structures are not thread safe. We should add a mutex to control this on the server.
-// TODO: Restructure the server to process transactions instead of client requests. That way we can
-// simply call these functions on recovery with the transactions from the log.
+// Server is a single Zookeeper server. applyMu guards the tree, config, and lastZxid, and sessionsMu guards
+// sessions. Everything else is either only set while creating the server, or safe to use concurrently.
 type Server struct {
 	pbzk.UnimplementedZookeeperServer
 
 	db znode.ZKDB
+	// applyMu serializes the changes to the tree. Writes hold it while they check the tree, apply their
+	// transaction, and queue the watch events it triggers. Reads hold it for reading, so they never see a
+	// change before its watch events are queued, and a watch they set can't miss a change.
+	applyMu *sync.RWMutex
 
-	// sessions is a map of ClientID to session for all the clients
+	// sessions is a map of session ID to session for all the clients
 	// that are currently connected to Zookeeper.
 	sessions map[string]*session.Session
-	// watches is a mapping of ZNode path to information about the type of watches on that node.
-	watches map[string][]*znode.Watch
+	// sessionsMu protects sessions. Sessions are attached, detached, and expired from different goroutines.
+	sessionsMu *sync.Mutex
+	// sessionTracker expires the sessions we haven't heard from in a while.
+	sessionTracker *session.Tracker
+	// reaper deletes the nodes that have expired, such as TTL nodes and empty containers.
+	reaper *reaper
+	// containerGracePeriod is how long a container can go without ever having a child before it is deleted.
+	containerGracePeriod time.Duration
+	// tickTime is the basic unit of time for the server. Sessions are checked for expiry once every tick.
+	tickTime time.Duration
+	// minSessionTimeout and maxSessionTimeout are the range of session timeouts we give clients.
+	minSessionTimeout time.Duration
+	maxSessionTimeout time.Duration
+	// watches are the watches every client has set.
+	watches *znode.WatchManager
+	// config is the current membership of the ensemble. This is kept in sync with the data stored
+	// in the config node, and is only changed by applying a reconfig transaction. It is only read or changed
+	// while holding applyMu.
+	config *quorum.Config
+	// serverID is the id of this server in the ensemble. It is the only server that acknowledges the
+	// transactions we commit, so it has to make up a quorum of the config on its own.
+	serverID int64
+	// lastZxid is the zxid of the last transaction we applied. It is only changed while holding applyMu.
+	lastZxid *atomic.Int64
+	// txnLog is where we log every transaction we apply, so we can recover the tree after a restart. It is
+	// nil if the server only keeps the tree in memory.
+	txnLog *persistence.LogManager
+	// authProviders are the auth schemes clients can authenticate with, by the name of the scheme.
+	authProviders map[string]auth.AuthProvider
+	// maxDataSize is the largest data in bytes we store in a single node.
+	maxDataSize int
 }
 
+// NewServer creates a server with the default config.
 func NewServer() *Server {
-	return &Server{
-		db:       znode.NewDB(),
-		sessions: map[string]*session.Session{},
-		watches:  map[string][]*znode.Watch{},
+	cfg := config.Default()
+	// The default config is always valid.
+	_ = cfg.Validate()
+	return NewServerWithConfig(cfg)
+}
+
+// NewServerWithConfig creates a server with the given config. The config should already be validated.
+// The tree is only kept in memory.
+func NewServerWithConfig(cfg *config.Config) *Server {
+	s := newServer(cfg)
+	s.start()
+	return s
+}
+
+// RecoverServer creates a server with the given config that logs every transaction to cfg.DataLogDir. The
+// transactions already in the log are replayed first, so a restarted server comes back with the same tree.
+// The config should already be validated.
+func RecoverServer(cfg *config.Config) (*Server, error) {
+	err := os.MkdirAll(cfg.DataLogDir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("error creating log directory [%s]: %w", cfg.DataLogDir, err)
+	}
+	txnLog, err := persistence.NewLogManager(cfg.DataLogDir)
+	if err != nil {
+		return nil, fmt.Errorf("error opening log [%s]: %w", cfg.DataLogDir, err)
 	}
+	txnLog.ForceSync = cfg.ForceSync
+	txns, err := txnLog.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	s := newServer(cfg)
+	s.txnLog = txnLog
+	// Replay before starting the background work, so nothing is committed until we've caught up.
+	err = s.recover(txns)
+	if err != nil {
+		return nil, err
+	}
+	s.start()
+	return s, nil
+}
+
+func newServer(cfg *config.Config) *Server {
+	s := &Server{
+		db:                   znode.NewDBWithConfig(cfg.Ensemble.Bytes(), configACL(cfg.ReconfigAdmin)),
+		applyMu:              &sync.RWMutex{},
+		sessions:             map[string]*session.Session{},
+		sessionsMu:           &sync.Mutex{},
+		tickTime:             cfg.TickTime,
+		minSessionTimeout:    cfg.MinSessionTimeout,
+		maxSessionTimeout:    cfg.MaxSessionTimeout,
+		watches:              znode.NewWatchManager(),
+		config:               cfg.Ensemble,
+		serverID:             cfg.ServerID,
+		lastZxid:             &atomic.Int64{},
+		authProviders:        map[string]auth.AuthProvider{},
+		maxDataSize:          cfg.MaxDataSize,
+		containerGracePeriod: cfg.ContainerGracePeriod,
+	}
+	for _, provider := range auth.DefaultProviders() {
+		s.RegisterAuthProvider(provider)
+	}
+	s.sessionTracker = session.NewTracker(s.tickTime, s.expireSession)
+	s.reaper = newReaper(cfg.ReaperInterval, s.reapExpiredNodes)
+	return s
+}
+
+// start starts the server's background work.
+func (s *Server) start() {
+	s.sessionTracker.Start()
+	s.reaper.Start()
+}
+
+// Close stops the server's background work, such as expiring sessions.
+func (s *Server) Close() {
+	s.sessionTracker.Stop()
+	s.reaper.Stop()
 }
 
 // Create creates a ZNode with path name path, stores data in it, and returns the name of the new ZNode
-// Flags can also be passed to pick certain attributes you want the ZNode to have.
+// The mode picks the type of ZNode to create.
 func (s *Server) Create(ctx context.Context, req *pbzk.CreateRequest) (*pbzk.CreateResponse, error) {
 	err := validatePath(req.GetPath())
 	if err != nil {
 		return nil, err
 	}
+	err = validateNotReserved(req.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	mode, err := createMode(req)
+	if err != nil {
+		return nil, err
+	}
+	err = s.validateDataSize(req.GetData())
+	if err != nil {
+		return nil, err
+	}
+	err = s.validateACL(req.GetAcl())
+	if err != nil {
+		return nil, err
+	}
+	acl := req.GetAcl()
+	if len(acl) == 0 {
+		acl = znode.OpenACLUnsafe
+	}
+
+	s.applyMu.Lock()
+	defer s.applyMu.Unlock()
+
+	err = s.validateQuotaLimits(req.GetPath(), req.GetData())
+	if err != nil {
+		return nil, err
+	}
+	// Creating a node needs permission on the parent. If the parent is missing, then the create fails anyway.
+	parent := s.db.Get(getParent(req.GetPath()))
+	if parent != nil {
+		err = s.checkACL(ctx, parent, pbzk.Permission_PERMISSION_CREATE)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = s.checkQuota(getParent(req.GetPath()), 1, int64(len(req.GetData())))
+	if err != nil {
+		return nil, err
+	}
 
 	clientID, _ := utils.ExtractClientIDHeader(ctx)
+	// Ephemeral nodes are tied to the session, so the session has to exist. A closing session is removed
+	// before its ephemeral nodes are deleted under applyMu, so either we see that it's gone, or the node
+	// we create is deleted along with the rest of its ephemeral nodes.
+	if isEphemeralMode(mode) {
+		s.sessionsMu.Lock()
+		_, ok := s.sessions[clientID]
+		s.sessionsMu.Unlock()
+		if !ok {
+			return nil, fmt.Errorf("session unexpectedly missing")
+		}
+	}
 	txn := &pbzk.Transaction{
 		ClientId:    clientID,
-		Zxid:        0, // TODO
 		TimestampMs: time.Now().UnixMilli(),
 		Txn: &pbzk.Transaction_Create{
 			Create: &pbzk.CreateTxn{
 				Path:       req.GetPath(),
 				Data:       req.GetData(),
-				Ephemeral:  slices.Contains(req.GetFlags(), pbzk.CreateRequest_FLAG_EPHEMERAL),
-				Sequential: slices.Contains(req.GetFlags(), pbzk.CreateRequest_FLAG_SEQUENTIAL),
+				Ephemeral:  isEphemeralMode(mode),
+				Sequential: isSequentialMode(mode),
+				Acl:        acl,
+				TtlMs:      req.GetTtlMs(),
+				Container:  mode == pbzk.CreateRequest_MODE_CONTAINER,
 			},
 		},
 	}
-	newNode, err := s.db.Create(txn)
+	newNode, err := s.commit(txn)
 	if err != nil {
 		return nil, err
 	}
 
-	// If this node is ephemeral, then tie it to this session.
-	if newNode.NodeType == znode.ZNodeType_EPHEMERAL {
-		sess, ok := s.sessions[clientID]
-		if !ok {
-			return nil, fmt.Errorf("session unexpectedly missing")
-		}
-		sess.EphemeralNodes[newNode.Name] = newNode
-	}
-
-	s.triggerWatches(newNode.Name, pbzk.WatchEvent_EVENT_TYPE_ZNODE_CREATED)
 	resp := &pbzk.CreateResponse{
 		ZNodeName: newNode.Name,
 	}
@@ -87,6 +242,13 @@ func (s *Server) Delete(ctx context.Context, req *pbzk.DeleteRequest) (*pbzk.Del
 	if err != nil {
 		return nil, err
 	}
+	err = validateNotReserved(req.GetPath())
+	if err != nil {
+		return nil, err
+	}
+
+	s.applyMu.Lock()
+	defer s.applyMu.Unlock()
 
 	// First, get the node and validate the request.
 	node := s.db.Get(req.GetPath())
@@ -100,15 +262,23 @@ func (s *Server) Delete(ctx context.Context, req *pbzk.DeleteRequest) (*pbzk.Del
 	}
 
 	// Nodes with children are not able to be deleted.
-	if len(node.Children) > 0 {
+	if len(s.db.Children(req.GetPath())) > 0 {
 		return nil, fmt.Errorf("the node specified has children. Only leaf nodes can be deleted")
 	}
 
+	// Deleting a node needs permission on the parent, not the node itself.
+	parent := s.db.Get(getParent(req.GetPath()))
+	if parent != nil {
+		err = s.checkACL(ctx, parent, pbzk.Permission_PERMISSION_DELETE)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Actually delete from the DB.
 	clientID, _ := utils.ExtractClientIDHeader(ctx)
 	txn := &pbzk.Transaction{
 		ClientId:    clientID,
-		Zxid:        0, // TODO
 		TimestampMs: time.Now().UnixMilli(),
 		Txn: &pbzk.Transaction_Delete{
 			Delete: &pbzk.DeleteTxn{
@@ -116,19 +286,10 @@ func (s *Server) Delete(ctx context.Context, req *pbzk.DeleteRequest) (*pbzk.Del
 			},
 		},
 	}
-	err = s.db.Delete(txn)
+	_, err = s.commit(txn)
 	if err != nil {
 		return nil, err
 	}
-
-	// Clean up any references if this was ephemeral.
-	if node.NodeType == znode.ZNodeType_EPHEMERAL {
-		// If this session hasn't already been deleted, then clean up the reference to this node here.
-		if sess, ok := s.sessions[node.Creator]; ok {
-			delete(sess.EphemeralNodes, req.GetPath())
-		}
-	}
-	s.triggerWatches(req.GetPath(), pbzk.WatchEvent_EVENT_TYPE_ZNODE_DELETED)
 	return &pbzk.DeleteResponse{}, nil
 }
 
@@ -140,22 +301,22 @@ func (s *Server) Exists(ctx context.Context, req *pbzk.ExistsRequest) (*pbzk.Exi
 		return nil, err
 	}
 
+	s.applyMu.RLock()
+	defer s.applyMu.RUnlock()
+
 	node := s.db.Get(req.GetPath())
+	if node != nil {
+		err = s.checkACL(ctx, node, pbzk.Permission_PERMISSION_READ)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	// If the client wants to watch for changes on this node, then add it to our map of watches.
+	// If the client wants to watch for changes on this node, then add it to our watches. Exists calls watch
+	// for creates, updates, or deletes to the node specified.
 	if req.GetWatch() {
 		clientID, _ := utils.ExtractClientIDHeader(ctx)
-		w := &znode.Watch{
-			ClientID: clientID,
-			Path:     req.GetPath(),
-			// Exists calls watch for creates, updates, or deletes to the node specified.
-			WatchTypes: []pbzk.WatchEvent_EventType{
-				pbzk.WatchEvent_EVENT_TYPE_ZNODE_CREATED,
-				pbzk.WatchEvent_EVENT_TYPE_ZNODE_DATA_CHANGED,
-				pbzk.WatchEvent_EVENT_TYPE_ZNODE_DELETED,
-			},
-		}
-		s.watches[req.GetPath()] = append(s.watches[req.GetPath()], w)
+		s.watches.Add(clientID, req.GetPath(), znode.WatchKind_DATA)
 	}
 	return &pbzk.ExistsResponse{
 		Exists: node != nil,
@@ -171,24 +332,23 @@ func (s *Server) GetData(ctx context.Context, req *pbzk.GetDataRequest) (*pbzk.G
 		return nil, err
 	}
 
+	s.applyMu.RLock()
+	defer s.applyMu.RUnlock()
+
 	node := s.db.Get(req.GetPath())
 	if node == nil {
 		return &pbzk.GetDataResponse{}, nil
 	}
+	err = s.checkACL(ctx, node, pbzk.Permission_PERMISSION_READ)
+	if err != nil {
+		return nil, err
+	}
 
-	// If the client wants to watch for changes on this node, then add it to our map of watches.
+	// If the client wants to watch for changes on this node, then add it to our watches. GetData calls watch
+	// for updates or deletes to the node specified.
 	if req.GetWatch() {
 		clientID, _ := utils.ExtractClientIDHeader(ctx)
-		w := &znode.Watch{
-			ClientID: clientID,
-			Path:     req.GetPath(),
-			// GetData calls watch for updates or deletes to the node specified.
-			WatchTypes: []pbzk.WatchEvent_EventType{
-				pbzk.WatchEvent_EVENT_TYPE_ZNODE_DATA_CHANGED,
-				pbzk.WatchEvent_EVENT_TYPE_ZNODE_DELETED,
-			},
-		}
-		s.watches[req.GetPath()] = append(s.watches[req.GetPath()], w)
+		s.watches.Add(clientID, req.GetPath(), znode.WatchKind_DATA)
 	}
 	return &pbzk.GetDataResponse{
 		Data:    node.Data,
@@ -202,19 +362,41 @@ func (s *Server) SetData(ctx context.Context, req *pbzk.SetDataRequest) (*pbzk.S
 	if err != nil {
 		return nil, err
 	}
+	err = validateNotReserved(req.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	err = s.validateDataSize(req.GetData())
+	if err != nil {
+		return nil, err
+	}
 
+	s.applyMu.Lock()
+	defer s.applyMu.Unlock()
+
+	err = s.validateQuotaLimits(req.GetPath(), req.GetData())
+	if err != nil {
+		return nil, err
+	}
 	node := s.db.Get(req.GetPath())
 	if node == nil {
 		return nil, fmt.Errorf("node does not exist")
 	}
+	err = s.checkACL(ctx, node, pbzk.Permission_PERMISSION_WRITE)
+	if err != nil {
+		return nil, err
+	}
 	if !isValidVersion(req.GetVersion(), node.Version) {
 		return nil, fmt.Errorf("invalid version: expected [%d], actual [%d]", req.GetVersion(), node.Version)
 	}
+	err = s.checkQuota(req.GetPath(), 0, int64(len(req.GetData())-len(node.Data)))
+	if err != nil {
+		return nil, err
+	}
 
 	clientID, _ := utils.ExtractClientIDHeader(ctx)
 	txn := &pbzk.Transaction{
 		ClientId:    clientID,
-		Zxid:        0, // TODO
 		TimestampMs: time.Now().UnixMilli(),
 		Txn: &pbzk.Transaction_SetData{
 			SetData: &pbzk.SetDataTxn{
@@ -223,12 +405,10 @@ func (s *Server) SetData(ctx context.Context, req *pbzk.SetDataRequest) (*pbzk.S
 			},
 		},
 	}
-	err = s.db.SetData(txn)
+	_, err = s.commit(txn)
 	if err != nil {
 		return nil, err
 	}
-
-	s.triggerWatches(req.GetPath(), pbzk.WatchEvent_EVENT_TYPE_ZNODE_DATA_CHANGED)
 	return &pbzk.SetDataResponse{}, nil
 }
 
@@ -239,91 +419,360 @@ func (s *Server) GetChildren(ctx context.Context, req *pbzk.GetChildrenRequest)
 		return nil, err
 	}
 
+	s.applyMu.RLock()
+	defer s.applyMu.RUnlock()
+
 	node := s.db.Get(req.GetPath())
 	if node == nil {
 		return &pbzk.GetChildrenResponse{}, nil
 	}
-
-	// Just get the names of the children from the map.
-	var childrenNames []string
-	for name := range node.Children {
-		childrenNames = append(childrenNames, name)
+	err = s.checkACL(ctx, node, pbzk.Permission_PERMISSION_READ)
+	if err != nil {
+		return nil, err
 	}
 
-	// If the client wants to watch for changes on this node, then add it to our map of watches.
+	childrenNames := s.db.Children(req.GetPath())
+
+	// If the client wants to watch for changes on this node, then add it to our watches. GetChildren calls
+	// watch for children update events, and for deletes since deletes mean we won't have any more children.
 	if req.GetWatch() {
 		clientID, _ := utils.ExtractClientIDHeader(ctx)
-		w := &znode.Watch{
-			ClientID: clientID,
-			Path:     req.GetPath(),
-			// GetChildren calls only watch for children update events.
-			WatchTypes: []pbzk.WatchEvent_EventType{
-				pbzk.WatchEvent_EVENT_TYPE_ZNODE_CHILDREN_CHANGED,
-				// We also watch for delete events to this node since deletes mean we won't have any more children.
-				pbzk.WatchEvent_EVENT_TYPE_ZNODE_DELETED,
-			},
-		}
-		s.watches[req.GetPath()] = append(s.watches[req.GetPath()], w)
+		s.watches.Add(clientID, req.GetPath(), znode.WatchKind_CHILDREN)
 	}
 	return &pbzk.GetChildrenResponse{
 		Children: childrenNames,
 	}, nil
 }
 
+// GetACL returns the ACL of the ZNode, and the version of the ACL. Clients that can either read or administer
+// the node can see its ACL.
+func (s *Server) GetACL(ctx context.Context, req *pbzk.GetACLRequest) (*pbzk.GetACLResponse, error) {
+	err := validatePath(req.GetPath())
+	if err != nil {
+		return nil, err
+	}
+
+	s.applyMu.RLock()
+	defer s.applyMu.RUnlock()
+
+	node := s.db.Get(req.GetPath())
+	if node == nil {
+		return nil, fmt.Errorf("node does not exist")
+	}
+	err = s.checkACL(ctx, node, pbzk.Permission_PERMISSION_READ|pbzk.Permission_PERMISSION_ADMIN)
+	if err != nil {
+		return nil, err
+	}
+	return &pbzk.GetACLResponse{
+		Acl:      node.ACL,
+		Aversion: node.ACLVersion,
+	}, nil
+}
+
+// SetACL replaces the ACL of the ZNode if the ACL is at the expected version. Unlike the data, the ACL of
+// the reserved nodes can be changed, so that admins can control who is allowed to reconfig the ensemble.
+func (s *Server) SetACL(ctx context.Context, req *pbzk.SetACLRequest) (*pbzk.SetACLResponse, error) {
+	err := validatePath(req.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	if len(req.GetAcl()) == 0 {
+		return nil, fmt.Errorf("ACL cannot be empty")
+	}
+	err = s.validateACL(req.GetAcl())
+	if err != nil {
+		return nil, err
+	}
+
+	s.applyMu.Lock()
+	defer s.applyMu.Unlock()
+
+	node := s.db.Get(req.GetPath())
+	if node == nil {
+		return nil, fmt.Errorf("node does not exist")
+	}
+	err = s.checkACL(ctx, node, pbzk.Permission_PERMISSION_ADMIN)
+	if err != nil {
+		return nil, err
+	}
+	if !isValidVersion(req.GetAversion(), node.ACLVersion) {
+		return nil, fmt.Errorf("invalid ACL version: expected [%d], actual [%d]", req.GetAversion(), node.ACLVersion)
+	}
+
+	clientID, _ := utils.ExtractClientIDHeader(ctx)
+	txn := &pbzk.Transaction{
+		ClientId:    clientID,
+		TimestampMs: time.Now().UnixMilli(),
+		Txn: &pbzk.Transaction_SetAcl{
+			SetAcl: &pbzk.SetACLTxn{
+				Path: req.GetPath(),
+				Acl:  req.GetAcl(),
+			},
+		},
+	}
+	_, err = s.commit(txn)
+	if err != nil {
+		return nil, err
+	}
+	return &pbzk.SetACLResponse{}, nil
+}
+
+// AddWatch sets a persistent watch on a ZNode. Unlike the watches set by Exists, GetData, and GetChildren,
+// persistent watches fire for every event instead of only the next one. The ZNode doesn't have to exist.
+func (s *Server) AddWatch(ctx context.Context, req *pbzk.AddWatchRequest) (*pbzk.AddWatchResponse, error) {
+	path, err := validateWatchPath(req.GetPath())
+	if err != nil {
+		return nil, err
+	}
+
+	var kind znode.WatchKind
+	switch req.GetMode() {
+	case pbzk.AddWatchRequest_MODE_PERSISTENT:
+		kind = znode.WatchKind_PERSISTENT
+	case pbzk.AddWatchRequest_MODE_PERSISTENT_RECURSIVE:
+		kind = znode.WatchKind_PERSISTENT_RECURSIVE
+	default:
+		return nil, fmt.Errorf("invalid watch mode [%s]", req.GetMode())
+	}
+
+	s.applyMu.RLock()
+	defer s.applyMu.RUnlock()
+
+	node := s.db.Get(path)
+	if node != nil {
+		err = s.checkACL(ctx, node, pbzk.Permission_PERMISSION_READ)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	clientID, _ := utils.ExtractClientIDHeader(ctx)
+	s.watches.Add(clientID, path, kind)
+	return &pbzk.AddWatchResponse{}, nil
+}
+
+// RemoveWatches removes the watches of the given type that the client has set on a ZNode. It isn't an error
+// if there are no watches to remove.
+func (s *Server) RemoveWatches(ctx context.Context, req *pbzk.RemoveWatchesRequest) (*pbzk.RemoveWatchesResponse, error) {
+	path, err := validateWatchPath(req.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetType() == pbzk.RemoveWatchesRequest_WATCHER_TYPE_UNSET {
+		return nil, fmt.Errorf("invalid watcher type [%s]", req.GetType())
+	}
+
+	clientID, _ := utils.ExtractClientIDHeader(ctx)
+	s.watches.Remove(clientID, path, watcherTypeKinds(req.GetType())...)
+	return &pbzk.RemoveWatchesResponse{}, nil
+}
+
+// watcherTypeKinds returns the kinds of watches that are removed for the watcher type.
+func watcherTypeKinds(watcherType pbzk.RemoveWatchesRequest_WatcherType) []znode.WatchKind {
+	switch watcherType {
+	case pbzk.RemoveWatchesRequest_WATCHER_TYPE_ANY:
+		return znode.AllWatchKinds
+	case pbzk.RemoveWatchesRequest_WATCHER_TYPE_CHILDREN:
+		return []znode.WatchKind{znode.WatchKind_CHILDREN}
+	case pbzk.RemoveWatchesRequest_WATCHER_TYPE_DATA:
+		return []znode.WatchKind{znode.WatchKind_DATA}
+	case pbzk.RemoveWatchesRequest_WATCHER_TYPE_PERSISTENT:
+		return []znode.WatchKind{znode.WatchKind_PERSISTENT}
+	case pbzk.RemoveWatchesRequest_WATCHER_TYPE_PERSISTENT_RECURSIVE:
+		return []znode.WatchKind{znode.WatchKind_PERSISTENT_RECURSIVE}
+	default:
+		return nil
+	}
+}
+
+// setWatches sets the watches the client had before it reconnected. Each watch is compared against the
+// client's last zxid, and the ones that would have fired while the client was away fire right away instead
+// of being set. Persistent watches are always set, since the client can't tell what it missed anyway.
+//
+// Exists watches on nodes that didn't exist are sent separately from the data watches, so a missing node means
+// it was deleted for a data watch, but hasn't been created yet for an exists watch.
+func (s *Server) setWatches(ctx context.Context, req *pbzk.ConnectRequest) error {
+	s.applyMu.RLock()
+	defer s.applyMu.RUnlock()
+
+	clientID, _ := utils.ExtractClientIDHeader(ctx)
+	lastZxid := req.GetLastZxid()
+	kinds := []struct {
+		paths []string
+		kind  znode.WatchKind
+		// exist is set for the watches set by exists on a node that didn't exist.
+		exist bool
+	}{
+		{req.GetDataWatches(), znode.WatchKind_DATA, false},
+		{req.GetExistWatches(), znode.WatchKind_DATA, true},
+		{req.GetChildWatches(), znode.WatchKind_CHILDREN, false},
+		{req.GetPersistentWatches(), znode.WatchKind_PERSISTENT, false},
+		{req.GetPersistentRecursiveWatches(), znode.WatchKind_PERSISTENT_RECURSIVE, false},
+	}
+	for _, k := range kinds {
+		for _, p := range k.paths {
+			path, err := validateWatchPath(p)
+			if err != nil {
+				return err
+			}
+			node := s.db.Get(path)
+			if node != nil && s.checkACL(ctx, node, pbzk.Permission_PERMISSION_READ) != nil {
+				// The client can't read the node anymore, so it doesn't get to watch it either.
+				continue
+			}
+
+			var missed pbzk.WatchEvent_EventType
+			switch {
+			case k.kind.Persistent():
+			case node == nil && k.exist:
+				// The node still hasn't been created, so keep waiting for it.
+			case node == nil:
+				missed = pbzk.WatchEvent_EVENT_TYPE_ZNODE_DELETED
+			case k.kind == znode.WatchKind_DATA && node.Czxid > lastZxid:
+				missed = pbzk.WatchEvent_EVENT_TYPE_ZNODE_CREATED
+			case k.kind == znode.WatchKind_DATA && node.Mzxid > lastZxid:
+				missed = pbzk.WatchEvent_EVENT_TYPE_ZNODE_DATA_CHANGED
+			case k.kind == znode.WatchKind_CHILDREN && node.Pzxid > lastZxid:
+				missed = pbzk.WatchEvent_EVENT_TYPE_ZNODE_CHILDREN_CHANGED
+			}
+			if missed != pbzk.WatchEvent_EVENT_TYPE_UNSET {
+				s.triggerEachWatch(s.lastZxid.Load(), []string{clientID}, path, missed)
+				continue
+			}
+			s.watches.Add(clientID, path, k.kind)
+		}
+	}
+	return nil
+}
+
 // Sync waits for all updates pending at the start of the operation to propagate to the server
 // that the client is connected to. The path is currently ignored. (Using path is not discussed in the white paper)
 func (s *Server) Sync(_ context.Context, _ *pbzk.SyncRequest) (*pbzk.SyncResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Sync not implemented")
 }
 
-// triggerWatches will notify all clients that are watching for events for that node.
-func (s *Server) triggerWatches(path string, watchType pbzk.WatchEvent_EventType) {
-	watchesToTrigger := s.extractWatches(path, watchType)
+// Reconfig adds or removes servers from the ensemble, or changes the address or role of existing servers.
+// The new config is committed as a transaction and stored in the config node, so clients can read
+// and watch it like any other ZNode.
+func (s *Server) Reconfig(ctx context.Context, req *pbzk.ReconfigRequest) (*pbzk.ReconfigResponse, error) {
+	if len(req.GetJoiningServers()) == 0 && len(req.GetLeavingServers()) == 0 && len(req.GetGroups()) == 0 && len(req.GetWeights()) == 0 {
+		return nil, fmt.Errorf("reconfig must change at least one server, group, or weight")
+	}
+	s.applyMu.Lock()
+	defer s.applyMu.Unlock()
+
+	// The new config is written to the config node, so the client needs permission to write to it.
+	configNode := s.db.Get(znode.ConfigPath)
+	if configNode != nil {
+		err := s.checkACL(ctx, configNode, pbzk.Permission_PERMISSION_WRITE)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !isValidVersion(req.GetFromConfig(), s.config.Version) {
+		return nil, fmt.Errorf("invalid config version: expected [%d], actual [%d]", req.GetFromConfig(), s.config.Version)
+	}
+
+	var joining []*quorum.Server
+	for _, line := range req.GetJoiningServers() {
+		srv, err := quorum.ParseServerLine(line)
+		if err != nil {
+			return nil, err
+		}
+		joining = append(joining, srv)
+	}
+	groups := map[int64][]int64{}
+	for _, line := range req.GetGroups() {
+		gid, members, err := quorum.ParseGroupLine(line)
+		if err != nil {
+			return nil, err
+		}
+		groups[gid] = members
+	}
+	weights := map[int64]int64{}
+	for _, line := range req.GetWeights() {
+		id, weight, err := quorum.ParseWeightLine(line)
+		if err != nil {
+			return nil, err
+		}
+		weights[id] = weight
+	}
+	newConfig, err := s.config.Reconfig(joining, req.GetLeavingServers(), groups, weights)
+	if err != nil {
+		return nil, err
+	}
+
+	clientID, _ := utils.ExtractClientIDHeader(ctx)
+	txn := &pbzk.Transaction{
+		ClientId:    clientID,
+		TimestampMs: time.Now().UnixMilli(),
+		Txn: &pbzk.Transaction_Reconfig{
+			Reconfig: &pbzk.ReconfigTxn{
+				Config: newConfig.Bytes(),
+			},
+		},
+	}
+	_, err = s.commit(txn)
+	if err != nil {
+		return nil, err
+	}
+	return &pbzk.ReconfigResponse{
+		Config:  newConfig.Bytes(),
+		Version: newConfig.Version,
+	}, nil
+}
+
+// Digest returns a hash of the whole tree stored on this server. Servers with the same digest
+// have the same data.
+func (s *Server) Digest() uint64 {
+	return s.db.Digest()
+}
+
+// triggerWatches will notify all clients that are watching for events for that node. The zxid is the
+// transaction that caused the event.
+func (s *Server) triggerWatches(zxid int64, path string, watchType pbzk.WatchEvent_EventType) {
+	s.triggerEachWatch(zxid, s.watches.Trigger(path, watchType), path, watchType)
 
 	// For create/delete events, check if this triggered any child watches in the parent.
-	var childWatchesToTrigger []*znode.Watch
 	if watchType == pbzk.WatchEvent_EVENT_TYPE_ZNODE_CREATED ||
 		watchType == pbzk.WatchEvent_EVENT_TYPE_ZNODE_DELETED {
 		parentPath := getParent(path)
-		childWatchesToTrigger = s.extractWatches(parentPath, pbzk.WatchEvent_EVENT_TYPE_ZNODE_CHILDREN_CHANGED)
+		childWatchesToTrigger := s.watches.Trigger(parentPath, pbzk.WatchEvent_EVENT_TYPE_ZNODE_CHILDREN_CHANGED)
+		s.triggerEachWatch(zxid, childWatchesToTrigger, parentPath, pbzk.WatchEvent_EVENT_TYPE_ZNODE_CHILDREN_CHANGED)
 	}
-
-	// Actually trigger the watches.
-	s.triggerEachWatch(watchesToTrigger, watchType)
-	s.triggerEachWatch(childWatchesToTrigger, pbzk.WatchEvent_EVENT_TYPE_ZNODE_CHILDREN_CHANGED)
 }
 
-func (s *Server) extractWatches(path string, watchType pbzk.WatchEvent_EventType) []*znode.Watch {
-	var watchesToTrigger []*znode.Watch
-	var clientIDsToRemove []string
-	for _, watch := range s.watches[path] {
-		if slices.Contains(watch.WatchTypes, watchType) {
-			watchesToTrigger = append(watchesToTrigger, watch)
-			clientIDsToRemove = append(clientIDsToRemove, watch.ClientID)
-		}
-	}
-	for _, id := range clientIDsToRemove {
-		s.watches[path] = slices.DeleteFunc(s.watches[path], func(watch *znode.Watch) bool {
-			return watch.ClientID == id
-		})
-	}
-	return watchesToTrigger
+// WatchStats counts the watches that clients have set.
+func (s *Server) WatchStats() znode.WatchStats {
+	return s.watches.Stats()
 }
 
-func (s *Server) triggerEachWatch(watches []*znode.Watch, watchType pbzk.WatchEvent_EventType) {
-	for _, w := range watches {
-		// No need to capture loop var since we're using Go 1.22.
-		// Trigger each watch in a separate goroutine since adding to the messages channel is blocking.
-		go func() {
-			if sess, ok := s.sessions[w.ClientID]; ok {
-				event := &session.Event{
-					WatchEvent: &pbzk.WatchEvent{
-						Type: watchType,
-					},
-				}
-				sess.Messages <- event
-			}
-		}()
+// triggerEachWatch queues the event to be sent to each of the clients. The event is queued before the
+// response to the request that caused it, so the client sees the event before it can see the new data.
+func (s *Server) triggerEachWatch(zxid int64, clientIDs []string, path string, watchType pbzk.WatchEvent_EventType) {
+	if path == "" {
+		path = "/"
+	}
+	for _, clientID := range clientIDs {
+		s.sessionsMu.Lock()
+		sess, ok := s.sessions[clientID]
+		s.sessionsMu.Unlock()
+		if !ok {
+			continue
+		}
+		event := &pbzk.ZookeeperResponse{
+			Zxid: zxid,
+			Message: &pbzk.ZookeeperResponse_WatchEvent{
+				WatchEvent: &pbzk.WatchEvent{
+					Type: watchType,
+					Path: path,
+				},
+			},
+		}
+		if !sess.Outbox.Push(event) {
+			// The connection is dropped once the outbox overflows, so the client will have to resync.
+			log.Printf("Dropping watch event for session [%s] since it isn't keeping up with its messages\n", clientID)
+		}
 	}
 }
 
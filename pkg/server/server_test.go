@@ -3,16 +3,26 @@ package server
 import (
 	"context"
 	"fmt"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/mikekulinski/zookeeper/pkg/auth"
+	"github.com/mikekulinski/zookeeper/pkg/config"
+	"github.com/mikekulinski/zookeeper/pkg/quorum"
 	"github.com/mikekulinski/zookeeper/pkg/session"
 	"github.com/mikekulinski/zookeeper/pkg/utils"
 	"github.com/mikekulinski/zookeeper/pkg/znode"
 	mock_db "github.com/mikekulinski/zookeeper/pkg/znode/mocks"
 	pbzk "github.com/mikekulinski/zookeeper/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 type serverTestSuite struct {
@@ -24,11 +34,17 @@ type serverTestSuite struct {
 func (s *serverTestSuite) SetupTest() {
 	ctrl := gomock.NewController(s.T())
 	s.MockDB = mock_db.NewMockZKDB(ctrl)
+	// None of the mocked trees have quotas.
+	s.MockDB.EXPECT().Quotas(gomock.Any()).Return(nil).AnyTimes()
 
 	s.ZK = NewServer()
 	s.ZK.db = s.MockDB
 }
 
+func (s *serverTestSuite) TearDownTest() {
+	s.ZK.Close()
+}
+
 // TestServer_Create_Standard verifies that we handle create edge cases properly for normal nodes.
 func (s *serverTestSuite) TestServer_Create_Standard() {
 	var clientID = uuid.New().String()
@@ -48,6 +64,7 @@ func (s *serverTestSuite) TestServer_Create_Standard() {
 			name: "error with create",
 			path: "/x/y/z",
 			testFunc: func() {
+				s.MockDB.EXPECT().Get("/x/y").Return(nil)
 				s.MockDB.EXPECT().Create(gomock.Any()).Return(nil, fmt.Errorf("error with create"))
 			},
 			errorExpected: true,
@@ -56,6 +73,7 @@ func (s *serverTestSuite) TestServer_Create_Standard() {
 			name: "valid create, standard node",
 			path: "/xyz",
 			testFunc: func() {
+				s.MockDB.EXPECT().Get("").Return(znode.NewZNode("", znode.ZNodeType_STANDARD, "", nil))
 				s.MockDB.EXPECT().Create(gomock.Any()).Return(
 					znode.NewZNode(
 						"/xyz",
@@ -111,7 +129,9 @@ func (s *serverTestSuite) TestServer_Create_Ephemeral() {
 			testFunc: func() {
 				// Make sure to remove the session if one exists.
 				delete(s.ZK.sessions, clientID)
-				s.MockDB.EXPECT().Create(gomock.Any()).Return(newNode, nil)
+				s.MockDB.EXPECT().Get("").Return(nil)
+				// The node must never be created, or it would outlive the session.
+				s.MockDB.EXPECT().Create(gomock.Any()).Times(0)
 			},
 			errorExpected: true,
 		},
@@ -120,7 +140,8 @@ func (s *serverTestSuite) TestServer_Create_Ephemeral() {
 			path: "/xyz",
 			testFunc: func() {
 				// Make sure to create a session since it's required to be valid.
-				s.ZK.sessions[clientID] = session.NewSession()
+				s.ZK.sessions[clientID] = session.NewSession(config.DefaultTickTime)
+				s.MockDB.EXPECT().Get("").Return(nil)
 				s.MockDB.EXPECT().Create(gomock.Any()).Return(newNode, nil)
 			},
 			errorExpected: false,
@@ -136,6 +157,7 @@ func (s *serverTestSuite) TestServer_Create_Ephemeral() {
 
 			req := &pbzk.CreateRequest{
 				Path: test.path,
+				Mode: pbzk.CreateRequest_MODE_EPHEMERAL,
 			}
 			_, err := s.ZK.Create(ctx, req)
 			if test.errorExpected {
@@ -147,102 +169,154 @@ func (s *serverTestSuite) TestServer_Create_Ephemeral() {
 	}
 }
 
-//	func (s *serverTestSuite) TestServer_Create_Sequential() {
-//		const existingNodeName = "existing_5"
-//		tests := []struct {
-//			name           string
-//			path           string
-//			parentNodeType znode.ZNodeType
-//			errorExpected  bool
-//		}{
-//			{
-//				name:          "node already exists",
-//				path:          "/existing",
-//				errorExpected: true,
-//			},
-//			{
-//				name:          "valid create, root",
-//				path:          "/new",
-//				errorExpected: false,
-//			},
-//			{
-//				name:          "valid create, child of existing node",
-//				path:          fmt.Sprintf("/%s/new", existingNodeName),
-//				errorExpected: false,
-//			},
-//			{
-//				name:           "parent node is ephemeral",
-//				path:           fmt.Sprintf("/%s/new", existingNodeName),
-//				parentNodeType: znode.ZNodeType_EPHEMERAL,
-//				errorExpected:  true,
-//			},
-//		}
-//		for _, test := range tests {
-//			s.Run(test.name, func() {
-//				ctx := context.Background()
-//				zk := NewServer()
-//				// Pre-init the server with some nodes so we can also test cases with existing nodes.
-//				zk.root.NextSequentialNode = 5
-//				existingNode := znode.NewZNode(existingNodeName, test.parentNodeType, "", nil)
-//				existingNode.NextSequentialNode = 5
-//				zk.root.Children = map[string]*znode.ZNode{
-//					existingNodeName: existingNode,
-//				}
-//
-//				req := &pbzk.CreateRequest{
-//					Path:  test.path,
-//					Flags: []pbzk.CreateRequest_Flag{pbzk.CreateRequest_FLAG_SEQUENTIAL},
-//				}
-//				resp, err := zk.Create(ctx, req)
-//				if test.errorExpected {
-//					s.Assert().Empty(resp.GetZNodeName())
-//					s.Assert().Error(err)
-//				} else {
-//					fmt.Println(resp.GetZNodeName())
-//					s.Assert().Contains(resp.GetZNodeName(), "new_5")
-//					s.Assert().NoError(err)
-//				}
-//			})
-//		}
-//	}
-//
-//	func (s *serverTestSuite) TestServer_Create_RegularThenSequential() {
-//		const existingNodeName = "existing"
-//		ctx := context.Background()
-//		zk := NewServer()
-//		// Pre-init the server with some nodes so we can also test cases with existing nodes.
-//		zk.root.NextSequentialNode = 5
-//		existingNode := znode.NewZNode(existingNodeName, znode.ZNodeType_STANDARD, "", nil)
-//		existingNode.NextSequentialNode = 5
-//		zk.root.Children = map[string]*znode.ZNode{
-//			existingNodeName: existingNode,
-//		}
-//
-//		// Create a regular node. It shouldn't have the sequential suffix.
-//		req := &pbzk.CreateRequest{
-//			Path: "/" + existingNodeName + "/standard",
-//		}
-//		resp, err := zk.Create(ctx, req)
-//		fmt.Println(resp.GetZNodeName())
-//		s.Assert().True(strings.HasSuffix(resp.GetZNodeName(), "standard"))
-//		s.Assert().NoError(err)
-//		// NextSequentialNode shouldn't be incremented.
-//		s.Assert().Equal(5, existingNode.NextSequentialNode)
-//
-//		// Create a sequential node. It should use 5 as the suffix. NextSequentialNode should only be incremented
-//		// after creating another sequential node.
-//		req = &pbzk.CreateRequest{
-//			Path:  "/" + existingNodeName + "/sequential",
-//			Flags: []pbzk.CreateRequest_Flag{pbzk.CreateRequest_FLAG_SEQUENTIAL},
-//		}
-//		resp, err = zk.Create(ctx, req)
-//		fmt.Println(resp.GetZNodeName())
-//		s.Assert().True(strings.HasSuffix(resp.GetZNodeName(), "sequential_5"))
-//		s.Assert().NoError(err)
-//		// NextSequentialNode should be incremented.
-//		s.Assert().Equal(6, existingNode.NextSequentialNode)
-//	}
-//
+// TestServer_Create_Modes verifies that every create mode, and the deprecated flags, create the right type of node.
+func (s *serverTestSuite) TestServer_Create_Modes() {
+	tests := []struct {
+		name          string
+		req           *pbzk.CreateRequest
+		nodeName      string
+		nodeType      znode.ZNodeType
+		errorExpected bool
+	}{
+		{
+			name:     "persistent",
+			req:      &pbzk.CreateRequest{Mode: pbzk.CreateRequest_MODE_PERSISTENT},
+			nodeName: "/node",
+			nodeType: znode.ZNodeType_STANDARD,
+		},
+		{
+			name:     "ephemeral",
+			req:      &pbzk.CreateRequest{Mode: pbzk.CreateRequest_MODE_EPHEMERAL},
+			nodeName: "/node",
+			nodeType: znode.ZNodeType_EPHEMERAL,
+		},
+		{
+			name:     "persistent sequential",
+			req:      &pbzk.CreateRequest{Mode: pbzk.CreateRequest_MODE_PERSISTENT_SEQUENTIAL},
+			nodeName: "/node0000000000",
+			nodeType: znode.ZNodeType_STANDARD,
+		},
+		{
+			name:     "ephemeral sequential",
+			req:      &pbzk.CreateRequest{Mode: pbzk.CreateRequest_MODE_EPHEMERAL_SEQUENTIAL},
+			nodeName: "/node0000000000",
+			nodeType: znode.ZNodeType_EPHEMERAL,
+		},
+		{
+			name:     "container",
+			req:      &pbzk.CreateRequest{Mode: pbzk.CreateRequest_MODE_CONTAINER},
+			nodeName: "/node",
+			nodeType: znode.ZNodeType_CONTAINER,
+		},
+		{
+			name:     "persistent with TTL",
+			req:      &pbzk.CreateRequest{Mode: pbzk.CreateRequest_MODE_PERSISTENT_WITH_TTL, TtlMs: 1000},
+			nodeName: "/node",
+			nodeType: znode.ZNodeType_TTL,
+		},
+		{
+			name:     "persistent sequential with TTL",
+			req:      &pbzk.CreateRequest{Mode: pbzk.CreateRequest_MODE_PERSISTENT_SEQUENTIAL_WITH_TTL, TtlMs: 1000},
+			nodeName: "/node0000000000",
+			nodeType: znode.ZNodeType_TTL,
+		},
+		{
+			name:     "no mode",
+			req:      &pbzk.CreateRequest{},
+			nodeName: "/node",
+			nodeType: znode.ZNodeType_STANDARD,
+		},
+		{
+			name: "ephemeral and sequential flags",
+			req: &pbzk.CreateRequest{
+				Flags: []pbzk.CreateRequest_Flag{pbzk.CreateRequest_FLAG_EPHEMERAL, pbzk.CreateRequest_FLAG_SEQUENTIAL},
+			},
+			nodeName: "/node0000000000",
+			nodeType: znode.ZNodeType_EPHEMERAL,
+		},
+		{
+			name:     "container flag",
+			req:      &pbzk.CreateRequest{Flags: []pbzk.CreateRequest_Flag{pbzk.CreateRequest_FLAG_CONTAINER}},
+			nodeName: "/node",
+			nodeType: znode.ZNodeType_CONTAINER,
+		},
+		{
+			name:     "TTL flag",
+			req:      &pbzk.CreateRequest{Flags: []pbzk.CreateRequest_Flag{pbzk.CreateRequest_FLAG_TTL}, TtlMs: 1000},
+			nodeName: "/node",
+			nodeType: znode.ZNodeType_TTL,
+		},
+		{
+			name: "mode and flags",
+			req: &pbzk.CreateRequest{
+				Mode:  pbzk.CreateRequest_MODE_PERSISTENT,
+				Flags: []pbzk.CreateRequest_Flag{pbzk.CreateRequest_FLAG_EPHEMERAL},
+			},
+			errorExpected: true,
+		},
+		{
+			name:          "ephemeral with a ttl",
+			req:           &pbzk.CreateRequest{Mode: pbzk.CreateRequest_MODE_EPHEMERAL, TtlMs: 1000},
+			errorExpected: true,
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			s.ZK.db = znode.NewDB()
+			sess, _, err := s.ZK.attachSession("", "", 0)
+			s.Require().NoError(err)
+			ctx := utils.SetIncomingClientIDHeader(context.Background(), sess.ID)
+
+			test.req.Path = "/node"
+			resp, err := s.ZK.Create(ctx, test.req)
+			if test.errorExpected {
+				s.Assert().Error(err)
+				s.Assert().Nil(s.ZK.db.Get("/node"))
+				return
+			}
+			s.Require().NoError(err)
+			s.Assert().Equal(test.nodeName, resp.GetZNodeName())
+			node := s.ZK.db.Get(test.nodeName)
+			s.Require().NotNil(node)
+			s.Assert().Equal(test.nodeType, node.NodeType)
+		})
+	}
+}
+
+// TestServer_Create_Sequential verifies that sequential nodes get zero-padded suffixes that sort in the order
+// the nodes were created, and that creating them fires watches under the full name.
+func (s *serverTestSuite) TestServer_Create_Sequential() {
+	s.ZK.db = znode.NewDB()
+	ctx := context.Background()
+	_, err := s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/queue"})
+	s.Require().NoError(err)
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/queue/standard"})
+	s.Require().NoError(err)
+
+	sess := session.NewSession(config.DefaultTickTime)
+	s.ZK.sessions[sess.ID] = sess
+	s.ZK.watches.Add(sess.ID, "/queue", znode.WatchKind_CHILDREN)
+
+	resp, err := s.ZK.Create(ctx, &pbzk.CreateRequest{
+		Path: "/queue/item-",
+		Mode: pbzk.CreateRequest_MODE_PERSISTENT_SEQUENTIAL,
+	})
+	s.Require().NoError(err)
+	// Creating the standard node already used up a sequence number.
+	s.Assert().Equal("/queue/item-0000000001", resp.GetZNodeName())
+	s.Assert().NotNil(s.ZK.db.Get("/queue/item-0000000001"))
+	s.Assert().Equal([]*pbzk.WatchEvent{
+		{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_CHILDREN_CHANGED, Path: "/queue"},
+	}, watchEvents(sess))
+
+	resp, err = s.ZK.Create(ctx, &pbzk.CreateRequest{
+		Path: "/queue/item-",
+		Mode: pbzk.CreateRequest_MODE_PERSISTENT_SEQUENTIAL,
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal("/queue/item-0000000002", resp.GetZNodeName())
+}
+
 //	func (s *serverTestSuite) TestServer_Delete() {
 //		const rootChildName = "rootChild"
 //		const childChildName = "childChild"
@@ -357,6 +431,7 @@ func (s *serverTestSuite) TestServer_Exists_NodeCreated() {
 			node: &znode.ZNode{
 				Name:    "/" + rootChildName,
 				Version: 2,
+				ACL:     znode.OpenACLUnsafe,
 				Data:    []byte("secret stuff"),
 			},
 			exists: true,
@@ -367,6 +442,7 @@ func (s *serverTestSuite) TestServer_Exists_NodeCreated() {
 			node: &znode.ZNode{
 				Name:    fmt.Sprintf("/%s/%s", rootChildName, childChildName),
 				Version: 10,
+				ACL:     znode.OpenACLUnsafe,
 				Data:    []byte("secret stuff"),
 			},
 			exists: true,
@@ -420,6 +496,7 @@ func (s *serverTestSuite) TestServer_GetData() {
 			node: &znode.ZNode{
 				Name:    "/" + rootChildName,
 				Version: 2,
+				ACL:     znode.OpenACLUnsafe,
 				Data:    []byte("secret stuff"),
 			},
 		},
@@ -429,6 +506,7 @@ func (s *serverTestSuite) TestServer_GetData() {
 			node: &znode.ZNode{
 				Name:    fmt.Sprintf("/%s/%s", rootChildName, childChildName),
 				Version: 10,
+				ACL:     znode.OpenACLUnsafe,
 				Data:    []byte("secret stuff"),
 			},
 		},
@@ -793,6 +871,1128 @@ func (s *serverTestSuite) TestServer_GetData() {
 //	}
 //}
 
+// TestServer_Reconfig verifies that we validate reconfig requests before committing the new config.
+func (s *serverTestSuite) TestServer_Reconfig() {
+	tests := []struct {
+		name          string
+		req           *pbzk.ReconfigRequest
+		testFunc      func()
+		version       int64
+		errorExpected bool
+	}{
+		{
+			name:          "empty reconfig",
+			req:           &pbzk.ReconfigRequest{FromConfig: -1},
+			testFunc:      func() {},
+			errorExpected: true,
+		},
+		{
+			name: "invalid server",
+			req: &pbzk.ReconfigRequest{
+				JoiningServers: []string{"server.1=zk1:2888"},
+				FromConfig:     -1,
+			},
+			testFunc:      func() {},
+			errorExpected: true,
+		},
+		{
+			name: "wrong config version",
+			req: &pbzk.ReconfigRequest{
+				JoiningServers: []string{"server.1=zk1:2888:3888"},
+				FromConfig:     5,
+			},
+			testFunc:      func() {},
+			errorExpected: true,
+		},
+		{
+			name: "removing a server that doesn't exist",
+			req: &pbzk.ReconfigRequest{
+				LeavingServers: []int64{7},
+				FromConfig:     -1,
+			},
+			testFunc:      func() {},
+			errorExpected: true,
+		},
+		{
+			name: "invalid group",
+			req: &pbzk.ReconfigRequest{
+				JoiningServers: []string{"server.1=zk1:2888:3888"},
+				Groups:         []string{"group.1=a"},
+				FromConfig:     -1,
+			},
+			testFunc:      func() {},
+			errorExpected: true,
+		},
+		{
+			name: "invalid weight",
+			req: &pbzk.ReconfigRequest{
+				JoiningServers: []string{"server.1=zk1:2888:3888"},
+				Weights:        []string{"weight.1=-1"},
+				FromConfig:     -1,
+			},
+			testFunc:      func() {},
+			errorExpected: true,
+		},
+		{
+			name: "error with reconfig",
+			req: &pbzk.ReconfigRequest{
+				JoiningServers: []string{"server.1=zk1:2888:3888"},
+				FromConfig:     -1,
+			},
+			testFunc: func() {
+				s.MockDB.EXPECT().Reconfig(gomock.Any()).Return(fmt.Errorf("error with reconfig"))
+			},
+			errorExpected: true,
+		},
+		{
+			name: "valid reconfig",
+			req: &pbzk.ReconfigRequest{
+				JoiningServers: []string{
+					"server.1=zk1:2888:3888",
+					"server.2=zk2:2888:3888;2181",
+				},
+				Groups:     []string{"group.1=1:2"},
+				Weights:    []string{"weight.1=3"},
+				FromConfig: 0,
+			},
+			testFunc: func() {
+				s.MockDB.EXPECT().Reconfig(gomock.Any()).Return(nil)
+			},
+			version: 1,
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			ctx := context.Background()
+			s.ZK.config, _ = quorum.NewConfig()
+			s.MockDB.EXPECT().Get(znode.ConfigPath).Return(znode.NewZNode(znode.ConfigPath, znode.ZNodeType_STANDARD, "", nil)).AnyTimes()
+
+			// Make sure to run the function for each test that sets up the server state.
+			test.testFunc()
+
+			resp, err := s.ZK.Reconfig(ctx, test.req)
+			if test.errorExpected {
+				s.Assert().Error(err)
+				// The config should be untouched if the reconfig failed.
+				s.Assert().Empty(s.ZK.config.Servers)
+				return
+			}
+			s.Require().NoError(err)
+			s.Assert().Equal(test.version, resp.GetVersion())
+			s.Assert().Equal(s.ZK.config.Bytes(), resp.GetConfig())
+			s.Assert().Equal([]int64{1, 2}, s.ZK.config.Voters())
+			s.Assert().Equal(map[int64][]int64{1: {1, 2}}, s.ZK.config.Groups)
+			s.Assert().Equal(map[int64]int64{1: 3}, s.ZK.config.Weights)
+		})
+	}
+}
+
+// TestServer_Reconfig_Admin verifies that the config node starts with the ensemble config, that only the
+// reconfig admin can change it, and that concurrent reconfigs from the same version can't both succeed.
+func (s *serverTestSuite) TestServer_Reconfig_Admin() {
+	cfg := config.Default()
+	var err error
+	cfg.Ensemble, err = quorum.ParseConfig([]byte("server.1=zk1:2888:3888\n"))
+	s.Require().NoError(err)
+	cfg.ServerID = 1
+	cfg.ReconfigAdmin = auth.GenerateDigest("admin", "secret")
+	zk := NewServerWithConfig(cfg)
+	defer zk.Close()
+
+	node := zk.db.Get(znode.ConfigPath)
+	s.Require().NotNil(node)
+	s.Assert().Equal(cfg.Ensemble.Bytes(), node.Data)
+
+	sess := zk.StartSession(0)
+	ctx := utils.SetIncomingClientIDHeader(context.Background(), sess.ID)
+	req := &pbzk.ReconfigRequest{JoiningServers: []string{"server.2=zk2:2888:3888"}, FromConfig: -1}
+	_, err = zk.Reconfig(ctx, req)
+	s.Assert().ErrorIs(err, ErrNoAuth)
+
+	_, err = zk.AddAuth(ctx, &pbzk.AddAuthRequest{Scheme: auth.SchemeDigest, Auth: []byte("admin:secret")})
+	s.Require().NoError(err)
+
+	// Both reconfigs expect version 0, so only the first one to be applied can succeed.
+	errs := make(chan error, 2)
+	for _, line := range []string{"server.2=zk2:2888:3888", "server.3=zk3:2888:3888"} {
+		go func() {
+			_, err := zk.Reconfig(ctx, &pbzk.ReconfigRequest{JoiningServers: []string{line}, FromConfig: 0})
+			errs <- err
+		}()
+	}
+	var failed int
+	for range 2 {
+		if <-errs != nil {
+			failed++
+		}
+	}
+	s.Assert().Equal(1, failed)
+	s.Assert().Len(zk.config.Servers, 2)
+	s.Assert().Equal(int64(1), zk.config.Version)
+	s.Assert().Equal(zk.config.Bytes(), zk.db.Get(znode.ConfigPath).Data)
+}
+
+// TestServer_Commit_Quorum verifies that a write is only committed if our own ack makes up a quorum of the
+// ensemble, as decided by the verifier the config picks.
+func (s *serverTestSuite) TestServer_Commit_Quorum() {
+	tests := []struct {
+		name          string
+		config        string
+		errorExpected bool
+	}{
+		{
+			name: "standalone",
+		},
+		{
+			name:   "only server",
+			config: "server.1=zk1:2888:3888\n",
+		},
+		{
+			name:          "majority",
+			config:        "server.1=zk1:2888:3888\nserver.2=zk2:2888:3888\nserver.3=zk3:2888:3888\n",
+			errorExpected: true,
+		},
+		{
+			name:   "weighted",
+			config: "server.1=zk1:2888:3888\nserver.2=zk2:2888:3888\nserver.3=zk3:2888:3888\nweight.1=3\n",
+		},
+		{
+			name:          "weighted against us",
+			config:        "server.1=zk1:2888:3888\nserver.2=zk2:2888:3888\nserver.3=zk3:2888:3888\nweight.2=3\n",
+			errorExpected: true,
+		},
+		{
+			name: "grouped",
+			config: "server.1=zk1:2888:3888\nserver.2=zk2:2888:3888\nserver.3=zk3:2888:3888\n" +
+				"group.1=1\ngroup.2=2:3\nweight.2=0\nweight.3=0\n",
+		},
+		{
+			name: "grouped without a majority of groups",
+			config: "server.1=zk1:2888:3888\nserver.2=zk2:2888:3888\nserver.3=zk3:2888:3888\n" +
+				"group.1=1\ngroup.2=2\ngroup.3=3\n",
+			errorExpected: true,
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			s.ZK.db = znode.NewDB()
+			s.ZK.lastZxid.Store(0)
+			s.ZK.serverID = 1
+			var err error
+			s.ZK.config, err = quorum.ParseConfig([]byte(test.config))
+			s.Require().NoError(err)
+
+			_, err = s.ZK.Create(context.Background(), &pbzk.CreateRequest{Path: "/zoo"})
+			if test.errorExpected {
+				s.Assert().ErrorIs(err, ErrNoQuorum)
+				s.Assert().Nil(s.ZK.db.Get("/zoo"))
+				s.Assert().Equal(int64(0), s.ZK.lastZxid.Load())
+				return
+			}
+			s.Require().NoError(err)
+			s.Assert().NotNil(s.ZK.db.Get("/zoo"))
+			s.Assert().Equal(int64(1), s.ZK.lastZxid.Load())
+		})
+	}
+}
+
+// TestServer_ApplyReconfig verifies that the config is taken from the reconfig transaction, so replaying the
+// log restores it.
+func (s *serverTestSuite) TestServer_ApplyReconfig() {
+	s.ZK.db = znode.NewDB()
+	newConfig := []byte("server.1=zk1:2888:3888:participant\nversion=3\n")
+	txn := &pbzk.Transaction{
+		Zxid: 1,
+		Txn:  &pbzk.Transaction_Reconfig{Reconfig: &pbzk.ReconfigTxn{Config: newConfig}},
+	}
+	_, err := s.ZK.apply(txn)
+	s.Require().NoError(err)
+	s.Assert().Equal(int64(3), s.ZK.config.Version)
+	s.Assert().Equal([]int64{1}, s.ZK.config.Voters())
+
+	// A config that doesn't parse is rejected before the tree is changed.
+	txn.Txn = &pbzk.Transaction_Reconfig{Reconfig: &pbzk.ReconfigTxn{Config: []byte("server.2=zk2")}}
+	_, err = s.ZK.apply(txn)
+	s.Assert().Error(err)
+	s.Assert().Equal(newConfig, s.ZK.db.Get(znode.ConfigPath).Data)
+	s.Assert().Equal(int64(3), s.ZK.config.Version)
+}
+
+// TestServer_ACL verifies that each request checks the ACL of the right node before changing the tree.
+func (s *serverTestSuite) TestServer_ACL() {
+	ctx := context.Background()
+	s.ZK.db = znode.NewDB()
+	readOnly := []*pbzk.ACL{{Perms: int32(pbzk.Permission_PERMISSION_READ), Id: znode.AnyoneID}}
+	noAccess := []*pbzk.ACL{{Perms: int32(pbzk.Permission_PERMISSION_ADMIN), Id: znode.AnyoneID}}
+
+	_, err := s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/open"})
+	s.Require().NoError(err)
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/open/child"})
+	s.Require().NoError(err)
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/readonly", Acl: readOnly})
+	s.Require().NoError(err)
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/hidden", Acl: noAccess})
+	s.Require().NoError(err)
+
+	tests := []struct {
+		name          string
+		request       func() error
+		errorExpected bool
+	}{
+		{
+			name: "create under open node",
+			request: func() error {
+				_, err := s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/open/new"})
+				return err
+			},
+		},
+		{
+			name: "create under read only node",
+			request: func() error {
+				_, err := s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/readonly/new"})
+				return err
+			},
+			errorExpected: true,
+		},
+		{
+			name: "set data on read only node",
+			request: func() error {
+				_, err := s.ZK.SetData(ctx, &pbzk.SetDataRequest{Path: "/readonly", Data: []byte("data"), Version: -1})
+				return err
+			},
+			errorExpected: true,
+		},
+		{
+			name: "get data on read only node",
+			request: func() error {
+				_, err := s.ZK.GetData(ctx, &pbzk.GetDataRequest{Path: "/readonly"})
+				return err
+			},
+		},
+		{
+			name: "get data without read permission",
+			request: func() error {
+				_, err := s.ZK.GetData(ctx, &pbzk.GetDataRequest{Path: "/hidden"})
+				return err
+			},
+			errorExpected: true,
+		},
+		{
+			name: "get children without read permission",
+			request: func() error {
+				_, err := s.ZK.GetChildren(ctx, &pbzk.GetChildrenRequest{Path: "/hidden"})
+				return err
+			},
+			errorExpected: true,
+		},
+		{
+			name: "exists without read permission",
+			request: func() error {
+				_, err := s.ZK.Exists(ctx, &pbzk.ExistsRequest{Path: "/hidden"})
+				return err
+			},
+			errorExpected: true,
+		},
+		{
+			name: "get ACL with admin permission",
+			request: func() error {
+				_, err := s.ZK.GetACL(ctx, &pbzk.GetACLRequest{Path: "/hidden"})
+				return err
+			},
+		},
+		{
+			name: "set ACL without admin permission",
+			request: func() error {
+				_, err := s.ZK.SetACL(ctx, &pbzk.SetACLRequest{Path: "/readonly", Acl: znode.OpenACLUnsafe, Aversion: -1})
+				return err
+			},
+			errorExpected: true,
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			err := test.request()
+			if test.errorExpected {
+				s.Assert().ErrorIs(err, ErrNoAuth)
+			} else {
+				s.Assert().NoError(err)
+			}
+		})
+	}
+
+	// Deleting needs permission on the parent, so locking down the parent protects the children.
+	_, err = s.ZK.SetACL(ctx, &pbzk.SetACLRequest{Path: "/open", Acl: readOnly, Aversion: 0})
+	s.Require().NoError(err)
+	_, err = s.ZK.Delete(ctx, &pbzk.DeleteRequest{Path: "/open/child", Version: -1})
+	s.Assert().ErrorIs(err, ErrNoAuth)
+	s.Assert().NotNil(s.ZK.db.Get("/open/child"))
+}
+
+// TestServer_HandleClientRequest_Errors verifies that a failed request gets an error response, and that only
+// failing to authenticate or breaking the protocol ends the session.
+func (s *serverTestSuite) TestServer_HandleClientRequest_Errors() {
+	s.ZK.db = znode.NewDB()
+	sess := s.ZK.StartSession(0)
+	ctx := utils.SetIncomingClientIDHeader(context.Background(), sess.ID)
+	_, err := s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/hidden", Acl: []*pbzk.ACL{{
+		Perms: int32(pbzk.Permission_PERMISSION_ADMIN),
+		Id:    znode.AnyoneID,
+	}}})
+	s.Require().NoError(err)
+
+	resp, err := s.ZK.handleClientRequest(ctx, &pbzk.ZookeeperRequest{
+		Message: &pbzk.ZookeeperRequest_GetData{GetData: &pbzk.GetDataRequest{Path: "/hidden"}},
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal(int32(codes.PermissionDenied), resp.GetError().GetCode())
+	s.Assert().Contains(resp.GetError().GetMessage(), ErrNoAuth.Error())
+
+	resp, err = s.ZK.handleClientRequest(ctx, &pbzk.ZookeeperRequest{
+		Message: &pbzk.ZookeeperRequest_Create{Create: &pbzk.CreateRequest{Path: "/hidden"}},
+	})
+	s.Require().NoError(err)
+	s.Assert().NotNil(resp.GetError())
+
+	_, err = s.ZK.handleClientRequest(ctx, &pbzk.ZookeeperRequest{
+		Message: &pbzk.ZookeeperRequest_AddAuth{AddAuth: &pbzk.AddAuthRequest{Scheme: auth.SchemeDigest, Auth: []byte("alice")}},
+	})
+	s.Assert().Equal(codes.Unauthenticated, status.Code(err))
+
+	_, err = s.ZK.handleClientRequest(ctx, &pbzk.ZookeeperRequest{
+		Message: &pbzk.ZookeeperRequest_Connect{Connect: &pbzk.ConnectRequest{}},
+	})
+	s.Assert().Error(err)
+}
+
+// TestServer_AddAuth verifies that the ids a client authenticates as are checked against ACLs.
+func (s *serverTestSuite) TestServer_AddAuth() {
+	s.ZK.db = znode.NewDB()
+	sess := s.ZK.StartSession(0)
+	ctx := utils.SetIncomingClientIDHeader(context.Background(), sess.ID)
+	aliceOnly := []*pbzk.ACL{{
+		Perms: int32(pbzk.Permission_PERMISSION_ALL),
+		Id:    &pbzk.Id{Scheme: auth.SchemeDigest, Id: auth.GenerateDigest("alice", "secret")},
+	}}
+	localOnly := []*pbzk.ACL{{
+		Perms: int32(pbzk.Permission_PERMISSION_ALL),
+		Id:    &pbzk.Id{Scheme: auth.SchemeIP, Id: "10.0.0.0/8"},
+	}}
+	_, err := s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/alice", Acl: aliceOnly})
+	s.Require().NoError(err)
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/local", Acl: localOnly})
+	s.Require().NoError(err)
+
+	_, err = s.ZK.GetData(ctx, &pbzk.GetDataRequest{Path: "/alice"})
+	s.Assert().ErrorIs(err, ErrNoAuth)
+
+	// Failing to authenticate ends the session with an auth failure.
+	_, err = s.ZK.AddAuth(ctx, &pbzk.AddAuthRequest{Scheme: "kerberos", Auth: []byte("alice")})
+	s.Assert().Equal(codes.Unauthenticated, status.Code(err))
+	_, err = s.ZK.AddAuth(ctx, &pbzk.AddAuthRequest{Scheme: auth.SchemeDigest, Auth: []byte("alice")})
+	s.Assert().Equal(codes.Unauthenticated, status.Code(err))
+
+	_, err = s.ZK.AddAuth(ctx, &pbzk.AddAuthRequest{Scheme: auth.SchemeDigest, Auth: []byte("alice:secret")})
+	s.Require().NoError(err)
+	_, err = s.ZK.GetData(ctx, &pbzk.GetDataRequest{Path: "/alice"})
+	s.Assert().NoError(err)
+
+	// Clients are identified by their address as soon as they connect.
+	_, err = s.ZK.GetData(ctx, &pbzk.GetDataRequest{Path: "/local"})
+	s.Assert().ErrorIs(err, ErrNoAuth)
+	local := peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.1.2.3"), Port: 5555}})
+	local = s.ZK.authenticateConnection(local)
+	_, err = s.ZK.GetData(local, &pbzk.GetDataRequest{Path: "/local"})
+	s.Assert().NoError(err)
+
+	// Authenticating by address only lasts as long as the connection, so the session doesn't take the id
+	// with it when it moves.
+	_, err = s.ZK.AddAuth(local, &pbzk.AddAuthRequest{Scheme: auth.SchemeIP})
+	s.Require().NoError(err)
+	s.Assert().Equal([]*pbzk.Id{{Scheme: auth.SchemeDigest, Id: auth.GenerateDigest("alice", "secret")}}, sess.AuthIDs())
+	remote := peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("192.168.1.1"), Port: 5555}})
+	remote = s.ZK.authenticateConnection(remote)
+	_, err = s.ZK.GetData(remote, &pbzk.GetDataRequest{Path: "/local"})
+	s.Assert().ErrorIs(err, ErrNoAuth)
+	_, err = s.ZK.GetData(remote, &pbzk.GetDataRequest{Path: "/alice"})
+	s.Assert().NoError(err)
+}
+
+// TestServer_SetACL verifies that we check the ACL version and validate the new ACL.
+func (s *serverTestSuite) TestServer_SetACL() {
+	ctx := context.Background()
+	s.ZK.db = znode.NewDB()
+	_, err := s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/node"})
+	s.Require().NoError(err)
+
+	tests := []struct {
+		name          string
+		req           *pbzk.SetACLRequest
+		aversion      int64
+		errorExpected bool
+	}{
+		{
+			name:          "node missing",
+			req:           &pbzk.SetACLRequest{Path: "/missing", Acl: znode.OpenACLUnsafe, Aversion: -1},
+			errorExpected: true,
+		},
+		{
+			name:          "empty ACL",
+			req:           &pbzk.SetACLRequest{Path: "/node", Aversion: -1},
+			errorExpected: true,
+		},
+		{
+			name: "invalid ACL",
+			req: &pbzk.SetACLRequest{
+				Path:     "/node",
+				Acl:      []*pbzk.ACL{{Perms: int32(pbzk.Permission_PERMISSION_ALL), Id: &pbzk.Id{Scheme: "unknown", Id: "id"}}},
+				Aversion: -1,
+			},
+			errorExpected: true,
+		},
+		{
+			name:          "wrong ACL version",
+			req:           &pbzk.SetACLRequest{Path: "/node", Acl: znode.OpenACLUnsafe, Aversion: 3},
+			errorExpected: true,
+		},
+		{
+			name:     "valid",
+			req:      &pbzk.SetACLRequest{Path: "/node", Acl: znode.OpenACLUnsafe, Aversion: 0},
+			aversion: 1,
+		},
+		{
+			name:     "ignore version check",
+			req:      &pbzk.SetACLRequest{Path: "/node", Acl: znode.OpenACLUnsafe, Aversion: -1},
+			aversion: 2,
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			_, err := s.ZK.SetACL(ctx, test.req)
+			if test.errorExpected {
+				s.Assert().Error(err)
+				return
+			}
+			s.Require().NoError(err)
+			resp, err := s.ZK.GetACL(ctx, &pbzk.GetACLRequest{Path: "/node"})
+			s.Require().NoError(err)
+			s.Assert().Equal(test.aversion, resp.GetAversion())
+		})
+	}
+}
+
+// TestServer_MaxDataSize verifies that we reject data that is too large to store in a single node.
+func (s *serverTestSuite) TestServer_MaxDataSize() {
+	ctx := context.Background()
+	s.ZK.db = znode.NewDB()
+	s.ZK.maxDataSize = 4
+	_, err := s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/node", Data: []byte("1234")})
+	s.Require().NoError(err)
+
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/large", Data: []byte("12345")})
+	s.Assert().ErrorIs(err, ErrDataTooLarge)
+	_, err = s.ZK.SetData(ctx, &pbzk.SetDataRequest{Path: "/node", Data: []byte("12345"), Version: -1})
+	s.Assert().ErrorIs(err, ErrDataTooLarge)
+	s.Assert().Equal([]byte("1234"), s.ZK.db.Get("/node").Data)
+}
+
+// TestServer_Quota verifies that hard limits reject writes, and soft limits only count them.
+func (s *serverTestSuite) TestServer_Quota() {
+	ctx := context.Background()
+	s.ZK.db = znode.NewDB()
+	_, err := s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/app"})
+	s.Require().NoError(err)
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/zookeeper/quota/app"})
+	s.Require().NoError(err)
+
+	// The limits have to be valid, and for a subtree that exists.
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/zookeeper/quota/app/zookeeper_limits", Data: []byte("nodes=1")})
+	s.Assert().Error(err)
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/zookeeper/quota/missing"})
+	s.Require().NoError(err)
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/zookeeper/quota/missing/zookeeper_limits", Data: []byte("count=1")})
+	s.Assert().Error(err)
+	// Only the server writes the stats.
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/zookeeper/quota/app/zookeeper_stats"})
+	s.Assert().Error(err)
+
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{
+		Path: "/zookeeper/quota/app/zookeeper_limits",
+		Data: []byte("count=2,countHardLimit=3,bytesHardLimit=5"),
+	})
+	s.Require().NoError(err)
+
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/app/a", Data: []byte("12")})
+	s.Require().NoError(err)
+	// Going over the soft limit is allowed, but counted.
+	before := softQuotaExceeded.Value()
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/app/b", Data: []byte("3")})
+	s.Require().NoError(err)
+	s.Assert().Equal(before+1, softQuotaExceeded.Value())
+
+	// Going over a hard limit isn't.
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/app/b/c"})
+	s.Assert().ErrorIs(err, znode.ErrQuotaExceeded)
+	_, err = s.ZK.SetData(ctx, &pbzk.SetDataRequest{Path: "/app/a", Data: []byte("12345"), Version: -1})
+	s.Assert().ErrorIs(err, znode.ErrQuotaExceeded)
+	// Shrinking the subtree is always allowed.
+	_, err = s.ZK.SetData(ctx, &pbzk.SetDataRequest{Path: "/app/a", Data: []byte("1"), Version: -1})
+	s.Assert().NoError(err)
+
+	resp, err := s.ZK.GetData(ctx, &pbzk.GetDataRequest{Path: "/zookeeper/quota/app/zookeeper_stats"})
+	s.Require().NoError(err)
+	s.Assert().Equal([]byte("count=3,bytes=2"), resp.GetData())
+}
+
+// TestServer_TTL verifies that the reaper deletes TTL nodes once they expire.
+func (s *serverTestSuite) TestServer_TTL() {
+	ctx := context.Background()
+	s.ZK.db = znode.NewDB()
+	const ttlMode = pbzk.CreateRequest_MODE_PERSISTENT_WITH_TTL
+	_, err := s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/expires", Mode: ttlMode, TtlMs: 1})
+	s.Require().NoError(err)
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/parent", Mode: ttlMode, TtlMs: 1})
+	s.Require().NoError(err)
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/parent/child"})
+	s.Require().NoError(err)
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/lasts", Mode: ttlMode, TtlMs: time.Hour.Milliseconds()})
+	s.Require().NoError(err)
+
+	time.Sleep(5 * time.Millisecond)
+	s.ZK.reapExpiredNodes()
+	s.Assert().Nil(s.ZK.db.Get("/expires"))
+	s.Assert().NotNil(s.ZK.db.Get("/parent"))
+	s.Assert().NotNil(s.ZK.db.Get("/lasts"))
+
+	// Once its children are gone, the node can expire.
+	_, err = s.ZK.Delete(ctx, &pbzk.DeleteRequest{Path: "/parent/child", Version: -1})
+	s.Require().NoError(err)
+	s.ZK.reapExpiredNodes()
+	s.Assert().Nil(s.ZK.db.Get("/parent"))
+}
+
+// TestServer_Container verifies that the reaper deletes containers once their last child is deleted, and
+// that watchers are told about it.
+func (s *serverTestSuite) TestServer_Container() {
+	ctx := context.Background()
+	s.ZK.db = znode.NewDB()
+	s.ZK.containerGracePeriod = time.Hour
+	const containerMode = pbzk.CreateRequest_MODE_CONTAINER
+	_, err := s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/locks", Mode: containerMode})
+	s.Require().NoError(err)
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/new", Mode: containerMode})
+	s.Require().NoError(err)
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/locks/lock"})
+	s.Require().NoError(err)
+
+	s.ZK.reapExpiredNodes()
+	s.Assert().NotNil(s.ZK.db.Get("/locks"))
+
+	const watcherID = "watcher"
+	s.ZK.watches.Add(watcherID, "/locks", znode.WatchKind_DATA)
+	sess := session.NewSession(config.DefaultTickTime)
+	s.ZK.sessions[watcherID] = sess
+
+	_, err = s.ZK.Delete(ctx, &pbzk.DeleteRequest{Path: "/locks/lock", Version: -1})
+	s.Require().NoError(err)
+	s.ZK.reapExpiredNodes()
+	s.Assert().Nil(s.ZK.db.Get("/locks"))
+	// New containers get a grace period to add their first child.
+	s.Assert().NotNil(s.ZK.db.Get("/new"))
+
+	s.Assert().Equal([]*pbzk.WatchEvent{
+		{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_DELETED, Path: "/locks"},
+	}, watchEvents(sess))
+}
+
+// scanningDB runs afterScan once it has found the expired nodes, before the reaper deletes them.
+type scanningDB struct {
+	znode.ZKDB
+	afterScan func()
+}
+
+func (d *scanningDB) ExpiredNodes(nowMs int64, containerGracePeriod time.Duration) []*znode.ZNode {
+	expired := d.ZKDB.ExpiredNodes(nowMs, containerGracePeriod)
+	d.afterScan()
+	return expired
+}
+
+// TestServer_ReapChangedNode verifies that the reaper keeps an expired node that a client used after the
+// reaper found it.
+func (s *serverTestSuite) TestServer_ReapChangedNode() {
+	ctx := context.Background()
+	s.ZK.db = znode.NewDB()
+	s.ZK.containerGracePeriod = 0
+	_, err := s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/locks", Mode: pbzk.CreateRequest_MODE_CONTAINER})
+	s.Require().NoError(err)
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/ttl", Mode: pbzk.CreateRequest_MODE_PERSISTENT_WITH_TTL, TtlMs: 1})
+	s.Require().NoError(err)
+	time.Sleep(5 * time.Millisecond)
+	s.ZK.db = &scanningDB{
+		ZKDB: s.ZK.db,
+		afterScan: func() {
+			_, err := s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/locks/lock"})
+			s.Require().NoError(err)
+			_, err = s.ZK.SetData(ctx, &pbzk.SetDataRequest{Path: "/ttl", Data: []byte("data"), Version: -1})
+			s.Require().NoError(err)
+		},
+	}
+
+	s.ZK.reapExpiredNodes()
+	s.Assert().NotNil(s.ZK.db.Get("/locks"))
+	s.Assert().NotNil(s.ZK.db.Get("/locks/lock"))
+	s.Assert().NotNil(s.ZK.db.Get("/ttl"))
+}
+
+// TestServer_AddWatch verifies that persistent watches fire for every event, and recursive watches fire for
+// every event in the subtree.
+func (s *serverTestSuite) TestServer_AddWatch() {
+	s.ZK.db = znode.NewDB()
+	const persistentID, recursiveID = "persistent", "recursive"
+	persistent := session.NewSession(config.DefaultTickTime)
+	s.ZK.sessions[persistentID] = persistent
+	recursive := session.NewSession(config.DefaultTickTime)
+	s.ZK.sessions[recursiveID] = recursive
+	persistentCtx := utils.SetIncomingClientIDHeader(context.Background(), persistentID)
+	recursiveCtx := utils.SetIncomingClientIDHeader(context.Background(), recursiveID)
+
+	_, err := s.ZK.AddWatch(persistentCtx, &pbzk.AddWatchRequest{Path: "/app"})
+	s.Assert().Error(err, "the mode is required")
+	_, err = s.ZK.AddWatch(persistentCtx, &pbzk.AddWatchRequest{Path: "/app", Mode: pbzk.AddWatchRequest_MODE_PERSISTENT})
+	s.Require().NoError(err)
+	// Adding the same watch again doesn't make it fire twice.
+	_, err = s.ZK.AddWatch(persistentCtx, &pbzk.AddWatchRequest{Path: "/app", Mode: pbzk.AddWatchRequest_MODE_PERSISTENT})
+	s.Require().NoError(err)
+	_, err = s.ZK.AddWatch(recursiveCtx, &pbzk.AddWatchRequest{Path: "/", Mode: pbzk.AddWatchRequest_MODE_PERSISTENT_RECURSIVE})
+	s.Require().NoError(err)
+
+	ctx := context.Background()
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/app"})
+	s.Require().NoError(err)
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/app/child"})
+	s.Require().NoError(err)
+	_, err = s.ZK.SetData(ctx, &pbzk.SetDataRequest{Path: "/app/child", Data: []byte("data"), Version: -1})
+	s.Require().NoError(err)
+	_, err = s.ZK.SetData(ctx, &pbzk.SetDataRequest{Path: "/app", Data: []byte("data"), Version: -1})
+	s.Require().NoError(err)
+
+	// Events are queued in the order the changes were made.
+	s.Assert().Equal([]*pbzk.WatchEvent{
+		{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_CREATED, Path: "/app"},
+		{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_CHILDREN_CHANGED, Path: "/app"},
+		{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_DATA_CHANGED, Path: "/app"},
+	}, watchEvents(persistent))
+	s.Assert().Equal([]*pbzk.WatchEvent{
+		{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_CREATED, Path: "/app"},
+		{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_CREATED, Path: "/app/child"},
+		{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_DATA_CHANGED, Path: "/app/child"},
+		{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_DATA_CHANGED, Path: "/app"},
+	}, watchEvents(recursive))
+
+	// Closing the session removes its watches.
+	s.ZK.closeSession(recursiveID)
+	s.Assert().Empty(s.ZK.watches.Watches(recursiveID))
+	s.Assert().Equal(znode.WatchStats{
+		Sessions: 1,
+		Paths:    1,
+		Watches:  1,
+		ByKind:   map[znode.WatchKind]int{znode.WatchKind_PERSISTENT: 1},
+	}, s.ZK.WatchStats())
+}
+
+// TestServer_SlowConsumer verifies that watch events are dropped once a client stops reading its messages,
+// and that the client gets a fresh outbox when it reconnects.
+func (s *serverTestSuite) TestServer_SlowConsumer() {
+	s.ZK.db = znode.NewDB()
+	sess, _, err := s.ZK.attachSession("", "", 0)
+	s.Require().NoError(err)
+	ctx := utils.SetIncomingClientIDHeader(context.Background(), sess.ID)
+	_, err = s.ZK.AddWatch(ctx, &pbzk.AddWatchRequest{Path: "/", Mode: pbzk.AddWatchRequest_MODE_PERSISTENT_RECURSIVE})
+	s.Require().NoError(err)
+
+	for i := range session.OutboxCapacity + 1 {
+		_, err = s.ZK.Create(context.Background(), &pbzk.CreateRequest{Path: fmt.Sprintf("/node%d", i)})
+		s.Require().NoError(err)
+	}
+	s.Assert().Equal(session.OutboxCapacity, sess.Outbox.Len())
+	s.Assert().NotPanics(func() { <-sess.Outbox.Overflowed() })
+
+	_, _, err = s.ZK.attachSession(sess.ID, sess.Password, 0)
+	s.Require().NoError(err)
+	s.Assert().Zero(sess.Outbox.Len())
+	_, err = s.ZK.Create(context.Background(), &pbzk.CreateRequest{Path: "/next"})
+	s.Require().NoError(err)
+	s.Assert().Equal([]*pbzk.WatchEvent{
+		{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_CREATED, Path: "/next"},
+	}, watchEvents(sess))
+}
+
+// TestServer_SetWatches verifies that a reconnecting client gets its watches back, and gets the events it
+// missed while it was away.
+func (s *serverTestSuite) TestServer_SetWatches() {
+	s.ZK.db = znode.NewDB()
+	ctx := context.Background()
+	for _, path := range []string{"/same", "/changed", "/deleted", "/parent"} {
+		_, err := s.ZK.Create(ctx, &pbzk.CreateRequest{Path: path})
+		s.Require().NoError(err)
+	}
+	_, err := s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/secret", Acl: []*pbzk.ACL{{
+		Perms: int32(pbzk.Permission_PERMISSION_ALL),
+		Id:    &pbzk.Id{Scheme: "digest", Id: "someone:else"},
+	}}})
+	s.Require().NoError(err)
+	lastZxid := s.ZK.lastZxid.Load()
+
+	_, err = s.ZK.SetData(ctx, &pbzk.SetDataRequest{Path: "/changed", Data: []byte("data"), Version: -1})
+	s.Require().NoError(err)
+	_, err = s.ZK.Delete(ctx, &pbzk.DeleteRequest{Path: "/deleted", Version: -1})
+	s.Require().NoError(err)
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/created"})
+	s.Require().NoError(err)
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/parent/child"})
+	s.Require().NoError(err)
+
+	sess, _, err := s.ZK.attachSession("", "", 0)
+	s.Require().NoError(err)
+	clientCtx := utils.SetIncomingClientIDHeader(ctx, sess.ID)
+	err = s.ZK.setWatches(clientCtx, &pbzk.ConnectRequest{
+		LastZxid:                   lastZxid,
+		DataWatches:                []string{"/same", "/changed", "/deleted", "/secret"},
+		ExistWatches:               []string{"/created", "/missing"},
+		ChildWatches:               []string{"/same", "/parent"},
+		PersistentWatches:          []string{"/changed"},
+		PersistentRecursiveWatches: []string{"/"},
+	})
+	s.Require().NoError(err)
+
+	s.Assert().Equal([]*pbzk.WatchEvent{
+		{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_DATA_CHANGED, Path: "/changed"},
+		{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_DELETED, Path: "/deleted"},
+		{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_CREATED, Path: "/created"},
+		{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_CHILDREN_CHANGED, Path: "/parent"},
+	}, watchEvents(sess))
+	// The watches that fired aren't set, and the client can't watch what it can't read.
+	s.Assert().Equal([]znode.Watch{
+		{ClientID: sess.ID, Path: "", Kind: znode.WatchKind_PERSISTENT_RECURSIVE},
+		{ClientID: sess.ID, Path: "/changed", Kind: znode.WatchKind_PERSISTENT},
+		{ClientID: sess.ID, Path: "/missing", Kind: znode.WatchKind_DATA},
+		{ClientID: sess.ID, Path: "/same", Kind: znode.WatchKind_DATA},
+		{ClientID: sess.ID, Path: "/same", Kind: znode.WatchKind_CHILDREN},
+	}, s.ZK.watches.Watches(sess.ID))
+
+	err = s.ZK.setWatches(clientCtx, &pbzk.ConnectRequest{DataWatches: []string{"invalid"}})
+	s.Assert().Error(err)
+}
+
+// TestServer_RemoveWatches verifies that clients can remove each type of watch they've set.
+func (s *serverTestSuite) TestServer_RemoveWatches() {
+	s.ZK.db = znode.NewDB()
+	const clientID, otherID = "client", "other"
+	ctx := utils.SetIncomingClientIDHeader(context.Background(), clientID)
+	otherCtx := utils.SetIncomingClientIDHeader(context.Background(), otherID)
+	_, err := s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/zoo"})
+	s.Require().NoError(err)
+
+	setWatches := func() {
+		_, err := s.ZK.GetData(ctx, &pbzk.GetDataRequest{Path: "/zoo", Watch: true})
+		s.Require().NoError(err)
+		_, err = s.ZK.GetChildren(ctx, &pbzk.GetChildrenRequest{Path: "/zoo", Watch: true})
+		s.Require().NoError(err)
+		_, err = s.ZK.AddWatch(ctx, &pbzk.AddWatchRequest{Path: "/zoo", Mode: pbzk.AddWatchRequest_MODE_PERSISTENT})
+		s.Require().NoError(err)
+		_, err = s.ZK.AddWatch(ctx, &pbzk.AddWatchRequest{Path: "/zoo", Mode: pbzk.AddWatchRequest_MODE_PERSISTENT_RECURSIVE})
+		s.Require().NoError(err)
+	}
+	tests := []struct {
+		name          string
+		watcherType   pbzk.RemoveWatchesRequest_WatcherType
+		remaining     []znode.WatchKind
+		errorExpected bool
+	}{
+		{
+			name:          "unset type",
+			watcherType:   pbzk.RemoveWatchesRequest_WATCHER_TYPE_UNSET,
+			errorExpected: true,
+		},
+		{
+			name:        "data",
+			watcherType: pbzk.RemoveWatchesRequest_WATCHER_TYPE_DATA,
+			remaining:   []znode.WatchKind{znode.WatchKind_CHILDREN, znode.WatchKind_PERSISTENT, znode.WatchKind_PERSISTENT_RECURSIVE},
+		},
+		{
+			name:        "children",
+			watcherType: pbzk.RemoveWatchesRequest_WATCHER_TYPE_CHILDREN,
+			remaining:   []znode.WatchKind{znode.WatchKind_DATA, znode.WatchKind_PERSISTENT, znode.WatchKind_PERSISTENT_RECURSIVE},
+		},
+		{
+			name:        "persistent",
+			watcherType: pbzk.RemoveWatchesRequest_WATCHER_TYPE_PERSISTENT,
+			remaining:   []znode.WatchKind{znode.WatchKind_DATA, znode.WatchKind_CHILDREN, znode.WatchKind_PERSISTENT_RECURSIVE},
+		},
+		{
+			name:        "persistent recursive",
+			watcherType: pbzk.RemoveWatchesRequest_WATCHER_TYPE_PERSISTENT_RECURSIVE,
+			remaining:   []znode.WatchKind{znode.WatchKind_DATA, znode.WatchKind_CHILDREN, znode.WatchKind_PERSISTENT},
+		},
+		{
+			name:        "any",
+			watcherType: pbzk.RemoveWatchesRequest_WATCHER_TYPE_ANY,
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			s.ZK.watches = znode.NewWatchManager()
+			setWatches()
+			// Another client's watch is never removed.
+			_, err := s.ZK.GetData(otherCtx, &pbzk.GetDataRequest{Path: "/zoo", Watch: true})
+			s.Require().NoError(err)
+
+			_, err = s.ZK.RemoveWatches(ctx, &pbzk.RemoveWatchesRequest{Path: "/zoo", Type: test.watcherType})
+			if test.errorExpected {
+				s.Assert().Error(err)
+				return
+			}
+			s.Require().NoError(err)
+			var remaining []znode.WatchKind
+			for _, w := range s.ZK.watches.Watches(clientID) {
+				remaining = append(remaining, w.Kind)
+			}
+			s.Assert().Equal(test.remaining, remaining)
+			s.Assert().Equal(len(test.remaining)+1, s.ZK.WatchStats().Watches)
+		})
+	}
+}
+
+// TestServer_AttachSession verifies that clients can only resume sessions they own.
+func (s *serverTestSuite) TestServer_AttachSession() {
+	sess, superseded, err := s.ZK.attachSession("", "", 0)
+	s.Require().NoError(err)
+
+	tests := []struct {
+		name          string
+		sessionID     string
+		password      string
+		errorExpected bool
+	}{
+		{
+			name:          "wrong password",
+			sessionID:     sess.ID,
+			password:      "wrong",
+			errorExpected: true,
+		},
+		{
+			name:          "session doesn't exist",
+			sessionID:     "other",
+			password:      sess.Password,
+			errorExpected: true,
+		},
+		{
+			name:      "resume session",
+			sessionID: sess.ID,
+			password:  sess.Password,
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			resumed, _, err := s.ZK.attachSession(test.sessionID, test.password, 0)
+			if test.errorExpected {
+				s.Assert().Error(err)
+				return
+			}
+			s.Require().NoError(err)
+			s.Assert().Equal(sess, resumed)
+			// The old connection should have been told to stop.
+			s.Assert().NotPanics(func() { <-superseded })
+		})
+	}
+}
+
+// TestServer_DetachSession verifies that we keep the session around after the connection goes away.
+func (s *serverTestSuite) TestServer_DetachSession() {
+	sess, superseded, err := s.ZK.attachSession("", "", 0)
+	s.Require().NoError(err)
+
+	s.ZK.detachSession(sess.ID, superseded)
+	s.Assert().Nil(sess.Superseded)
+	s.Assert().Contains(s.ZK.sessions, sess.ID)
+
+	// Detaching a connection that was already replaced does nothing.
+	_, resumed, err := s.ZK.attachSession(sess.ID, sess.Password, 0)
+	s.Require().NoError(err)
+	s.ZK.detachSession(sess.ID, superseded)
+	s.Assert().Equal(resumed, sess.Superseded)
+}
+
+// TestServer_NegotiateSessionTimeout verifies that session timeouts are kept within the range we allow.
+func (s *serverTestSuite) TestServer_NegotiateSessionTimeout() {
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		expected time.Duration
+	}{
+		{
+			name:     "too short",
+			timeout:  time.Millisecond,
+			expected: config.DefaultMinSessionTimeoutTicks * config.DefaultTickTime,
+		},
+		{
+			name:     "within range",
+			timeout:  10 * time.Second,
+			expected: 10 * time.Second,
+		},
+		{
+			name:     "too long",
+			timeout:  time.Hour,
+			expected: config.DefaultMaxSessionTimeoutTicks * config.DefaultTickTime,
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			s.Assert().Equal(test.expected, s.ZK.negotiateSessionTimeout(test.timeout))
+		})
+	}
+}
+
+// TestServer_ExpireSession verifies that expiring a session deletes its ephemeral nodes and notifies
+// anyone watching them.
+func (s *serverTestSuite) TestServer_ExpireSession() {
+	sess, _, err := s.ZK.attachSession("", "", 0)
+	s.Require().NoError(err)
+	watcher, _, err := s.ZK.attachSession("", "", 0)
+	s.Require().NoError(err)
+	s.ZK.watches.Add(watcher.ID, "/zoo", znode.WatchKind_DATA)
+
+	s.MockDB.EXPECT().CloseSession(gomock.Any()).DoAndReturn(func(txn *pbzk.Transaction) ([]string, error) {
+		s.Assert().Equal(sess.ID, txn.GetClientId())
+		s.Assert().NotNil(txn.GetCloseSession())
+		return []string{"/zoo"}, nil
+	})
+	s.ZK.expireSession(sess.ID)
+
+	s.Assert().NotContains(s.ZK.sessions, sess.ID)
+	s.Assert().NotPanics(func() { <-sess.Closed })
+	s.Assert().Equal([]*pbzk.WatchEvent{
+		{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_DELETED, Path: "/zoo"},
+	}, watchEvents(watcher))
+
+	// Expiring the session again does nothing.
+	s.ZK.expireSession(sess.ID)
+}
+
+// pausingDB pauses after applying a setData until the test lets it continue.
+type pausingDB struct {
+	znode.ZKDB
+	applied chan struct{}
+	resume  chan struct{}
+}
+
+func (d *pausingDB) SetData(txn *pbzk.Transaction) error {
+	err := d.ZKDB.SetData(txn)
+	d.applied <- struct{}{}
+	<-d.resume
+	return err
+}
+
+// TestServer_EventsBeforeReads verifies that no client can read a change until the watch events for it are
+// queued, so a client never sees new data before the event.
+func (s *serverTestSuite) TestServer_EventsBeforeReads() {
+	db := &pausingDB{ZKDB: znode.NewDB(), applied: make(chan struct{}), resume: make(chan struct{})}
+	s.ZK.db = db
+	ctx := context.Background()
+	_, err := s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/node"})
+	s.Require().NoError(err)
+	sess, _, err := s.ZK.attachSession("", "", 0)
+	s.Require().NoError(err)
+	clientCtx := utils.SetIncomingClientIDHeader(ctx, sess.ID)
+	_, err = s.ZK.GetData(clientCtx, &pbzk.GetDataRequest{Path: "/node", Watch: true})
+	s.Require().NoError(err)
+
+	go func() {
+		_, err := s.ZK.SetData(ctx, &pbzk.SetDataRequest{Path: "/node", Data: []byte("data"), Version: -1})
+		s.Assert().NoError(err)
+	}()
+	// The change is in the tree, but the watch hasn't fired yet.
+	<-db.applied
+	reads := make(chan *pbzk.GetDataResponse)
+	go func() {
+		resp, err := s.ZK.GetData(clientCtx, &pbzk.GetDataRequest{Path: "/node"})
+		s.Assert().NoError(err)
+		reads <- resp
+	}()
+	// Give the read a chance to get ahead of the watch.
+	time.Sleep(20 * time.Millisecond)
+	close(db.resume)
+
+	resp := <-reads
+	s.Assert().Equal([]byte("data"), resp.GetData())
+	s.Assert().Equal([]*pbzk.WatchEvent{
+		{Type: pbzk.WatchEvent_EVENT_TYPE_ZNODE_DATA_CHANGED, Path: "/node"},
+	}, watchEvents(sess))
+}
+
+// TestServer_FailedTxnKeepsZxid verifies that a transaction that fails to apply doesn't use up a zxid.
+func (s *serverTestSuite) TestServer_FailedTxnKeepsZxid() {
+	s.ZK.db = znode.NewDB()
+	ctx := context.Background()
+	_, err := s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/missing/child"})
+	s.Require().Error(err)
+	s.Assert().Zero(s.ZK.lastZxid.Load())
+
+	_, err = s.ZK.Create(ctx, &pbzk.CreateRequest{Path: "/node"})
+	s.Require().NoError(err)
+	s.Assert().EqualValues(1, s.ZK.lastZxid.Load())
+	s.Assert().EqualValues(1, s.ZK.db.Get("/node").Czxid)
+}
+
+// watchEvents removes the queued watch events for the session.
+func watchEvents(sess *session.Session) []*pbzk.WatchEvent {
+	var events []*pbzk.WatchEvent
+	for _, msg := range sess.Outbox.Drain() {
+		if event := msg.GetWatchEvent(); event != nil {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// TestRecoverServer verifies that a server recovers its tree from the log, and that the ephemeral nodes
+// of sessions that didn't survive the restart are deleted.
+func TestRecoverServer(t *testing.T) {
+	cfg := config.Default()
+	require.NoError(t, cfg.Validate())
+	cfg.DataLogDir = t.TempDir() + "/log"
+
+	zk, err := RecoverServer(cfg)
+	require.NoError(t, err)
+	sess := session.NewSession(config.DefaultTickTime)
+	zk.sessions[sess.ID] = sess
+	ctx := utils.SetIncomingClientIDHeader(context.Background(), sess.ID)
+	_, err = zk.Create(ctx, &pbzk.CreateRequest{Path: "/zoo", Data: []byte("animals")})
+	require.NoError(t, err)
+	_, err = zk.Create(ctx, &pbzk.CreateRequest{Path: "/zoo/giraffe", Mode: pbzk.CreateRequest_MODE_EPHEMERAL})
+	require.NoError(t, err)
+	_, err = zk.SetData(ctx, &pbzk.SetDataRequest{Path: "/zoo", Data: []byte("giraffes"), Version: -1})
+	require.NoError(t, err)
+	zk.Close()
+
+	zk, err = RecoverServer(cfg)
+	require.NoError(t, err)
+	node := zk.db.Get("/zoo")
+	require.NotNil(t, node)
+	assert.Equal(t, []byte("giraffes"), node.Data)
+	assert.Nil(t, zk.db.Get("/zoo/giraffe"))
+	// Closing the old session used up the next zxid.
+	assert.Equal(t, int64(4), zk.lastZxid.Load())
+
+	// New transactions are logged after the ones we replayed.
+	_, err = zk.Create(context.Background(), &pbzk.CreateRequest{Path: "/zoo/lion"})
+	require.NoError(t, err)
+	zk.Close()
+	zk, err = RecoverServer(cfg)
+	require.NoError(t, err)
+	defer zk.Close()
+	assert.NotNil(t, zk.db.Get("/zoo/lion"))
+}
+
 func TestServerTestSuite(t *testing.T) {
 	suite.Run(t, new(serverTestSuite))
 }
@@ -2,7 +2,11 @@ package server
 
 import (
 	"fmt"
+	"slices"
 	"strings"
+
+	"github.com/mikekulinski/zookeeper/pkg/znode"
+	pbzk "github.com/mikekulinski/zookeeper/proto"
 )
 
 // validatePath verifies that the path received from the client is valid.
@@ -29,6 +33,103 @@ func validatePath(path string) error {
 	return nil
 }
 
+// validateWatchPath verifies the path of a watch, and returns the path the watch is stored under. Watching
+// the whole tree is allowed, so the root is the one path we accept that validatePath doesn't.
+func validateWatchPath(path string) (string, error) {
+	if path == "/" {
+		// The root is stored under the empty path, the same as in the tree.
+		return "", nil
+	}
+	err := validatePath(path)
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// validateNotReserved makes sure clients aren't directly modifying the nodes Zookeeper uses to
+// store its own metadata.
+func validateNotReserved(path string) error {
+	if znode.IsReserved(path) {
+		return fmt.Errorf("path [%s] is reserved and can only be modified by the server", path)
+	}
+	return nil
+}
+
+// maxTTL is the longest TTL a node can have, the same as Zookeeper.
+const maxTTL = 1<<40 - 1
+
+// createMode returns the type of node the create request is for, and makes sure the rest of the request
+// fits it. Requests without a mode are read from the deprecated flags instead.
+func createMode(req *pbzk.CreateRequest) (pbzk.CreateRequest_CreateMode, error) {
+	mode := req.GetMode()
+	if mode == pbzk.CreateRequest_MODE_UNSET {
+		var err error
+		mode, err = createModeFromFlags(req.GetFlags())
+		if err != nil {
+			return mode, err
+		}
+	} else if len(req.GetFlags()) > 0 {
+		return mode, fmt.Errorf("flags cannot be set along with the mode [%s]", mode)
+	}
+
+	switch mode {
+	case pbzk.CreateRequest_MODE_PERSISTENT_WITH_TTL, pbzk.CreateRequest_MODE_PERSISTENT_SEQUENTIAL_WITH_TTL:
+		if req.GetTtlMs() <= 0 || req.GetTtlMs() > maxTTL {
+			return mode, fmt.Errorf("invalid ttl [%d]: must be between 1 and %d milliseconds", req.GetTtlMs(), int64(maxTTL))
+		}
+	case pbzk.CreateRequest_MODE_PERSISTENT, pbzk.CreateRequest_MODE_EPHEMERAL, pbzk.CreateRequest_MODE_PERSISTENT_SEQUENTIAL,
+		pbzk.CreateRequest_MODE_EPHEMERAL_SEQUENTIAL, pbzk.CreateRequest_MODE_CONTAINER:
+		if req.GetTtlMs() != 0 {
+			return mode, fmt.Errorf("ttl can only be set on TTL nodes")
+		}
+	default:
+		return mode, fmt.Errorf("invalid create mode [%s]", mode)
+	}
+	return mode, nil
+}
+
+// createModeFromFlags returns the mode that the deprecated flags stand for, or an error if they can't be used
+// together.
+func createModeFromFlags(flags []pbzk.CreateRequest_Flag) (pbzk.CreateRequest_CreateMode, error) {
+	ephemeral := slices.Contains(flags, pbzk.CreateRequest_FLAG_EPHEMERAL)
+	sequential := slices.Contains(flags, pbzk.CreateRequest_FLAG_SEQUENTIAL)
+	ttl := slices.Contains(flags, pbzk.CreateRequest_FLAG_TTL)
+	container := slices.Contains(flags, pbzk.CreateRequest_FLAG_CONTAINER)
+	switch {
+	case container && len(flags) > 1:
+		return pbzk.CreateRequest_MODE_UNSET, fmt.Errorf("container nodes cannot have any other flags")
+	case container:
+		return pbzk.CreateRequest_MODE_CONTAINER, nil
+	case ttl && ephemeral:
+		return pbzk.CreateRequest_MODE_UNSET, fmt.Errorf("ephemeral nodes cannot have a TTL")
+	case ttl && sequential:
+		return pbzk.CreateRequest_MODE_PERSISTENT_SEQUENTIAL_WITH_TTL, nil
+	case ttl:
+		return pbzk.CreateRequest_MODE_PERSISTENT_WITH_TTL, nil
+	case ephemeral && sequential:
+		return pbzk.CreateRequest_MODE_EPHEMERAL_SEQUENTIAL, nil
+	case ephemeral:
+		return pbzk.CreateRequest_MODE_EPHEMERAL, nil
+	case sequential:
+		return pbzk.CreateRequest_MODE_PERSISTENT_SEQUENTIAL, nil
+	default:
+		return pbzk.CreateRequest_MODE_PERSISTENT, nil
+	}
+}
+
+// isEphemeralMode returns whether the mode creates nodes that are deleted along with their session.
+func isEphemeralMode(mode pbzk.CreateRequest_CreateMode) bool {
+	return mode == pbzk.CreateRequest_MODE_EPHEMERAL || mode == pbzk.CreateRequest_MODE_EPHEMERAL_SEQUENTIAL
+}
+
+// isSequentialMode returns whether the mode appends a sequence number to the name of the node.
+func isSequentialMode(mode pbzk.CreateRequest_CreateMode) bool {
+	return mode == pbzk.CreateRequest_MODE_PERSISTENT_SEQUENTIAL ||
+		mode == pbzk.CreateRequest_MODE_EPHEMERAL_SEQUENTIAL ||
+		mode == pbzk.CreateRequest_MODE_PERSISTENT_SEQUENTIAL_WITH_TTL
+}
+
 // isValidVersion is used for conditional checks for update/delete operations. If the passed in version
 // is -1, then skip the version check. Otherwise, make sure the versions are equal.
 func isValidVersion(expected, actual int64) bool {
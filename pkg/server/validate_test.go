@@ -3,6 +3,7 @@ package server
 import (
 	"testing"
 
+	pbzk "github.com/mikekulinski/zookeeper/proto"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -64,3 +65,202 @@ func TestValidatePath(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateNotReserved(t *testing.T) {
+	tests := []struct {
+		name          string
+		path          string
+		errorExpected bool
+	}{
+		{
+			name:          "zookeeper root",
+			path:          "/zookeeper",
+			errorExpected: true,
+		},
+		{
+			name:          "config",
+			path:          "/zookeeper/config",
+			errorExpected: true,
+		},
+		{
+			name:          "quota root",
+			path:          "/zookeeper/quota",
+			errorExpected: true,
+		},
+		{
+			name:          "quota stats",
+			path:          "/zookeeper/quota/x/zookeeper_stats",
+			errorExpected: true,
+		},
+		{
+			name:          "quota limits",
+			path:          "/zookeeper/quota/x/zookeeper_limits",
+			errorExpected: false,
+		},
+		{
+			name:          "other node under zookeeper",
+			path:          "/zookeeper/other",
+			errorExpected: false,
+		},
+		{
+			name:          "regular node",
+			path:          "/x/config",
+			errorExpected: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateNotReserved(test.path)
+			if test.errorExpected {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCreateMode(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           *pbzk.CreateRequest
+		expected      pbzk.CreateRequest_CreateMode
+		errorExpected bool
+	}{
+		{
+			name:     "no mode or flags",
+			req:      &pbzk.CreateRequest{},
+			expected: pbzk.CreateRequest_MODE_PERSISTENT,
+		},
+		{
+			name:     "persistent",
+			req:      &pbzk.CreateRequest{Mode: pbzk.CreateRequest_MODE_PERSISTENT},
+			expected: pbzk.CreateRequest_MODE_PERSISTENT,
+		},
+		{
+			name:     "ephemeral",
+			req:      &pbzk.CreateRequest{Mode: pbzk.CreateRequest_MODE_EPHEMERAL},
+			expected: pbzk.CreateRequest_MODE_EPHEMERAL,
+		},
+		{
+			name:     "persistent sequential",
+			req:      &pbzk.CreateRequest{Mode: pbzk.CreateRequest_MODE_PERSISTENT_SEQUENTIAL},
+			expected: pbzk.CreateRequest_MODE_PERSISTENT_SEQUENTIAL,
+		},
+		{
+			name:     "ephemeral sequential",
+			req:      &pbzk.CreateRequest{Mode: pbzk.CreateRequest_MODE_EPHEMERAL_SEQUENTIAL},
+			expected: pbzk.CreateRequest_MODE_EPHEMERAL_SEQUENTIAL,
+		},
+		{
+			name:     "container",
+			req:      &pbzk.CreateRequest{Mode: pbzk.CreateRequest_MODE_CONTAINER},
+			expected: pbzk.CreateRequest_MODE_CONTAINER,
+		},
+		{
+			name:     "TTL",
+			req:      &pbzk.CreateRequest{Mode: pbzk.CreateRequest_MODE_PERSISTENT_WITH_TTL, TtlMs: 1000},
+			expected: pbzk.CreateRequest_MODE_PERSISTENT_WITH_TTL,
+		},
+		{
+			name:     "sequential TTL",
+			req:      &pbzk.CreateRequest{Mode: pbzk.CreateRequest_MODE_PERSISTENT_SEQUENTIAL_WITH_TTL, TtlMs: 1000},
+			expected: pbzk.CreateRequest_MODE_PERSISTENT_SEQUENTIAL_WITH_TTL,
+		},
+		{
+			name:          "TTL without a ttl",
+			req:           &pbzk.CreateRequest{Mode: pbzk.CreateRequest_MODE_PERSISTENT_WITH_TTL},
+			errorExpected: true,
+		},
+		{
+			name:          "TTL too long",
+			req:           &pbzk.CreateRequest{Mode: pbzk.CreateRequest_MODE_PERSISTENT_WITH_TTL, TtlMs: maxTTL + 1},
+			errorExpected: true,
+		},
+		{
+			name:          "ttl without a TTL mode",
+			req:           &pbzk.CreateRequest{Mode: pbzk.CreateRequest_MODE_EPHEMERAL, TtlMs: 1000},
+			errorExpected: true,
+		},
+		{
+			name:          "unknown mode",
+			req:           &pbzk.CreateRequest{Mode: pbzk.CreateRequest_CreateMode(100)},
+			errorExpected: true,
+		},
+		{
+			name: "mode and flags",
+			req: &pbzk.CreateRequest{
+				Mode:  pbzk.CreateRequest_MODE_PERSISTENT,
+				Flags: []pbzk.CreateRequest_Flag{pbzk.CreateRequest_FLAG_EPHEMERAL},
+			},
+			errorExpected: true,
+		},
+		// The deprecated flags are still read when the mode is unset.
+		{
+			name:     "ephemeral flag",
+			req:      &pbzk.CreateRequest{Flags: []pbzk.CreateRequest_Flag{pbzk.CreateRequest_FLAG_EPHEMERAL}},
+			expected: pbzk.CreateRequest_MODE_EPHEMERAL,
+		},
+		{
+			name:     "sequential flag",
+			req:      &pbzk.CreateRequest{Flags: []pbzk.CreateRequest_Flag{pbzk.CreateRequest_FLAG_SEQUENTIAL}},
+			expected: pbzk.CreateRequest_MODE_PERSISTENT_SEQUENTIAL,
+		},
+		{
+			name:     "ephemeral and sequential flags",
+			req:      &pbzk.CreateRequest{Flags: []pbzk.CreateRequest_Flag{pbzk.CreateRequest_FLAG_EPHEMERAL, pbzk.CreateRequest_FLAG_SEQUENTIAL}},
+			expected: pbzk.CreateRequest_MODE_EPHEMERAL_SEQUENTIAL,
+		},
+		{
+			name:     "TTL flag",
+			req:      &pbzk.CreateRequest{Flags: []pbzk.CreateRequest_Flag{pbzk.CreateRequest_FLAG_TTL}, TtlMs: 1000},
+			expected: pbzk.CreateRequest_MODE_PERSISTENT_WITH_TTL,
+		},
+		{
+			name:     "TTL and sequential flags",
+			req:      &pbzk.CreateRequest{Flags: []pbzk.CreateRequest_Flag{pbzk.CreateRequest_FLAG_TTL, pbzk.CreateRequest_FLAG_SEQUENTIAL}, TtlMs: 1000},
+			expected: pbzk.CreateRequest_MODE_PERSISTENT_SEQUENTIAL_WITH_TTL,
+		},
+		{
+			name:          "TTL flag without a ttl",
+			req:           &pbzk.CreateRequest{Flags: []pbzk.CreateRequest_Flag{pbzk.CreateRequest_FLAG_TTL}},
+			errorExpected: true,
+		},
+		{
+			name:          "ttl without the TTL flag",
+			req:           &pbzk.CreateRequest{TtlMs: 1000},
+			errorExpected: true,
+		},
+		{
+			name:     "container flag",
+			req:      &pbzk.CreateRequest{Flags: []pbzk.CreateRequest_Flag{pbzk.CreateRequest_FLAG_CONTAINER}},
+			expected: pbzk.CreateRequest_MODE_CONTAINER,
+		},
+		{
+			name:          "container and sequential flags",
+			req:           &pbzk.CreateRequest{Flags: []pbzk.CreateRequest_Flag{pbzk.CreateRequest_FLAG_CONTAINER, pbzk.CreateRequest_FLAG_SEQUENTIAL}},
+			errorExpected: true,
+		},
+		{
+			name:          "container flag with a ttl",
+			req:           &pbzk.CreateRequest{Flags: []pbzk.CreateRequest_Flag{pbzk.CreateRequest_FLAG_CONTAINER}, TtlMs: 1000},
+			errorExpected: true,
+		},
+		{
+			name:          "TTL and ephemeral flags",
+			req:           &pbzk.CreateRequest{Flags: []pbzk.CreateRequest_Flag{pbzk.CreateRequest_FLAG_TTL, pbzk.CreateRequest_FLAG_EPHEMERAL}, TtlMs: 1000},
+			errorExpected: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mode, err := createMode(test.req)
+			if test.errorExpected {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, mode)
+			}
+		})
+	}
+}
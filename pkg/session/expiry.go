@@ -0,0 +1,112 @@
+package session
+
+import (
+	"sync"
+	"time"
+)
+
+// ExpiryQueue tracks when elements expire. Instead of keeping a timer for each element, we round each
+// expiration up to the next multiple of the interval, and group the elements into buckets that all
+// expire at the same time. That way we only have to wake up once per interval to expire a whole
+// bucket at once, no matter how many elements there are. This is the same approach as Zookeeper's ExpiryQueue.
+type ExpiryQueue[E comparable] struct {
+	mu       *sync.Mutex
+	interval time.Duration
+	// expirations maps each element to the bucket it is in, keyed by the bucket's expiration time in ms.
+	expirations map[E]int64
+	// buckets maps each expiration time in ms to the elements that expire then.
+	buckets map[int64]map[E]struct{}
+	// nextExpiration is the expiration time of the next bucket we'll expire.
+	nextExpiration int64
+	// now returns the current time. This is swapped out in tests.
+	now func() time.Time
+}
+
+func NewExpiryQueue[E comparable](interval time.Duration) *ExpiryQueue[E] {
+	q := &ExpiryQueue[E]{
+		mu:          &sync.Mutex{},
+		interval:    interval,
+		expirations: map[E]int64{},
+		buckets:     map[int64]map[E]struct{}{},
+		now:         time.Now,
+	}
+	q.nextExpiration = q.roundToNextInterval(q.now().UnixMilli())
+	return q
+}
+
+// roundToNextInterval returns the expiration time of the bucket that t falls into.
+func (q *ExpiryQueue[E]) roundToNextInterval(t int64) int64 {
+	interval := q.interval.Milliseconds()
+	return (t/interval + 1) * interval
+}
+
+// Update moves the element to the bucket it falls into if it expires timeout from now. It returns
+// the new expiration time.
+func (q *ExpiryQueue[E]) Update(e E, timeout time.Duration) time.Time {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	expiration := q.roundToNextInterval(q.now().Add(timeout).UnixMilli())
+	if prev, ok := q.expirations[e]; ok {
+		if prev == expiration {
+			// Nothing to do since it is already in the right bucket.
+			return time.UnixMilli(expiration)
+		}
+		q.removeLocked(e, prev)
+	}
+
+	bucket, ok := q.buckets[expiration]
+	if !ok {
+		bucket = map[E]struct{}{}
+		q.buckets[expiration] = bucket
+	}
+	bucket[e] = struct{}{}
+	q.expirations[e] = expiration
+	return time.UnixMilli(expiration)
+}
+
+// Remove stops tracking the element.
+func (q *ExpiryQueue[E]) Remove(e E) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if expiration, ok := q.expirations[e]; ok {
+		q.removeLocked(e, expiration)
+	}
+}
+
+func (q *ExpiryQueue[E]) removeLocked(e E, expiration int64) {
+	delete(q.expirations, e)
+	delete(q.buckets[expiration], e)
+	if len(q.buckets[expiration]) == 0 {
+		delete(q.buckets, expiration)
+	}
+}
+
+// Poll returns the elements in the next bucket if it has expired, and stops tracking them. It returns
+// nil if the next bucket hasn't expired yet. Callers should wait WaitTime before polling again.
+func (q *ExpiryQueue[E]) Poll() []E {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.now().UnixMilli() < q.nextExpiration {
+		return nil
+	}
+	expiration := q.nextExpiration
+	q.nextExpiration += q.interval.Milliseconds()
+
+	var expired []E
+	for e := range q.buckets[expiration] {
+		expired = append(expired, e)
+		delete(q.expirations, e)
+	}
+	delete(q.buckets, expiration)
+	return expired
+}
+
+// WaitTime returns how long until the next bucket expires.
+func (q *ExpiryQueue[E]) WaitTime() time.Duration {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return max(time.UnixMilli(q.nextExpiration).Sub(q.now()), 0)
+}
@@ -0,0 +1,43 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestExpiryQueue verifies that elements expire in buckets, and that updating an element pushes
+// back when it expires.
+func TestExpiryQueue(t *testing.T) {
+	now := time.UnixMilli(1000)
+	q := NewExpiryQueue[string](100 * time.Millisecond)
+	q.now = func() time.Time { return now }
+	q.nextExpiration = q.roundToNextInterval(now.UnixMilli())
+
+	// Both of these round up to the bucket at 1300ms.
+	assert.Equal(t, time.UnixMilli(1300), q.Update("a", 250*time.Millisecond))
+	assert.Equal(t, time.UnixMilli(1300), q.Update("b", 299*time.Millisecond))
+	assert.Equal(t, time.UnixMilli(1400), q.Update("c", 300*time.Millisecond))
+	assert.Equal(t, 100*time.Millisecond, q.WaitTime())
+
+	// Nothing has expired yet.
+	assert.Empty(t, q.Poll())
+
+	// Update pushes back b, and Remove stops tracking c.
+	now = time.UnixMilli(1050)
+	assert.Equal(t, time.UnixMilli(1400), q.Update("b", 300*time.Millisecond))
+	q.Remove("c")
+
+	expired := map[int64][]string{}
+	for _, ms := range []int64{1100, 1200, 1300, 1400} {
+		now = time.UnixMilli(ms)
+		expired[ms] = q.Poll()
+	}
+	assert.Empty(t, expired[1100])
+	assert.Empty(t, expired[1200])
+	assert.Equal(t, []string{"a"}, expired[1300])
+	assert.Equal(t, []string{"b"}, expired[1400])
+	assert.Empty(t, q.expirations)
+	assert.Empty(t, q.buckets)
+}
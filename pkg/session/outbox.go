@@ -0,0 +1,107 @@
+package session
+
+import (
+	"sync"
+
+	pbzk "github.com/mikekulinski/zookeeper/proto"
+)
+
+// Outbox is the queue of messages waiting to be sent to the client, both responses and watch events.
+// Messages are kept in zxid order, so the client always sees a watch event before any response that
+// reflects the change that triggered it. The outbox is bounded. Once a client falls too far behind,
+// the outbox overflows and the connection is dropped instead of queuing messages forever.
+type Outbox struct {
+	mu       *sync.Mutex
+	capacity int
+	messages []*pbzk.ZookeeperResponse
+	// ready has a value whenever there may be messages to send.
+	ready chan struct{}
+	// overflowed is closed once a message didn't fit. Nothing is queued after that until Reset is called,
+	// since the client has already missed a message.
+	overflowed chan struct{}
+	full       bool
+}
+
+func NewOutbox(capacity int) *Outbox {
+	return &Outbox{
+		mu:         &sync.Mutex{},
+		capacity:   capacity,
+		ready:      make(chan struct{}, 1),
+		overflowed: make(chan struct{}),
+	}
+}
+
+// Push queues the message to be sent after every queued message with the same or an earlier zxid.
+// It returns false if the message was dropped because the outbox overflowed.
+func (o *Outbox) Push(msg *pbzk.ZookeeperResponse) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.full {
+		return false
+	}
+	if len(o.messages) >= o.capacity {
+		o.full = true
+		close(o.overflowed)
+		return false
+	}
+
+	// Messages almost always arrive in order, so look for where this one goes starting from the back.
+	i := len(o.messages)
+	for i > 0 && o.messages[i-1].GetZxid() > msg.GetZxid() {
+		i--
+	}
+	o.messages = append(o.messages, nil)
+	copy(o.messages[i+1:], o.messages[i:])
+	o.messages[i] = msg
+
+	select {
+	case o.ready <- struct{}{}:
+	default:
+		// The connection has already been told there are messages to send.
+	}
+	return true
+}
+
+// Ready returns a channel that has a value whenever there may be messages to send.
+func (o *Outbox) Ready() <-chan struct{} {
+	return o.ready
+}
+
+// Overflowed returns a channel that is closed once the outbox overflows.
+func (o *Outbox) Overflowed() <-chan struct{} {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.overflowed
+}
+
+// Drain removes and returns every queued message, in the order they should be sent.
+func (o *Outbox) Drain() []*pbzk.ZookeeperResponse {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	messages := o.messages
+	o.messages = nil
+	return messages
+}
+
+// Len returns the number of queued messages.
+func (o *Outbox) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.messages)
+}
+
+// Reset drops the queued messages if the outbox overflowed, so that it can be used again by a new connection.
+// The messages are dropped since some of them are missing anyway, so the client has to resync instead.
+func (o *Outbox) Reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if !o.full {
+		return
+	}
+	o.messages = nil
+	o.full = false
+	o.overflowed = make(chan struct{})
+}
@@ -0,0 +1,59 @@
+package session
+
+import (
+	"testing"
+
+	pbzk "github.com/mikekulinski/zookeeper/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestOutbox_Order verifies that messages are sent in zxid order, and in the order they were queued
+// when they have the same zxid.
+func TestOutbox_Order(t *testing.T) {
+	o := NewOutbox(10)
+	for _, msg := range []*pbzk.ZookeeperResponse{
+		{Xid: 1, Zxid: 2},
+		{Xid: 2, Zxid: 4},
+		{Xid: 3, Zxid: 3},
+		{Xid: 4, Zxid: 4},
+		{Xid: 5, Zxid: 1},
+	} {
+		assert.True(t, o.Push(msg))
+	}
+	assert.Len(t, o.Ready(), 1)
+
+	var xids []int64
+	for _, msg := range o.Drain() {
+		xids = append(xids, msg.GetXid())
+	}
+	assert.Equal(t, []int64{5, 1, 3, 2, 4}, xids)
+	assert.Empty(t, o.Drain())
+}
+
+// TestOutbox_Overflow verifies that nothing more is queued once the outbox overflows, until it's reset.
+func TestOutbox_Overflow(t *testing.T) {
+	o := NewOutbox(2)
+	assert.True(t, o.Push(&pbzk.ZookeeperResponse{Zxid: 1}))
+	assert.True(t, o.Push(&pbzk.ZookeeperResponse{Zxid: 2}))
+	select {
+	case <-o.Overflowed():
+		assert.Fail(t, "the outbox isn't over capacity yet")
+	default:
+	}
+
+	assert.False(t, o.Push(&pbzk.ZookeeperResponse{Zxid: 3}))
+	assert.NotPanics(t, func() { <-o.Overflowed() })
+	// Even once there is room again, the client has already missed a message.
+	o.Drain()
+	assert.False(t, o.Push(&pbzk.ZookeeperResponse{Zxid: 4}))
+	assert.Zero(t, o.Len())
+
+	o.Reset()
+	select {
+	case <-o.Overflowed():
+		assert.Fail(t, "the outbox was reset")
+	default:
+	}
+	assert.True(t, o.Push(&pbzk.ZookeeperResponse{Zxid: 5}))
+	assert.Equal(t, 1, o.Len())
+}
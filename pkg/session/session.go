@@ -1,32 +1,110 @@
 package session
 
 import (
-	"github.com/mikekulinski/zookeeper/pkg/znode"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
 	pbzk "github.com/mikekulinski/zookeeper/proto"
 )
 
+const (
+	// ResponseCacheSize is how many of the latest responses we keep for each session. If the client
+	// resends a request we already processed, then we reply with the cached response instead of
+	// applying it twice.
+	ResponseCacheSize = 128
+	// OutboxCapacity is how many messages can be waiting to be sent to the client before we give up on it
+	// and drop the connection.
+	OutboxCapacity = 1000
+)
+
 // TODO: Do we also need a mutex for each session?
 type Session struct {
-	// Messages is a channel of events that the server needs to process.
-	Messages chan *Event
-	// Ephemeral nodes that have been created by this server.
-	EphemeralNodes map[string]*znode.ZNode
+	// ID identifies the session. It is generated by the server when the session is created.
+	ID string
+	// Outbox holds the responses and watch events waiting to be sent to the client.
+	Outbox *Outbox
+	// Timeout is how long the session lasts without hearing from the client. This is negotiated with
+	// the client when the session is created.
+	Timeout time.Duration
+	// Closed is closed once the session has been closed or has expired.
+	Closed chan struct{}
+
+	// Password is a secret given to the client when the session is created. The client has to
+	// present it along with the ID to move the session to a new connection.
+	Password string
+	// Superseded is closed once the connection currently serving this session should stop, because
+	// the client has reconnected on a new connection. This is nil while the client is disconnected.
+	Superseded chan struct{}
+
+	// LastXid is the id of the last request we processed for this session.
+	LastXid int64
+	// responses are the latest responses we sent, in the order we sent them.
+	responses []*pbzk.ZookeeperResponse
+
+	// authMu protects authIDs, since they are read by every connection the session moves to.
+	authMu *sync.Mutex
+	// authIDs are the ids the client has authenticated as with addAuth. Ids that come from the client's
+	// connection aren't kept here, since they don't move with the session.
+	authIDs []*pbzk.Id
 }
 
-func NewSession() *Session {
+func NewSession(timeout time.Duration) *Session {
 	return &Session{
-		// Messages is intentionally not buffered so we can check for timeouts.
-		Messages:       make(chan *Event),
-		EphemeralNodes: make(map[string]*znode.ZNode),
+		ID:       uuid.New().String(),
+		Outbox:   NewOutbox(OutboxCapacity),
+		Timeout:  timeout,
+		Closed:   make(chan struct{}),
+		Password: uuid.New().String(),
+		authMu:   &sync.Mutex{},
+	}
+}
+
+// AddAuthID adds an id that the client has authenticated as.
+func (s *Session) AddAuthID(id *pbzk.Id) {
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+	for _, existing := range s.authIDs {
+		if existing.GetScheme() == id.GetScheme() && existing.GetId() == id.GetId() {
+			return
+		}
+	}
+	s.authIDs = append(s.authIDs, id)
+}
+
+// AuthIDs returns every id the client has authenticated as.
+func (s *Session) AuthIDs() []*pbzk.Id {
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+	return append([]*pbzk.Id(nil), s.authIDs...)
+}
+
+// CacheResponse saves the response so we can send it again if the client resends the request.
+func (s *Session) CacheResponse(resp *pbzk.ZookeeperResponse) {
+	s.responses = append(s.responses, resp)
+	if len(s.responses) > ResponseCacheSize {
+		s.responses = s.responses[1:]
+	}
+}
+
+// CachedResponse returns the response we sent for the request with the given id, or nil if it is
+// no longer cached.
+func (s *Session) CachedResponse(xid int64) *pbzk.ZookeeperResponse {
+	for _, resp := range s.responses {
+		if resp.GetXid() == xid {
+			return resp
+		}
 	}
+	return nil
 }
 
-// An Event is a message that the server needs to process. This can be of
-// several different types. We only expect one of these fields to be non-nil.
+// An Event is a message from the client that the server needs to process.
 type Event struct {
 	ClientRequest *pbzk.ZookeeperRequest
-	WatchEvent    *pbzk.WatchEvent
 	// EOF is used to tell the server that we have lost connection with the client.
 	// We use this instead of closing the channel since we have multiple writers to the channel.
 	EOF bool
+	// Err is set along with EOF if the connection broke instead of the client closing it. The
+	// session is kept around in case the client reconnects.
+	Err error
 }
@@ -0,0 +1,96 @@
+package session
+
+import (
+	"sync"
+	"time"
+)
+
+// Tracker keeps track of when each session will expire. Every message from the client extends its
+// session. If we don't hear from the client before its session timeout, then the session expires.
+type Tracker struct {
+	queue *ExpiryQueue[string]
+	// expire is called with the ID of each session that expires.
+	expire func(clientID string)
+
+	mu *sync.Mutex
+	// timeouts is the negotiated timeout of each session we're tracking.
+	timeouts map[string]time.Duration
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewTracker creates a tracker that checks for expired sessions once every tickTime. The tracker
+// doesn't expire any sessions until Start is called.
+func NewTracker(tickTime time.Duration, expire func(clientID string)) *Tracker {
+	return &Tracker{
+		queue:    NewExpiryQueue[string](tickTime),
+		expire:   expire,
+		mu:       &sync.Mutex{},
+		timeouts: map[string]time.Duration{},
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// AddSession starts tracking the session. It will expire after timeout unless it is touched.
+func (t *Tracker) AddSession(clientID string, timeout time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.timeouts[clientID] = timeout
+	t.queue.Update(clientID, timeout)
+}
+
+// TouchSession pushes back the expiration of the session by its timeout. It returns false if we
+// aren't tracking the session, likely because it has already expired.
+func (t *Tracker) TouchSession(clientID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	timeout, ok := t.timeouts[clientID]
+	if !ok {
+		return false
+	}
+	t.queue.Update(clientID, timeout)
+	return true
+}
+
+// RemoveSession stops tracking the session. This is used when the session is closed.
+func (t *Tracker) RemoveSession(clientID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.timeouts, clientID)
+	t.queue.Remove(clientID)
+}
+
+// Start starts expiring sessions in the background until Stop is called.
+func (t *Tracker) Start() {
+	go t.run()
+}
+
+// Stop stops expiring sessions, and waits for any expirations in progress to finish.
+func (t *Tracker) Stop() {
+	close(t.stop)
+	<-t.done
+}
+
+func (t *Tracker) run() {
+	defer close(t.done)
+	for {
+		select {
+		case <-time.After(t.queue.WaitTime()):
+		case <-t.stop:
+			return
+		}
+
+		t.mu.Lock()
+		expired := t.queue.Poll()
+		for _, clientID := range expired {
+			delete(t.timeouts, clientID)
+		}
+		t.mu.Unlock()
+
+		for _, clientID := range expired {
+			t.expire(clientID)
+		}
+	}
+}
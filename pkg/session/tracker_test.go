@@ -0,0 +1,41 @@
+package session
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTracker verifies that only the sessions we stop hearing from expire.
+func TestTracker(t *testing.T) {
+	const tickTime = 10 * time.Millisecond
+
+	mu := &sync.Mutex{}
+	var expired []string
+	tracker := NewTracker(tickTime, func(clientID string) {
+		mu.Lock()
+		defer mu.Unlock()
+		expired = append(expired, clientID)
+	})
+	tracker.Start()
+	defer tracker.Stop()
+
+	tracker.AddSession("idle", 5*tickTime)
+	tracker.AddSession("active", 5*tickTime)
+	tracker.AddSession("closed", 5*tickTime)
+	tracker.RemoveSession("closed")
+
+	// Keep touching the active session for longer than its timeout.
+	for i := 0; i < 20; i++ {
+		assert.True(t, tracker.TouchSession("active"))
+		time.Sleep(tickTime)
+	}
+	assert.False(t, tracker.TouchSession("idle"))
+	assert.False(t, tracker.TouchSession("closed"))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"idle"}, expired)
+}
@@ -0,0 +1,267 @@
+// Package testcluster runs several Zookeeper servers inside a single process so we can test how clients
+// behave when things go wrong. Every client connection goes through an in-memory network that can cut
+// clients off from a node and delay or drop their messages, and nodes can be crashed and restarted from
+// their log. The servers don't replicate to each other yet, so each node holds its own tree.
+package testcluster
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	zkc "github.com/mikekulinski/zookeeper/pkg/client"
+	"github.com/mikekulinski/zookeeper/pkg/config"
+	zks "github.com/mikekulinski/zookeeper/pkg/server"
+	pbzk "github.com/mikekulinski/zookeeper/proto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	bufferSize = 1024 * 1024
+	// ClientSessionTimeout is the session timeout clients ask for. Clients give up on a server after two thirds
+	// of it, so this keeps tests that wait for a client to give up quick.
+	ClientSessionTimeout = 4500 * time.Millisecond
+)
+
+// Node is a single server in the cluster.
+type Node struct {
+	// ID is the index of the node in the cluster.
+	ID int
+	// LogDir is the directory for this node's write-ahead log. It is kept across restarts, so a restarted
+	// node recovers the tree it had when it crashed.
+	LogDir string
+
+	mu         *sync.Mutex
+	server     *zks.Server
+	grpcServer *grpc.Server
+	lis        *bufconn.Listener
+	// conns are the connections clients have opened to this node.
+	conns []net.Conn
+}
+
+// Server returns the Zookeeper server currently running on this node, or nil if it has crashed.
+func (n *Node) Server() *zks.Server {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.server
+}
+
+// Running returns whether the node is currently up.
+func (n *Node) Running() bool {
+	return n.Server() != nil
+}
+
+func (n *Node) start() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	cfg := config.Default()
+	// The default config is always valid.
+	_ = cfg.Validate()
+	cfg.DataLogDir = n.LogDir
+	// Crashing a node doesn't lose what the process already wrote, so there's no need to wait for the disk.
+	cfg.ForceSync = false
+	server, err := zks.RecoverServer(cfg)
+	if err != nil {
+		return fmt.Errorf("error starting node [%d]: %w", n.ID, err)
+	}
+	n.server = server
+	n.lis = bufconn.Listen(bufferSize)
+	n.grpcServer = grpc.NewServer()
+	pbzk.RegisterZookeeperServer(n.grpcServer, n.server)
+
+	// Capture these so a restart doesn't swap them out from under the goroutine.
+	s, lis := n.grpcServer, n.lis
+	go func() {
+		// Serve only returns an error if we fail to accept on the listener, which happens when we stop.
+		_ = s.Serve(lis)
+	}()
+	return nil
+}
+
+func (n *Node) stop() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.grpcServer == nil {
+		return
+	}
+	// Stop immediately closes every connection, just like the process dying.
+	n.grpcServer.Stop()
+	n.server.Close()
+	n.grpcServer = nil
+	n.server = nil
+	n.lis = nil
+	n.conns = nil
+}
+
+func (n *Node) dial(ctx context.Context) (net.Conn, error) {
+	n.mu.Lock()
+	lis := n.lis
+	n.mu.Unlock()
+
+	if lis == nil {
+		return nil, fmt.Errorf("node [%d] is down", n.ID)
+	}
+	conn, err := lis.DialContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.conns = append(n.conns, conn)
+	return conn, nil
+}
+
+func (n *Node) dropConnections() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, conn := range n.conns {
+		_ = conn.Close()
+	}
+	n.conns = nil
+}
+
+// Cluster is a set of Zookeeper servers running in the same process.
+type Cluster struct {
+	t       testing.TB
+	nodes   []*Node
+	network *Network
+
+	mu *sync.Mutex
+	// clients is the number of clients we've created so far. We use this to give each client a
+	// unique name on the network.
+	clients int
+}
+
+// New starts a cluster with n nodes. Everything is cleaned up once the test finishes.
+func New(t testing.TB, n int) *Cluster {
+	c := &Cluster{
+		t:       t,
+		network: newNetwork(),
+		mu:      &sync.Mutex{},
+	}
+	for i := 0; i < n; i++ {
+		node := &Node{
+			ID:     i,
+			LogDir: t.TempDir(),
+			mu:     &sync.Mutex{},
+		}
+		c.nodes = append(c.nodes, node)
+	}
+	t.Cleanup(c.Close)
+	for _, node := range c.nodes {
+		require.NoError(t, node.start())
+	}
+	return c
+}
+
+// Close stops every node in the cluster.
+func (c *Cluster) Close() {
+	for _, n := range c.nodes {
+		n.stop()
+	}
+}
+
+// Size returns the number of nodes in the cluster, including the ones that are down.
+func (c *Cluster) Size() int {
+	return len(c.nodes)
+}
+
+// Node returns the node with the given id.
+func (c *Cluster) Node(id int) *Node {
+	require.Less(c.t, id, len(c.nodes), "node [%d] does not exist", id)
+	return c.nodes[id]
+}
+
+// Network returns the network connecting the nodes, which can be used to inject faults.
+func (c *Cluster) Network() *Network {
+	return c.network
+}
+
+// Crash stops the node immediately, closing every connection to it.
+func (c *Cluster) Crash(id int) {
+	c.Node(id).stop()
+}
+
+// Restart brings a crashed node back up, using the same log directory it had before.
+func (c *Cluster) Restart(id int) {
+	node := c.Node(id)
+	require.False(c.t, node.Running(), "node [%d] is already running", id)
+	require.NoError(c.t, node.start())
+}
+
+// DialOptions returns the options needed to connect to the nodes over the cluster's network. Connections
+// are routed to the node named in the address they dial. i.e. "node-1:8080" goes to node 1.
+// from is the name of the client dialing, which shows up in the errors for connections the network refuses.
+func (c *Cluster) DialOptions(from string) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			node, err := c.nodeAt(address)
+			if err != nil {
+				return nil, err
+			}
+			toName := NodeName(node.ID)
+			if c.network.blocked(from, toName) {
+				return nil, fmt.Errorf("%s cannot reach %s", from, toName)
+			}
+			return node.dial(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			node, err := c.nodeAt(cc.Target())
+			if err != nil {
+				return nil, err
+			}
+			return c.network.streamInterceptor(from, NodeName(node.ID))(ctx, desc, cc, method, streamer, opts...)
+		}),
+	}
+}
+
+// nodeAt returns the node with the given address.
+func (c *Cluster) nodeAt(address string) (*Node, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	for _, n := range c.nodes {
+		if NodeName(n.ID) == host {
+			return n, nil
+		}
+	}
+	return nil, fmt.Errorf("no node at address [%s]", address)
+}
+
+// Client returns a new client for the given nodes. It connects to the first one and fails over to the
+// rest in order. The client has not called Connect yet.
+func (c *Cluster) Client(to int, others ...int) *zkc.Client {
+	c.mu.Lock()
+	c.clients++
+	name := fmt.Sprintf("client-%d", c.clients)
+	c.mu.Unlock()
+
+	addresses := []string{NodeName(to)}
+	for _, id := range others {
+		addresses = append(addresses, NodeName(id))
+	}
+	client, err := zkc.NewClient(addresses, zkc.WithDialOptions(c.DialOptions(name)...), zkc.WithSessionTimeout(ClientSessionTimeout))
+	require.NoError(c.t, err)
+	return client
+}
+
+// DropConnections closes every open connection to the node without stopping it. Clients have to
+// reconnect, but the node keeps all of its sessions.
+func (c *Cluster) DropConnections(id int) {
+	c.Node(id).dropConnections()
+}
+
+// NodeName is the name of the node on the network.
+func NodeName(id int) string {
+	return fmt.Sprintf("node-%d", id)
+}
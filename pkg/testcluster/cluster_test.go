@@ -0,0 +1,178 @@
+package testcluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	zkc "github.com/mikekulinski/zookeeper/pkg/client"
+	pbzk "github.com/mikekulinski/zookeeper/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createRequest(path string) *pbzk.ZookeeperRequest {
+	return &pbzk.ZookeeperRequest{
+		Message: &pbzk.ZookeeperRequest_Create{
+			Create: &pbzk.CreateRequest{
+				Path: path,
+				Data: []byte(path),
+			},
+		},
+	}
+}
+
+// create sends a create request to the server and waits for the response.
+func create(t *testing.T, client *zkc.Client, path string) {
+	err := client.Send(createRequest(path))
+	require.NoError(t, err)
+	resp, err := client.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, path, resp.GetCreate().GetZNodeName())
+}
+
+func TestCluster_CrashAndRestart(t *testing.T) {
+	ctx := context.Background()
+	c := New(t, 2)
+	logDir := c.Node(1).LogDir
+
+	client := c.Client(1)
+	require.NoError(t, client.Connect(ctx))
+	create(t, client, "/zoo")
+	require.NoError(t, client.Close())
+	digest := c.Node(1).Server().Digest()
+
+	c.Crash(1)
+	assert.False(t, c.Node(1).Running())
+	assert.True(t, c.Node(0).Running())
+
+	// Clients can't connect to a crashed node.
+	client = c.Client(1)
+	connectCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	assert.Error(t, client.Connect(connectCtx))
+
+	c.Restart(1)
+	assert.True(t, c.Node(1).Running())
+	assert.Equal(t, logDir, c.Node(1).LogDir)
+
+	// The node recovers exactly the tree it had from its log.
+	assert.Equal(t, digest, c.Node(1).Server().Digest())
+	client = c.Client(1)
+	require.NoError(t, client.Connect(ctx))
+	err := client.Send(&pbzk.ZookeeperRequest{
+		Message: &pbzk.ZookeeperRequest_GetData{
+			GetData: &pbzk.GetDataRequest{Path: "/zoo"},
+		},
+	})
+	require.NoError(t, err)
+	resp, err := client.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("/zoo"), resp.GetGetData().GetData())
+	create(t, client, "/zoo/giraffe")
+	require.NoError(t, client.Close())
+}
+
+func TestNetwork_Faults(t *testing.T) {
+	n := newNetwork()
+
+	n.Isolate(0)
+	assert.True(t, n.blocked("client-1", NodeName(0)))
+	assert.False(t, n.blocked("client-1", NodeName(1)))
+
+	n.Heal()
+	assert.False(t, n.blocked("client-1", NodeName(0)))
+	n.Drop(1, 1)
+	assert.False(t, n.deliver("client-1", NodeName(1)))
+	assert.False(t, n.deliver(NodeName(1), "client-1"))
+	assert.True(t, n.deliver("client-1", NodeName(2)))
+
+	n.Heal()
+	n.Delay(2, 20*time.Millisecond)
+	start := time.Now()
+	assert.True(t, n.deliver("client-1", NodeName(2)))
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+}
+
+func TestCluster_IsolatedNode(t *testing.T) {
+	ctx := context.Background()
+	c := New(t, 1)
+
+	client := c.Client(0)
+	require.NoError(t, client.Connect(ctx))
+	create(t, client, "/zoo")
+
+	// Once the node is cut off, the client stops hearing back from the server.
+	c.Network().Isolate(0)
+	err := client.Send(createRequest("/zoo/giraffe"))
+	require.NoError(t, err)
+	_, err = client.Recv()
+	assert.ErrorIs(t, err, zkc.ErrIdleTimeout)
+	assert.Equal(t, zkc.State_DISCONNECTED, client.State())
+}
+
+func TestCluster_DropConnections(t *testing.T) {
+	ctx := context.Background()
+	c := New(t, 1)
+
+	client := c.Client(0)
+	require.NoError(t, client.Connect(ctx))
+	err := client.Send(&pbzk.ZookeeperRequest{
+		Message: &pbzk.ZookeeperRequest_Create{
+			Create: &pbzk.CreateRequest{
+				Path: "/zoo",
+				Mode: pbzk.CreateRequest_MODE_EPHEMERAL,
+			},
+		},
+	})
+	require.NoError(t, err)
+	_, err = client.Recv()
+	require.NoError(t, err)
+
+	// The client reconnects on its own and keeps its session, so the ephemeral node is still around.
+	c.DropConnections(0)
+	err = client.Send(&pbzk.ZookeeperRequest{
+		Message: &pbzk.ZookeeperRequest_Exists{
+			Exists: &pbzk.ExistsRequest{Path: "/zoo"},
+		},
+	})
+	require.NoError(t, err)
+	resp, err := client.Recv()
+	require.NoError(t, err)
+	assert.True(t, resp.GetExists().GetExists())
+	require.NoError(t, client.Close())
+}
+
+func TestCluster_FailoverWithoutReplication(t *testing.T) {
+	ctx := context.Background()
+	c := New(t, 2)
+
+	client := c.Client(0, 1)
+	require.NoError(t, client.Connect(ctx))
+	create(t, client, "/zoo")
+
+	// Nothing is replicated yet, so the other node is behind the client and refuses the connection.
+	c.Crash(0)
+	err := client.Send(createRequest("/zoo/giraffe"))
+	require.NoError(t, err)
+	_, err = client.Recv()
+	assert.ErrorIs(t, err, zkc.ErrIdleTimeout)
+
+	// Once the other node has caught up, it still has never heard of our session, so it tells the
+	// client that the session has expired.
+	c.Restart(0)
+	other := c.Client(1)
+	require.NoError(t, other.Connect(ctx))
+	create(t, other, "/zoo")
+	require.NoError(t, other.Close())
+
+	client = c.Client(0, 1)
+	require.NoError(t, client.Connect(ctx))
+	create(t, client, "/giraffe")
+	c.Crash(0)
+	err = client.Send(createRequest("/giraffe/baby"))
+	require.NoError(t, err)
+	_, err = client.Recv()
+	assert.ErrorIs(t, err, zkc.ErrSessionExpired)
+	assert.Equal(t, zkc.State_EXPIRED, client.State())
+}
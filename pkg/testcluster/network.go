@@ -0,0 +1,147 @@
+package testcluster
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Network decides which messages make it between clients and the nodes they connect to. Faults apply to
+// whole messages, so a dropped message simply never shows up on the other side, just like a lost packet.
+// The nodes don't replicate yet, so they never talk to each other and there are no links between nodes
+// to break.
+// TODO: Partition the nodes from each other once they replicate.
+type Network struct {
+	mu *sync.Mutex
+	// isolated is the set of nodes that no client can reach.
+	isolated map[string]bool
+	// delays is how long every message between a node and its clients is delayed.
+	delays map[string]time.Duration
+	// dropRates is the fraction of messages between a node and its clients that are dropped.
+	dropRates map[string]float64
+	rand      *rand.Rand
+}
+
+func newNetwork() *Network {
+	return &Network{
+		mu:        &sync.Mutex{},
+		isolated:  map[string]bool{},
+		delays:    map[string]time.Duration{},
+		dropRates: map[string]float64{},
+		rand:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Isolate cuts the node off from every client, without stopping it.
+func (n *Network) Isolate(id int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.isolated[NodeName(id)] = true
+}
+
+// Delay delays every message between the node and its clients.
+func (n *Network) Delay(id int, d time.Duration) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.delays[NodeName(id)] = d
+}
+
+// Drop randomly drops the given fraction of messages between the node and its clients. A rate of 1 drops
+// every message.
+func (n *Network) Drop(id int, rate float64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.dropRates[NodeName(id)] = rate
+}
+
+// Heal removes every fault from the network.
+func (n *Network) Heal() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.isolated = map[string]bool{}
+	n.delays = map[string]time.Duration{}
+	n.dropRates = map[string]float64{}
+}
+
+// blocked returns whether from is currently unable to reach to.
+func (n *Network) blocked(from, to string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.blockedLocked(from, to)
+}
+
+func (n *Network) blockedLocked(from, to string) bool {
+	return n.isolated[from] || n.isolated[to]
+}
+
+// deliver decides what happens to a single message sent between a client and a node. It sleeps
+// for any delay on the link and returns whether the message should be delivered.
+func (n *Network) deliver(from, to string) bool {
+	n.mu.Lock()
+	if n.blockedLocked(from, to) {
+		n.mu.Unlock()
+		return false
+	}
+	delay := n.delays[from] + n.delays[to]
+	dropRate := max(n.dropRates[from], n.dropRates[to])
+	dropped := dropRate > 0 && n.rand.Float64() < dropRate
+	n.mu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	return !dropped
+}
+
+// streamInterceptor applies the faults of the network to every message on streams from a client to a node.
+func (n *Network) streamInterceptor(from, to string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		// The connection may have been set up before the fault, so check again for every new stream.
+		if n.blocked(from, to) {
+			return nil, status.Errorf(codes.Unavailable, "%s cannot reach %s", from, to)
+		}
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &faultyStream{
+			ClientStream: stream,
+			network:      n,
+			from:         from,
+			to:           to,
+		}, nil
+	}
+}
+
+// faultyStream is a client stream where messages in either direction can be delayed or dropped.
+type faultyStream struct {
+	grpc.ClientStream
+	network  *Network
+	from, to string
+}
+
+func (f *faultyStream) SendMsg(m any) error {
+	if !f.network.deliver(f.from, f.to) {
+		// The message was lost on the way to the server.
+		return nil
+	}
+	return f.ClientStream.SendMsg(m)
+}
+
+func (f *faultyStream) RecvMsg(m any) error {
+	for {
+		err := f.ClientStream.RecvMsg(m)
+		if err != nil {
+			return err
+		}
+		if f.network.deliver(f.to, f.from) {
+			return nil
+		}
+		// The message was lost on the way back, so wait for the next one.
+	}
+}
@@ -10,7 +10,8 @@ const (
 	ClientIDHeader = "X-Client-ID"
 )
 
-// ExtractClientIDHeader extracts the clientID from the context.
+// ExtractClientIDHeader extracts the clientID from the context. This is the ID of the session the request
+// was made in.
 func ExtractClientIDHeader(ctx context.Context) (string, bool) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
@@ -25,13 +26,6 @@ func ExtractClientIDHeader(ctx context.Context) (string, bool) {
 	return values[0], true
 }
 
-func SetOutgoingClientIDHeader(ctx context.Context, clientID string) context.Context {
-	// Add client ID to outgoing metadata
-	md := metadata.Pairs(ClientIDHeader, clientID)
-	ctx = metadata.NewOutgoingContext(ctx, md)
-	return ctx
-}
-
 func SetIncomingClientIDHeader(ctx context.Context, clientID string) context.Context {
 	// Add client ID to outgoing metadata
 	md := metadata.Pairs(ClientIDHeader, clientID)
@@ -0,0 +1,21 @@
+package znode
+
+import (
+	pbzk "github.com/mikekulinski/zookeeper/proto"
+)
+
+const (
+	// SchemeWorld is the scheme with a single id, "anyone", that every client has.
+	SchemeWorld = "world"
+	// IDAnyone is the only id in the world scheme.
+	IDAnyone = "anyone"
+)
+
+var (
+	// AnyoneID is the identity that every client has, whether or not they authenticated.
+	AnyoneID = &pbzk.Id{Scheme: SchemeWorld, Id: IDAnyone}
+	// OpenACLUnsafe lets anyone do anything to the node. This is the ACL of nodes created without one.
+	OpenACLUnsafe = []*pbzk.ACL{{Perms: int32(pbzk.Permission_PERMISSION_ALL), Id: AnyoneID}}
+	// ReadACLUnsafe lets anyone read the node, but nobody change it.
+	ReadACLUnsafe = []*pbzk.ACL{{Perms: int32(pbzk.Permission_PERMISSION_READ), Id: AnyoneID}}
+)
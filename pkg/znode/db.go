@@ -2,46 +2,147 @@
 package znode
 
 import (
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"hash"
+	"hash/fnv"
+	"log"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	pbzk "github.com/mikekulinski/zookeeper/proto"
 )
 
+var (
+	// ErrNotEmpty is returned when deleting a node that has children.
+	ErrNotEmpty = errors.New("node has children")
+	// ErrNodeChanged is returned by a conditional delete when the node has changed since the delete was
+	// decided on.
+	ErrNodeChanged = errors.New("node has changed")
+)
+
 type ZKDB interface {
 	Get(path string) *ZNode
+	Children(path string) []string
 	Create(txn *pbzk.Transaction) (*ZNode, error)
 	Delete(txn *pbzk.Transaction) error
 	SetData(txn *pbzk.Transaction) error
+	SetACL(txn *pbzk.Transaction) error
+	Reconfig(txn *pbzk.Transaction) error
+	CloseSession(txn *pbzk.Transaction) ([]string, error)
+	ExpiredNodes(nowMs int64, containerGracePeriod time.Duration) []*ZNode
+	Quotas(path string) []QuotaUsage
+	Digest() uint64
 }
 
 // DB is the source of truth for all the data stored in the Zookeeper server. It also controls the
 // locking mechanism, so it can be abstracted away from the caller.
 type DB struct {
-	// TODO: Consider using a map to each node.
 	root *ZNode
-	mu   *sync.RWMutex
+	// nodes indexes every node in the tree by its full path, so lookups don't have to walk down the tree.
+	// The root is stored under the empty path.
+	nodes map[string]*ZNode
+	mu    *sync.RWMutex
+	// quotas is the usage of every subtree with a quota, by the path to the root of the subtree. This is
+	// updated as each transaction is applied, so every replica has the same usage.
+	quotas map[string]*QuotaUsage
 }
 
 func NewDB() *DB {
-	return &DB{
-		root: NewZNode("", ZNodeType_STANDARD, "", nil),
-		mu:   &sync.RWMutex{},
+	return NewDBWithConfig(nil, ReadACLUnsafe)
+}
+
+// NewDBWithConfig creates a DB whose config node holds the given ensemble config and has the given ACL.
+// Reconfig writes to the config node, so its ACL decides who can change the ensemble.
+func NewDBWithConfig(config []byte, configACL []*pbzk.ACL) *DB {
+	root := NewZNode("", ZNodeType_STANDARD, "", nil)
+	// Set up the nodes Zookeeper uses to store its own metadata.
+	zk := NewZNode(ZookeeperPath, ZNodeType_STANDARD, "", nil)
+	configNode := NewZNode(ConfigPath, ZNodeType_STANDARD, "", config)
+	configNode.ACL = configACL
+	zk.Children["config"] = configNode
+	zk.Children["quota"] = NewZNode(QuotaPath, ZNodeType_STANDARD, "", nil)
+	root.Children["zookeeper"] = zk
+	d := &DB{
+		root:   root,
+		nodes:  map[string]*ZNode{},
+		mu:     &sync.RWMutex{},
+		quotas: map[string]*QuotaUsage{},
 	}
+	d.indexZNodes(root)
+	return d
 }
 
+// Get returns a copy of the node at path, or nil if there isn't one. Later writes don't change the copy,
+// so it is safe to read after the lock is released.
 func (d *DB) Get(path string) *ZNode {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
-	names := splitPathIntoNodeNames(path)
+	node := d.nodes[path]
+	if node == nil {
+		return nil
+	}
+	return node.copy()
+}
 
-	return findZNode(d.root, names)
+// Children returns the names of the children of the node at path in sorted order, or nil if there isn't
+// a node at path.
+func (d *DB) Children(path string) []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	node := d.nodes[path]
+	if node == nil {
+		return nil
+	}
+	names := make([]string, 0, len(node.Children))
+	for name := range node.Children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// addChild adds the node to the tree under its parent, and to the index.
+func (d *DB) addChild(parent *ZNode, name string, node *ZNode) {
+	parent.Children[name] = node
+	d.nodes[node.Name] = node
+}
+
+// removeChild removes the child with the name from the tree, and removes it and all of its descendants
+// from the index.
+func (d *DB) removeChild(parent *ZNode, name string) {
+	node, ok := parent.Children[name]
+	if !ok {
+		return
+	}
+	delete(parent.Children, name)
+	d.unindexZNodes(node)
+}
+
+// indexZNodes adds the node and all of its descendants to the index.
+func (d *DB) indexZNodes(node *ZNode) {
+	d.nodes[node.Name] = node
+	for _, child := range node.Children {
+		d.indexZNodes(child)
+	}
+}
+
+// unindexZNodes removes the node and all of its descendants from the index.
+func (d *DB) unindexZNodes(node *ZNode) {
+	delete(d.nodes, node.Name)
+	for _, child := range node.Children {
+		d.unindexZNodes(child)
+	}
 }
 
 // findZNode will search down to the tree and return the node specified by the names.
-// If the node could not be found, then we will return nil.
+// If the node could not be found, then we will return nil. Lookups use the index instead, but this
+// is the source of truth that the index is checked against.
 func findZNode(start *ZNode, names []string) *ZNode {
 	node := start
 	for _, name := range names {
@@ -67,9 +168,8 @@ func (d *DB) Create(txn *pbzk.Transaction) (*ZNode, error) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	names := splitPathIntoNodeNames(txn.GetCreate().GetPath())
-	// Search down the tree until we hit the parent where we'll be creating this new node.
-	parent := findZNode(d.root, names[:len(names)-1])
+	parentName := parentPath(txn.GetCreate().GetPath())
+	parent := d.nodes[parentName]
 	if parent == nil {
 		return nil, fmt.Errorf("at least one of the anscestors of this node are missing")
 	}
@@ -79,16 +179,32 @@ func (d *DB) Create(txn *pbzk.Transaction) (*ZNode, error) {
 
 	// We are at the parent node of the one we are trying to create. Now let's
 	// try to create it.
-	newName := names[len(names)-1]
+	newName := baseName(txn.GetCreate().GetPath())
 	if txn.GetCreate().GetSequential() {
-		newName = fmt.Sprintf("%s_%d", newName, parent.NextSequentialNode)
+		var err error
+		newName, err = SequentialName(newName, parent.Cversion)
+		if err != nil {
+			return nil, err
+		}
 	}
-	fullName := newFullName(newName, names[:len(names)-1])
+	fullName := newFullName(newName, parentName)
 
 	nodeType := ZNodeType_STANDARD
 	if txn.GetCreate().GetEphemeral() {
 		nodeType = ZNodeType_EPHEMERAL
 	}
+	if txn.GetCreate().GetTtlMs() > 0 {
+		if nodeType == ZNodeType_EPHEMERAL {
+			return nil, fmt.Errorf("ephemeral nodes cannot have a TTL")
+		}
+		nodeType = ZNodeType_TTL
+	}
+	if txn.GetCreate().GetContainer() {
+		if nodeType != ZNodeType_STANDARD || txn.GetCreate().GetSequential() {
+			return nil, fmt.Errorf("container nodes cannot be ephemeral, sequential, or have a TTL")
+		}
+		nodeType = ZNodeType_CONTAINER
+	}
 
 	newNode := NewZNode(
 		fullName,
@@ -96,24 +212,33 @@ func (d *DB) Create(txn *pbzk.Transaction) (*ZNode, error) {
 		txn.GetClientId(),
 		txn.GetCreate().GetData(),
 	)
+	if len(txn.GetCreate().GetAcl()) > 0 {
+		newNode.ACL = txn.GetCreate().GetAcl()
+	}
+	newNode.Czxid = txn.GetZxid()
+	newNode.Mzxid = txn.GetZxid()
+	newNode.Pzxid = txn.GetZxid()
+	newNode.Ctime = txn.GetTimestampMs()
+	newNode.Mtime = txn.GetTimestampMs()
+	newNode.TTL = time.Duration(txn.GetCreate().GetTtlMs()) * time.Millisecond
 
 	if _, ok := parent.Children[newName]; ok {
 		return nil, fmt.Errorf("node [%s] already exists at path [%s]", newName, txn.GetCreate().GetPath())
 	}
-	parent.Children[newName] = newNode
-	// Make sure to increment the counter so the next sequential node will have the next number.
-	if txn.GetCreate().GetSequential() {
-		parent.NextSequentialNode++
+	d.addChild(parent, newName, newNode)
+	parent.Cversion++
+	parent.Pzxid = txn.GetZxid()
+	if target, ok := QuotaTarget(fullName); ok {
+		d.setQuota(target, newNode)
+	} else {
+		d.updateQuotas(fullName, 1, int64(len(newNode.Data)))
 	}
-	return newNode, nil
+	return newNode.copy(), nil
 }
 
-func newFullName(nodeName string, ancestorsNames []string) string {
-	nodePath := "/" + nodeName
-	if len(ancestorsNames) > 0 {
-		return "/" + strings.Join(ancestorsNames, "/") + nodePath
-	}
-	return nodePath
+// newFullName returns the path to the node named nodeName under the node at parentName.
+func newFullName(nodeName string, parentName string) string {
+	return parentName + "/" + nodeName
 }
 
 func (d *DB) Delete(txn *pbzk.Transaction) error {
@@ -124,17 +249,35 @@ func (d *DB) Delete(txn *pbzk.Transaction) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	names := splitPathIntoNodeNames(txn.GetDelete().GetPath())
-
-	// Search down the tree until we hit the parent where we'll be creating this new node.
-	parent := findZNode(d.root, names[:len(names)-1])
+	parent := d.nodes[parentPath(txn.GetDelete().GetPath())]
 	if parent == nil {
 		return fmt.Errorf("at least one of the anscestors of this node are missing")
 	}
 
-	nameToDelete := names[len(names)-1]
+	nameToDelete := baseName(txn.GetDelete().GetPath())
+	node, ok := parent.Children[nameToDelete]
+	if !ok {
+		return nil
+	}
+	// Deleting a node with children would silently drop the whole subtree.
+	if len(node.Children) > 0 {
+		return fmt.Errorf("%w: [%s]", ErrNotEmpty, node.Name)
+	}
+	if cond := txn.GetDelete().GetIfUnchanged(); cond != nil {
+		if node.Version != cond.GetVersion() || node.Cversion != cond.GetCversion() {
+			return fmt.Errorf("%w: [%s] is at version [%d] and cversion [%d], expected [%d] and [%d]",
+				ErrNodeChanged, node.Name, node.Version, node.Cversion, cond.GetVersion(), cond.GetCversion())
+		}
+	}
 	// Delete the actual node from the tree.
-	delete(parent.Children, nameToDelete)
+	d.removeChild(parent, nameToDelete)
+	parent.Cversion++
+	parent.Pzxid = txn.GetZxid()
+	if target, ok := QuotaTarget(node.Name); ok {
+		d.removeQuota(target, parent)
+	} else {
+		d.updateQuotas(node.Name, -1, -int64(len(node.Data)))
+	}
 	return nil
 }
 
@@ -146,14 +289,279 @@ func (d *DB) SetData(txn *pbzk.Transaction) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	names := splitPathIntoNodeNames(txn.GetSetData().GetPath())
-
 	// Find the node we're updating.
-	node := findZNode(d.root, names)
+	node := d.nodes[txn.GetSetData().GetPath()]
 	if node == nil {
 		return fmt.Errorf("node not found")
 	}
+	bytesDelta := int64(len(txn.GetSetData().GetData()) - len(node.Data))
 	node.Data = txn.GetSetData().GetData()
 	node.Version++
+	node.Mzxid = txn.GetZxid()
+	node.Mtime = txn.GetTimestampMs()
+	if target, ok := QuotaTarget(node.Name); ok {
+		d.setQuota(target, node)
+	} else {
+		d.updateQuotas(node.Name, 0, bytesDelta)
+	}
 	return nil
 }
+
+// SetACL replaces the ACL of the node.
+func (d *DB) SetACL(txn *pbzk.Transaction) error {
+	if txn.GetSetAcl() == nil {
+		return fmt.Errorf("not a setACL txn")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	node := d.nodes[txn.GetSetAcl().GetPath()]
+	if node == nil {
+		return fmt.Errorf("node not found")
+	}
+	node.ACL = txn.GetSetAcl().GetAcl()
+	node.ACLVersion++
+	return nil
+}
+
+// Reconfig stores the new membership of the ensemble in the config node.
+func (d *DB) Reconfig(txn *pbzk.Transaction) error {
+	if txn.GetReconfig() == nil {
+		return fmt.Errorf("not a reconfig txn")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	node := d.nodes[ConfigPath]
+	if node == nil {
+		return fmt.Errorf("config node not found")
+	}
+	node.Data = txn.GetReconfig().GetConfig()
+	node.Version++
+	node.Mzxid = txn.GetZxid()
+	return nil
+}
+
+// CloseSession deletes every ephemeral node created by the transaction's client. It returns the paths
+// of the nodes it deleted, so the caller can notify anyone watching them.
+func (d *DB) CloseSession(txn *pbzk.Transaction) ([]string, error) {
+	if txn.GetCloseSession() == nil {
+		return nil, fmt.Errorf("not a closeSession txn")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// TODO: Keep an index of the ephemeral nodes for each session so we don't have to search the whole tree.
+	var deletedNodes []*ZNode
+	deleteEphemeralZNodes(d.root, txn.GetClientId(), txn.GetZxid(), &deletedNodes)
+	var deleted []string
+	for _, node := range deletedNodes {
+		d.unindexZNodes(node)
+		d.updateQuotas(node.Name, -1, -int64(len(node.Data)))
+		deleted = append(deleted, node.Name)
+	}
+	// Sort so every replica reports the deletes in the same order.
+	sort.Strings(deleted)
+	return deleted, nil
+}
+
+func deleteEphemeralZNodes(node *ZNode, clientID string, zxid int64, deleted *[]*ZNode) {
+	for name, child := range node.Children {
+		if child.NodeType == ZNodeType_EPHEMERAL && child.Creator == clientID {
+			delete(node.Children, name)
+			node.Cversion++
+			node.Pzxid = zxid
+			*deleted = append(*deleted, child)
+			continue
+		}
+		deleteEphemeralZNodes(child, clientID, zxid, deleted)
+	}
+}
+
+// ExpiredNodes returns copies of the nodes that the server should delete as of nowMs, sorted by path.
+// TTL nodes expire once they have had no children and no modifications for their TTL. Containers expire
+// once their last child is deleted. Containers that never had a child expire after containerGracePeriod,
+// so clients have time to add the first child. The nodes could change before the server deletes them, so
+// the deletes should be conditional on the versions of the copies.
+func (d *DB) ExpiredNodes(nowMs int64, containerGracePeriod time.Duration) []*ZNode {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	// TODO: Keep an index of the TTL and container nodes so we don't have to search the whole tree.
+	var expired []*ZNode
+	findExpiredZNodes(d.root, nowMs, containerGracePeriod.Milliseconds(), &expired)
+	sort.Slice(expired, func(i, j int) bool {
+		return expired[i].Name < expired[j].Name
+	})
+	return expired
+}
+
+func findExpiredZNodes(node *ZNode, nowMs int64, containerGracePeriodMs int64, expired *[]*ZNode) {
+	if len(node.Children) == 0 {
+		switch node.NodeType {
+		case ZNodeType_TTL:
+			if nowMs-node.Mtime >= node.TTL.Milliseconds() {
+				*expired = append(*expired, node.copy())
+			}
+		case ZNodeType_CONTAINER:
+			if node.Cversion > 0 || nowMs-node.Ctime >= containerGracePeriodMs {
+				*expired = append(*expired, node.copy())
+			}
+		}
+	}
+	for _, child := range node.Children {
+		findExpiredZNodes(child, nowMs, containerGracePeriodMs, expired)
+	}
+}
+
+// Quotas returns the usage of every quota that counts the node at path, from the closest to the root of
+// the tree.
+func (d *DB) Quotas(path string) []QuotaUsage {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var usages []QuotaUsage
+	for p := path; p != ""; p = parentPath(p) {
+		if usage, ok := d.quotas[p]; ok {
+			usages = append(usages, *usage)
+		}
+	}
+	return usages
+}
+
+// updateQuotas adds to the usage of every quota that counts the node at path.
+func (d *DB) updateQuotas(path string, countDelta int64, bytesDelta int64) {
+	// Zookeeper's own nodes don't count towards any quota. Most trees don't have any quotas, so skip walking
+	// up the tree.
+	if len(d.quotas) == 0 || quotaCovers(ZookeeperPath, path) {
+		return
+	}
+	for p := path; p != ""; p = parentPath(p) {
+		usage, ok := d.quotas[p]
+		if !ok {
+			continue
+		}
+		usage.Count += countDelta
+		usage.Bytes += bytesDelta
+		d.writeQuotaStats(usage)
+	}
+}
+
+// setQuota starts enforcing the limits in the limits node on the subtree at target. If the limits are
+// invalid, then we stop enforcing the quota.
+func (d *DB) setQuota(target string, limitsNode *ZNode) {
+	limits, err := ParseQuota(limitsNode.Data)
+	if err != nil {
+		log.Printf("Ignoring the quota on [%s]: %v\n", target, err)
+		d.removeQuota(target, d.nodes[parentPath(limitsNode.Name)])
+		return
+	}
+	usage, ok := d.quotas[target]
+	if !ok {
+		// Count what is already in the subtree.
+		usage = &QuotaUsage{Path: target}
+		countZNodes(d.nodes[target], usage)
+		d.quotas[target] = usage
+	}
+	usage.Limits = limits
+	d.writeQuotaStats(usage)
+}
+
+// removeQuota stops enforcing the quota on the subtree at target. quotaNode is the node in the quota
+// subtree that holds the limits and stats.
+func (d *DB) removeQuota(target string, quotaNode *ZNode) {
+	delete(d.quotas, target)
+	if quotaNode != nil {
+		d.removeChild(quotaNode, QuotaStatsNode)
+	}
+}
+
+// writeQuotaStats stores the usage in the stats node of the quota, so clients can read it.
+func (d *DB) writeQuotaStats(usage *QuotaUsage) {
+	quotaNode := d.nodes[QuotaPath+usage.Path]
+	if quotaNode == nil {
+		return
+	}
+	stats, ok := quotaNode.Children[QuotaStatsNode]
+	if !ok {
+		stats = NewZNode(quotaNode.Name+"/"+QuotaStatsNode, ZNodeType_STANDARD, "", nil)
+		d.addChild(quotaNode, QuotaStatsNode, stats)
+	}
+	stats.Data = usage.statsData()
+}
+
+func countZNodes(node *ZNode, usage *QuotaUsage) {
+	if node == nil {
+		return
+	}
+	usage.Count++
+	usage.Bytes += int64(len(node.Data))
+	for _, child := range node.Children {
+		countZNodes(child, usage)
+	}
+}
+
+// parentPath returns the path to the parent of the node at path, or "" for children of the root.
+func parentPath(path string) string {
+	i := strings.LastIndex(path, "/")
+	if i <= 0 {
+		return ""
+	}
+	return path[:i]
+}
+
+// baseName returns the name of the node at path, which is everything after the last /.
+func baseName(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// Digest returns a hash of every node in the tree. Two DBs with the same digest hold the same data,
+// which lets us cheaply check that replicas have converged.
+func (d *DB) Digest() uint64 {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	h := fnv.New64a()
+	digestZNode(h, d.root)
+	return h.Sum64()
+}
+
+func digestZNode(h hash.Hash64, node *ZNode) {
+	var buf [8]byte
+	_, _ = h.Write([]byte(node.Name))
+	binary.BigEndian.PutUint64(buf[:], uint64(node.Version))
+	_, _ = h.Write(buf[:])
+	binary.BigEndian.PutUint64(buf[:], uint64(node.Cversion))
+	_, _ = h.Write(buf[:])
+	binary.BigEndian.PutUint64(buf[:], uint64(node.NodeType))
+	_, _ = h.Write(buf[:])
+	// The creator is only part of the data for ephemeral nodes, since it ties them to a session.
+	if node.NodeType == ZNodeType_EPHEMERAL {
+		_, _ = h.Write([]byte(node.Creator))
+	}
+	binary.BigEndian.PutUint64(buf[:], uint64(node.TTL))
+	_, _ = h.Write(buf[:])
+	binary.BigEndian.PutUint64(buf[:], uint64(node.ACLVersion))
+	_, _ = h.Write(buf[:])
+	for _, acl := range node.ACL {
+		binary.BigEndian.PutUint64(buf[:], uint64(acl.GetPerms()))
+		_, _ = h.Write(buf[:])
+		_, _ = h.Write([]byte(acl.GetId().GetScheme() + ":" + acl.GetId().GetId() + "\x00"))
+	}
+	binary.BigEndian.PutUint64(buf[:], uint64(len(node.Data)))
+	_, _ = h.Write(buf[:])
+	_, _ = h.Write(node.Data)
+
+	// Visit the children in a consistent order so the digest doesn't depend on map iteration order.
+	names := make([]string, 0, len(node.Children))
+	for name := range node.Children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		digestZNode(h, node.Children[name])
+	}
+}
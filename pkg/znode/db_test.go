@@ -2,7 +2,9 @@ package znode
 
 import (
 	"fmt"
+	"sort"
 	"testing"
+	"time"
 
 	pbzk "github.com/mikekulinski/zookeeper/proto"
 	"github.com/stretchr/testify/assert"
@@ -194,113 +196,834 @@ func TestDB_CreateDeleteThenGet(t *testing.T) {
 	}
 }
 
-// TODO: Write tests for checking sequential.
-//// TestDB_Create_Sequential verifies that we are properly incrementing for sequential nodes.
-//func TestDB_Create_Sequential(t *testing.T) {
-//	const rootChildName = "rootChild"
-//	const childChildName = "childChild"
-//	tests := []struct {
-//		name          string
-//		path          string
-//		parentNode    *pbzk.Transaction
-//		node          *ZNode
-//		errorExpected bool
-//	}{
-//		{
-//			name: "node already exists, root",
-//			path: "/" + rootChildName,
-//			parentNode: &pbzk.Transaction{
-//				Txn: &pbzk.Transaction_Create{
-//					Create: &pbzk.CreateTxn{
-//						Path: fmt.Sprintf("/%s_0", rootChildName),
-//						Data: []byte(rootChildName + "_0"),
-//					},
-//				},
-//			},
-//			node: &ZNode{
-//				Name:     "/" + rootChildName,
-//				NodeType: ZNodeType_STANDARD,
-//				Data:     []byte(rootChildName),
-//			},
-//			errorExpected: true,
-//		},
-//		// TODO:
-//		//{
-//		//	name: "node exists, child of another node",
-//		//	path: fmt.Sprintf("/%s/%s", rootChildName, childChildName),
-//		//	node: &ZNode{
-//		//		Name:     fmt.Sprintf("/%s/%s", rootChildName, childChildName),
-//		//		NodeType: ZNodeType_STANDARD,
-//		//		Data:     []byte(childChildName),
-//		//	},
-//		//},
-//	}
-//	for _, test := range tests {
-//		t.Run(test.name, func(t *testing.T) {
-//			db := NewDB()
-//			// Add the parent node.
-//			_, err := db.Create(test.parentNode)
-//			require.NoError(t, err)
-//			// Add the child node.
-//			txn := &pbzk.Transaction{
-//				Txn: &pbzk.Transaction_Create{
-//					Create: &pbzk.CreateTxn{
-//						Path: fmt.Sprintf("/%s/%s", rootChildName, childChildName),
-//						Data: []byte(childChildName),
-//						// Creating a sequential node.
-//						Sequential: true,
-//					},
-//				},
-//			}
-//			_, err = db.Create(txn)
-//			require.NoError(t, err)
-//
-//			node := db.Get(test.path)
-//			if test.node == nil || node == nil {
-//				// If at least one is nil, then check that they are both nil.
-//				assert.Nil(t, test.node)
-//				assert.Nil(t, node)
-//			} else {
-//				// Otherwise verify the data is equal.
-//				assert.Equal(t, test.node.Name, node.Name)
-//				assert.Equal(t, test.node.NodeType, node.NodeType)
-//				assert.Equal(t, test.node.Data, node.Data)
-//			}
-//			assert.NoError(t, err)
-//		})
-//	}
-//}
+// TestDB_Create_Sequential verifies that sequential nodes are numbered by the cversion of their parent.
+func TestDB_Create_Sequential(t *testing.T) {
+	create := func(db *DB, path string, sequential bool) (*ZNode, error) {
+		return db.Create(&pbzk.Transaction{
+			Txn: &pbzk.Transaction_Create{
+				Create: &pbzk.CreateTxn{
+					Path:       path,
+					Sequential: sequential,
+				},
+			},
+		})
+	}
+
+	db := NewDB()
+	_, err := create(db, "/locks", false)
+	require.NoError(t, err)
+	var names []string
+	for range 12 {
+		node, err := create(db, "/locks/lock-", true)
+		require.NoError(t, err)
+		names = append(names, node.Name)
+	}
+	assert.Equal(t, "/locks/lock-0000000000", names[0])
+	assert.Equal(t, "/locks/lock-0000000011", names[11])
+	// The names sort in the order the nodes were created.
+	assert.True(t, sort.StringsAreSorted(names))
+
+	// Every change to the children uses up a sequence number, not just sequential creates.
+	_, err = create(db, "/locks/other", false)
+	require.NoError(t, err)
+	err = db.Delete(&pbzk.Transaction{
+		Txn: &pbzk.Transaction_Delete{Delete: &pbzk.DeleteTxn{Path: names[0]}},
+	})
+	require.NoError(t, err)
+	node, err := create(db, "/locks/lock-", true)
+	require.NoError(t, err)
+	assert.Equal(t, "/locks/lock-0000000014", node.Name)
+
+	// Numbers aren't reused once the parent runs out.
+	db.nodes["/locks"].Cversion = MaxSequenceNumber + 1
+	_, err = create(db, "/locks/lock-", true)
+	assert.ErrorIs(t, err, ErrSequenceOverflow)
+}
+
+// TestDB_Create_Combinations verifies every combination of the options for creating a node.
+func TestDB_Create_Combinations(t *testing.T) {
+	type options struct {
+		ephemeral, sequential, ttl, container bool
+	}
+	tests := []struct {
+		name          string
+		options       options
+		nodeType      ZNodeType
+		errorExpected bool
+	}{
+		{name: "persistent", options: options{}, nodeType: ZNodeType_STANDARD},
+		{name: "ephemeral", options: options{ephemeral: true}, nodeType: ZNodeType_EPHEMERAL},
+		{name: "persistent sequential", options: options{sequential: true}, nodeType: ZNodeType_STANDARD},
+		{name: "ephemeral sequential", options: options{ephemeral: true, sequential: true}, nodeType: ZNodeType_EPHEMERAL},
+		{name: "ttl", options: options{ttl: true}, nodeType: ZNodeType_TTL},
+		{name: "sequential ttl", options: options{sequential: true, ttl: true}, nodeType: ZNodeType_TTL},
+		{name: "container", options: options{container: true}, nodeType: ZNodeType_CONTAINER},
+		{name: "ephemeral ttl", options: options{ephemeral: true, ttl: true}, errorExpected: true},
+		{name: "ephemeral sequential ttl", options: options{ephemeral: true, sequential: true, ttl: true}, errorExpected: true},
+		{name: "ephemeral container", options: options{ephemeral: true, container: true}, errorExpected: true},
+		{name: "sequential container", options: options{sequential: true, container: true}, errorExpected: true},
+		{name: "ttl container", options: options{ttl: true, container: true}, errorExpected: true},
+		{name: "ephemeral sequential container", options: options{ephemeral: true, sequential: true, container: true}, errorExpected: true},
+		{name: "ephemeral ttl container", options: options{ephemeral: true, ttl: true, container: true}, errorExpected: true},
+		{name: "sequential ttl container", options: options{sequential: true, ttl: true, container: true}, errorExpected: true},
+		{name: "everything", options: options{ephemeral: true, sequential: true, ttl: true, container: true}, errorExpected: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := NewDB()
+			create := &pbzk.CreateTxn{
+				Path:       "/node",
+				Ephemeral:  test.options.ephemeral,
+				Sequential: test.options.sequential,
+				Container:  test.options.container,
+			}
+			if test.options.ttl {
+				create.TtlMs = 1000
+			}
+			node, err := db.Create(&pbzk.Transaction{
+				ClientId: "client",
+				Txn:      &pbzk.Transaction_Create{Create: create},
+			})
+			if test.errorExpected {
+				assert.Error(t, err)
+				assert.Nil(t, db.Get("/node"))
+				assert.Nil(t, db.Get("/node0000000000"))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.nodeType, node.NodeType)
+			expectedName := "/node"
+			if test.options.sequential {
+				expectedName = "/node0000000000"
+			}
+			assert.Equal(t, expectedName, node.Name)
+			assert.Equal(t, node, db.Get(expectedName))
+		})
+	}
+}
+
+func TestSequentialName(t *testing.T) {
+	tests := []struct {
+		name          string
+		cversion      int64
+		expected      string
+		errorExpected bool
+	}{
+		{
+			name:     "first",
+			cversion: 0,
+			expected: "lock-0000000000",
+		},
+		{
+			name:     "padded",
+			cversion: 42,
+			expected: "lock-0000000042",
+		},
+		{
+			name:     "past 32 bits",
+			cversion: 1 << 32,
+			expected: "lock-4294967296",
+		},
+		{
+			name:     "largest",
+			cversion: MaxSequenceNumber,
+			expected: "lock-9999999999",
+		},
+		{
+			name:          "overflow",
+			cversion:      MaxSequenceNumber + 1,
+			errorExpected: true,
+		},
+		{
+			name:          "negative",
+			cversion:      -1,
+			errorExpected: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			name, err := SequentialName("lock-", test.cversion)
+			if test.errorExpected {
+				assert.ErrorIs(t, err, ErrSequenceOverflow)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, name)
+		})
+	}
+}
 
 func TestServer_NewFullName(t *testing.T) {
 	tests := []struct {
 		name           string
 		nodeName       string
-		ancestorsNames []string
+		parentName     string
 		expectedResult string
 	}{
 		{
 			name:           "no ancestors",
 			nodeName:       "node",
-			ancestorsNames: nil,
+			parentName:     "",
 			expectedResult: "/node",
 		},
 		{
 			name:           "1 ancestor",
 			nodeName:       "node",
-			ancestorsNames: []string{"a1"},
+			parentName:     "/a1",
 			expectedResult: "/a1/node",
 		},
 		{
 			name:           "multiple ancestors",
 			nodeName:       "node",
-			ancestorsNames: []string{"a1", "a2", "a3"},
+			parentName:     "/a1/a2/a3",
 			expectedResult: "/a1/a2/a3/node",
 		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			actualResult := newFullName(test.nodeName, test.ancestorsNames)
+			actualResult := newFullName(test.nodeName, test.parentName)
 			assert.Equal(t, test.expectedResult, actualResult)
 		})
 	}
 }
+
+// TestDB_Reconfig verifies that the new config is stored in the config node.
+func TestDB_Reconfig(t *testing.T) {
+	db := NewDB()
+	node := db.Get(ConfigPath)
+	require.NotNil(t, node)
+	assert.Empty(t, node.Data)
+	assert.Zero(t, node.Version)
+
+	txn := &pbzk.Transaction{
+		Txn: &pbzk.Transaction_Reconfig{
+			Reconfig: &pbzk.ReconfigTxn{
+				Config: []byte("server.1=zk1:2888:3888:participant\nversion=1\n"),
+			},
+		},
+	}
+	err := db.Reconfig(txn)
+	require.NoError(t, err)
+
+	node = db.Get(ConfigPath)
+	assert.Equal(t, txn.GetReconfig().GetConfig(), node.Data)
+	assert.Equal(t, int64(1), node.Version)
+
+	// Other types of transactions should be rejected.
+	err = db.Reconfig(&pbzk.Transaction{Txn: &pbzk.Transaction_Delete{}})
+	assert.Error(t, err)
+}
+
+// TestNewDBWithConfig verifies that the config node starts with the ensemble config and the given ACL.
+func TestNewDBWithConfig(t *testing.T) {
+	config := []byte("server.1=zk1:2888:3888:participant\nversion=0\n")
+	acl := []*pbzk.ACL{{Perms: int32(pbzk.Permission_PERMISSION_ALL), Id: &pbzk.Id{Scheme: "digest", Id: "admin:hash"}}}
+	db := NewDBWithConfig(config, acl)
+
+	node := db.Get(ConfigPath)
+	require.NotNil(t, node)
+	assert.Equal(t, config, node.Data)
+	assert.Equal(t, acl, node.ACL)
+
+	// Without a config, nobody can change the config node.
+	node = NewDB().Get(ConfigPath)
+	require.NotNil(t, node)
+	assert.Equal(t, ReadACLUnsafe, node.ACL)
+}
+
+// TestDB_Digest verifies that the digest only depends on the data in the tree.
+func TestDB_Digest(t *testing.T) {
+	createTxn := func(path string, data string) *pbzk.Transaction {
+		return &pbzk.Transaction{
+			ClientId: path,
+			Txn: &pbzk.Transaction_Create{
+				Create: &pbzk.CreateTxn{
+					Path: path,
+					Data: []byte(data),
+				},
+			},
+		}
+	}
+
+	// Create the same nodes in a different order.
+	db1 := NewDB()
+	db2 := NewDB()
+	for _, path := range []string{"/a", "/b", "/a/c"} {
+		_, err := db1.Create(createTxn(path, "data"))
+		require.NoError(t, err)
+	}
+	for _, path := range []string{"/b", "/a", "/a/c"} {
+		_, err := db2.Create(createTxn(path, "data"))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, db1.Digest(), db2.Digest())
+
+	// Any change to the data should change the digest.
+	err := db2.SetData(&pbzk.Transaction{
+		Txn: &pbzk.Transaction_SetData{
+			SetData: &pbzk.SetDataTxn{
+				Path: "/a/c",
+				Data: []byte("data"),
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, db1.Digest(), db2.Digest())
+}
+
+// TestDB_CloseSession verifies that we only delete the ephemeral nodes of the session being closed.
+func TestDB_CloseSession(t *testing.T) {
+	createTxn := func(clientID string, path string, ephemeral bool) *pbzk.Transaction {
+		return &pbzk.Transaction{
+			ClientId: clientID,
+			Txn: &pbzk.Transaction_Create{
+				Create: &pbzk.CreateTxn{
+					Path:      path,
+					Ephemeral: ephemeral,
+				},
+			},
+		}
+	}
+
+	db := NewDB()
+	for _, txn := range []*pbzk.Transaction{
+		createTxn("client1", "/a", false),
+		createTxn("client1", "/a/b", true),
+		createTxn("client1", "/c", true),
+		createTxn("client1", "/d", false),
+		createTxn("client2", "/e", true),
+	} {
+		_, err := db.Create(txn)
+		require.NoError(t, err)
+	}
+
+	deleted, err := db.CloseSession(&pbzk.Transaction{
+		ClientId: "client1",
+		Txn: &pbzk.Transaction_CloseSession{
+			CloseSession: &pbzk.CloseSessionTxn{},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/a/b", "/c"}, deleted)
+	assert.Nil(t, db.Get("/a/b"))
+	assert.Nil(t, db.Get("/c"))
+	assert.NotNil(t, db.Get("/a"))
+	assert.NotNil(t, db.Get("/d"))
+	assert.NotNil(t, db.Get("/e"))
+
+	// Other types of transactions should be rejected.
+	_, err = db.CloseSession(&pbzk.Transaction{Txn: &pbzk.Transaction_Delete{}})
+	assert.Error(t, err)
+}
+
+func TestDB_SetACL(t *testing.T) {
+	db := NewDB()
+	_, err := db.Create(&pbzk.Transaction{
+		Txn: &pbzk.Transaction_Create{
+			Create: &pbzk.CreateTxn{Path: "/a"},
+		},
+	})
+	require.NoError(t, err)
+	// Nodes created without an ACL are open to everyone.
+	assert.Equal(t, OpenACLUnsafe, db.Get("/a").ACL)
+
+	before := db.Digest()
+	err = db.SetACL(&pbzk.Transaction{
+		Txn: &pbzk.Transaction_SetAcl{
+			SetAcl: &pbzk.SetACLTxn{Path: "/a", Acl: ReadACLUnsafe},
+		},
+	})
+	require.NoError(t, err)
+	node := db.Get("/a")
+	assert.Equal(t, ReadACLUnsafe, node.ACL)
+	assert.EqualValues(t, 1, node.ACLVersion)
+	// Changing the ACL doesn't change the version of the data.
+	assert.Zero(t, node.Version)
+	assert.NotEqual(t, before, db.Digest())
+
+	err = db.SetACL(&pbzk.Transaction{
+		Txn: &pbzk.Transaction_SetAcl{
+			SetAcl: &pbzk.SetACLTxn{Path: "/missing", Acl: ReadACLUnsafe},
+		},
+	})
+	assert.Error(t, err)
+	err = db.SetACL(&pbzk.Transaction{Txn: &pbzk.Transaction_Delete{}})
+	assert.Error(t, err)
+}
+
+// TestDB_Zxids verifies that nodes remember the transactions that created them, changed their data, and
+// changed their children.
+func TestDB_Zxids(t *testing.T) {
+	db := NewDB()
+	_, err := db.Create(&pbzk.Transaction{
+		Zxid: 1,
+		Txn:  &pbzk.Transaction_Create{Create: &pbzk.CreateTxn{Path: "/a"}},
+	})
+	require.NoError(t, err)
+	_, err = db.Create(&pbzk.Transaction{
+		Zxid: 2,
+		Txn:  &pbzk.Transaction_Create{Create: &pbzk.CreateTxn{Path: "/a/b"}},
+	})
+	require.NoError(t, err)
+	err = db.SetData(&pbzk.Transaction{
+		Zxid: 3,
+		Txn:  &pbzk.Transaction_SetData{SetData: &pbzk.SetDataTxn{Path: "/a", Data: []byte("data")}},
+	})
+	require.NoError(t, err)
+
+	a := db.Get("/a")
+	assert.EqualValues(t, 1, a.Czxid)
+	assert.EqualValues(t, 3, a.Mzxid)
+	assert.EqualValues(t, 2, a.Pzxid)
+	b := db.Get("/a/b")
+	assert.EqualValues(t, 2, b.Czxid)
+	assert.EqualValues(t, 2, b.Mzxid)
+	assert.EqualValues(t, 2, b.Pzxid)
+
+	err = db.Delete(&pbzk.Transaction{
+		Zxid: 4,
+		Txn:  &pbzk.Transaction_Delete{Delete: &pbzk.DeleteTxn{Path: "/a/b"}},
+	})
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, db.Get("/a").Pzxid)
+	assert.EqualValues(t, 3, db.Get("/a").Mzxid)
+}
+
+func TestDB_Quotas(t *testing.T) {
+	db := NewDB()
+	create := func(path string, data string) {
+		_, err := db.Create(&pbzk.Transaction{
+			Txn: &pbzk.Transaction_Create{
+				Create: &pbzk.CreateTxn{Path: path, Data: []byte(data)},
+			},
+		})
+		require.NoError(t, err)
+	}
+	create("/a", "12")
+	create("/a/b", "345")
+	create("/zookeeper/quota/a", "")
+	// The usage of the subtree is counted when the quota is set, including the root of the subtree.
+	create("/zookeeper/quota/a/zookeeper_limits", "count=3,bytesHardLimit=10")
+
+	usages := db.Quotas("/a/b")
+	require.Len(t, usages, 1)
+	assert.Equal(t, QuotaUsage{
+		Path:   "/a",
+		Limits: Quota{Count: 3, Bytes: -1, CountHardLimit: -1, BytesHardLimit: 10},
+		Count:  2,
+		Bytes:  5,
+	}, usages[0])
+	assert.Equal(t, []byte("count=2,bytes=5"), db.Get("/zookeeper/quota/a/zookeeper_stats").Data)
+
+	// Every write in the subtree updates the usage.
+	create("/a/c", "6")
+	err := db.SetData(&pbzk.Transaction{
+		Txn: &pbzk.Transaction_SetData{
+			SetData: &pbzk.SetDataTxn{Path: "/a/b", Data: []byte("3")},
+		},
+	})
+	require.NoError(t, err)
+	err = db.Delete(&pbzk.Transaction{
+		Txn: &pbzk.Transaction_Delete{
+			Delete: &pbzk.DeleteTxn{Path: "/a/c"},
+		},
+	})
+	require.NoError(t, err)
+	create("/other", "outside the quota")
+	usages = db.Quotas("/a")
+	require.Len(t, usages, 1)
+	assert.EqualValues(t, 2, usages[0].Count)
+	assert.EqualValues(t, 3, usages[0].Bytes)
+	assert.Equal(t, []byte("count=2,bytes=3"), db.Get("/zookeeper/quota/a/zookeeper_stats").Data)
+	assert.Empty(t, db.Quotas("/other"))
+
+	// Deleting the limits stops enforcing the quota.
+	err = db.Delete(&pbzk.Transaction{
+		Txn: &pbzk.Transaction_Delete{
+			Delete: &pbzk.DeleteTxn{Path: "/zookeeper/quota/a/zookeeper_limits"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, db.Quotas("/a/b"))
+	assert.Nil(t, db.Get("/zookeeper/quota/a/zookeeper_stats"))
+}
+
+func TestDB_ExpiredNodes(t *testing.T) {
+	db := NewDB()
+	create := func(path string, ttlMs int64, timestampMs int64) {
+		_, err := db.Create(&pbzk.Transaction{
+			TimestampMs: timestampMs,
+			Txn: &pbzk.Transaction_Create{
+				Create: &pbzk.CreateTxn{Path: path, TtlMs: ttlMs},
+			},
+		})
+		require.NoError(t, err)
+	}
+	create("/ttl", 100, 1000)
+	create("/parent", 100, 1000)
+	create("/parent/child", 0, 1000)
+	create("/standard", 0, 1000)
+	create("/modified", 100, 1000)
+	err := db.SetData(&pbzk.Transaction{
+		TimestampMs: 1050,
+		Txn: &pbzk.Transaction_SetData{
+			SetData: &pbzk.SetDataTxn{Path: "/modified", Data: []byte("data")},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ZNodeType_TTL, db.Get("/ttl").NodeType)
+
+	assert.Empty(t, expiredPaths(db.ExpiredNodes(1099, time.Hour)))
+	// Nodes with children never expire, and modifying a node restarts its TTL.
+	assert.Equal(t, []string{"/ttl"}, expiredPaths(db.ExpiredNodes(1100, time.Hour)))
+	assert.Equal(t, []string{"/modified", "/ttl"}, expiredPaths(db.ExpiredNodes(1150, time.Hour)))
+
+	_, err = db.Create(&pbzk.Transaction{
+		Txn: &pbzk.Transaction_Create{
+			Create: &pbzk.CreateTxn{Path: "/ephemeral", Ephemeral: true, TtlMs: 100},
+		},
+	})
+	assert.Error(t, err)
+}
+
+func TestDB_ExpiredNodes_Containers(t *testing.T) {
+	db := NewDB()
+	create := func(path string, container bool, timestampMs int64) {
+		_, err := db.Create(&pbzk.Transaction{
+			TimestampMs: timestampMs,
+			Txn: &pbzk.Transaction_Create{
+				Create: &pbzk.CreateTxn{Path: path, Container: container},
+			},
+		})
+		require.NoError(t, err)
+	}
+	create("/used", true, 1000)
+	create("/used/child", false, 1000)
+	create("/unused", true, 1000)
+	create("/standard", false, 1000)
+	assert.Equal(t, ZNodeType_CONTAINER, db.Get("/used").NodeType)
+	// Neither container has lost a child, and the unused one is still in its grace period.
+	assert.Empty(t, expiredPaths(db.ExpiredNodes(1500, time.Second)))
+
+	err := db.Delete(&pbzk.Transaction{
+		Txn: &pbzk.Transaction_Delete{
+			Delete: &pbzk.DeleteTxn{Path: "/used/child"},
+		},
+	})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, db.Get("/used").Cversion)
+	assert.Equal(t, []string{"/used"}, expiredPaths(db.ExpiredNodes(1500, time.Second)))
+	assert.Equal(t, []string{"/unused", "/used"}, expiredPaths(db.ExpiredNodes(2000, time.Second)))
+
+	_, err = db.Create(&pbzk.Transaction{
+		Txn: &pbzk.Transaction_Create{
+			Create: &pbzk.CreateTxn{Path: "/ephemeral", Ephemeral: true, Container: true},
+		},
+	})
+	assert.Error(t, err)
+}
+
+// expiredPaths returns the paths of the expired nodes.
+func expiredPaths(nodes []*ZNode) []string {
+	var paths []string
+	for _, node := range nodes {
+		paths = append(paths, node.Name)
+	}
+	return paths
+}
+
+// TestDB_Delete_Conditional verifies that nodes with children are never deleted, and that a conditional
+// delete only goes through if the node hasn't changed.
+func TestDB_Delete_Conditional(t *testing.T) {
+	db := NewDB()
+	for _, path := range []string{"/parent", "/parent/child", "/node"} {
+		_, err := db.Create(&pbzk.Transaction{
+			Txn: &pbzk.Transaction_Create{Create: &pbzk.CreateTxn{Path: path}},
+		})
+		require.NoError(t, err)
+	}
+	del := func(path string, cond *pbzk.DeleteCondition) error {
+		return db.Delete(&pbzk.Transaction{
+			Txn: &pbzk.Transaction_Delete{Delete: &pbzk.DeleteTxn{Path: path, IfUnchanged: cond}},
+		})
+	}
+
+	err := del("/parent", nil)
+	assert.ErrorIs(t, err, ErrNotEmpty)
+	assert.NotNil(t, db.Get("/parent/child"))
+	assertIndexed(t, db)
+
+	err = del("/node", &pbzk.DeleteCondition{Version: 0, Cversion: 1})
+	assert.ErrorIs(t, err, ErrNodeChanged)
+	err = del("/node", &pbzk.DeleteCondition{Version: 1, Cversion: 0})
+	assert.ErrorIs(t, err, ErrNodeChanged)
+	assert.NotNil(t, db.Get("/node"))
+
+	err = del("/node", &pbzk.DeleteCondition{Version: 0, Cversion: 0})
+	require.NoError(t, err)
+	assert.Nil(t, db.Get("/node"))
+}
+
+// assertIndexed verifies that the index has exactly the nodes in the tree.
+func assertIndexed(t *testing.T, db *DB) {
+	t.Helper()
+	var walk func(node *ZNode, count *int)
+	walk = func(node *ZNode, count *int) {
+		*count++
+		assert.Same(t, node, db.nodes[node.Name], "node [%s] isn't indexed", node.Name)
+		assert.Same(t, node, findZNode(db.root, splitPathIntoNodeNames(node.Name)))
+		for _, child := range node.Children {
+			walk(child, count)
+		}
+	}
+	count := 0
+	walk(db.root, &count)
+	assert.Len(t, db.nodes, count)
+}
+
+// TestDB_Index verifies that the index stays in sync with the tree as nodes are added and removed.
+func TestDB_Index(t *testing.T) {
+	db := NewDB()
+	assertIndexed(t, db)
+	assert.Equal(t, db.root.copy(), db.Get(""))
+	assert.NotNil(t, db.Get(ConfigPath))
+
+	for _, txn := range []*pbzk.Transaction{
+		{Txn: &pbzk.Transaction_Create{Create: &pbzk.CreateTxn{Path: "/a"}}},
+		{Txn: &pbzk.Transaction_Create{Create: &pbzk.CreateTxn{Path: "/a/b"}}},
+		{ClientId: "client", Txn: &pbzk.Transaction_Create{Create: &pbzk.CreateTxn{Path: "/a/b/c", Ephemeral: true}}},
+		{Txn: &pbzk.Transaction_Create{Create: &pbzk.CreateTxn{Path: "/a/seq-", Sequential: true}}},
+		// Setting a quota adds a stats node.
+		{Txn: &pbzk.Transaction_Create{Create: &pbzk.CreateTxn{Path: QuotaPath + "/a"}}},
+		{Txn: &pbzk.Transaction_Create{Create: &pbzk.CreateTxn{Path: QuotaPath + "/a/" + QuotaLimitsNode, Data: []byte("count=10")}}},
+	} {
+		_, err := db.Create(txn)
+		require.NoError(t, err)
+	}
+	assertIndexed(t, db)
+	assert.NotNil(t, db.Get("/a/seq-0000000001"))
+	assert.NotNil(t, db.Get(QuotaPath+"/a/"+QuotaStatsNode))
+	assert.Nil(t, db.Get("/a/missing"))
+	assert.Nil(t, db.Get("/a/b/c/d"))
+
+	_, err := db.CloseSession(&pbzk.Transaction{
+		ClientId: "client",
+		Txn:      &pbzk.Transaction_CloseSession{CloseSession: &pbzk.CloseSessionTxn{}},
+	})
+	require.NoError(t, err)
+	assert.Nil(t, db.Get("/a/b/c"))
+	assertIndexed(t, db)
+
+	for _, path := range []string{"/a/b", QuotaPath + "/a/" + QuotaLimitsNode} {
+		err = db.Delete(&pbzk.Transaction{
+			Txn: &pbzk.Transaction_Delete{Delete: &pbzk.DeleteTxn{Path: path}},
+		})
+		require.NoError(t, err)
+		assert.Nil(t, db.Get(path))
+	}
+	// Removing the quota removes its stats node too.
+	assert.Nil(t, db.Get(QuotaPath+"/a/"+QuotaStatsNode))
+	assertIndexed(t, db)
+}
+
+// TestDB_Get_Copy verifies that the nodes handed out by the DB don't change when the tree does.
+func TestDB_Get_Copy(t *testing.T) {
+	db := NewDB()
+	created, err := db.Create(&pbzk.Transaction{
+		Zxid: 1,
+		Txn:  &pbzk.Transaction_Create{Create: &pbzk.CreateTxn{Path: "/a", Data: []byte("before")}},
+	})
+	require.NoError(t, err)
+	node := db.Get("/a")
+	assert.Empty(t, node.Children)
+
+	_, err = db.Create(&pbzk.Transaction{
+		Zxid: 2,
+		Txn:  &pbzk.Transaction_Create{Create: &pbzk.CreateTxn{Path: "/a/b"}},
+	})
+	require.NoError(t, err)
+	err = db.SetData(&pbzk.Transaction{
+		Zxid: 3,
+		Txn:  &pbzk.Transaction_SetData{SetData: &pbzk.SetDataTxn{Path: "/a", Data: []byte("after")}},
+	})
+	require.NoError(t, err)
+	err = db.SetACL(&pbzk.Transaction{
+		Txn: &pbzk.Transaction_SetAcl{SetAcl: &pbzk.SetACLTxn{Path: "/a", Acl: ReadACLUnsafe}},
+	})
+	require.NoError(t, err)
+
+	for _, n := range []*ZNode{created, node} {
+		assert.Equal(t, []byte("before"), n.Data)
+		assert.Zero(t, n.Version)
+		assert.Zero(t, n.Cversion)
+		assert.EqualValues(t, 1, n.Pzxid)
+		assert.Equal(t, OpenACLUnsafe, n.ACL)
+		assert.Empty(t, n.Children)
+	}
+	node = db.Get("/a")
+	assert.Equal(t, []byte("after"), node.Data)
+	assert.EqualValues(t, 1, node.Version)
+	assert.EqualValues(t, 1, node.Cversion)
+	assert.Equal(t, ReadACLUnsafe, node.ACL)
+	assert.Empty(t, node.Children)
+}
+
+func TestDB_Children(t *testing.T) {
+	db := NewDB()
+	for _, path := range []string{"/a", "/a/c", "/a/b", "/a/b/d"} {
+		_, err := db.Create(&pbzk.Transaction{
+			Txn: &pbzk.Transaction_Create{Create: &pbzk.CreateTxn{Path: path}},
+		})
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected []string
+	}{
+		{
+			name:     "root",
+			path:     "",
+			expected: []string{"a", "zookeeper"},
+		},
+		{
+			name:     "sorted",
+			path:     "/a",
+			expected: []string{"b", "c"},
+		},
+		{
+			name:     "leaf",
+			path:     "/a/c",
+			expected: []string{},
+		},
+		{
+			name:     "missing",
+			path:     "/missing",
+			expected: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, db.Children(test.path))
+		})
+	}
+}
+
+// TestDB_ConcurrentReads reads nodes while they are being changed. Run it with -race to catch any reads of
+// the tree outside the lock.
+func TestDB_ConcurrentReads(t *testing.T) {
+	db := NewDB()
+	_, err := db.Create(&pbzk.Transaction{
+		Txn: &pbzk.Transaction_Create{Create: &pbzk.CreateTxn{Path: "/a"}},
+	})
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 100 {
+			child := fmt.Sprintf("/a/%d", i)
+			_, err := db.Create(&pbzk.Transaction{
+				Txn: &pbzk.Transaction_Create{Create: &pbzk.CreateTxn{Path: child}},
+			})
+			assert.NoError(t, err)
+			err = db.SetData(&pbzk.Transaction{
+				Txn: &pbzk.Transaction_SetData{SetData: &pbzk.SetDataTxn{Path: "/a", Data: []byte(child)}},
+			})
+			assert.NoError(t, err)
+			err = db.Delete(&pbzk.Transaction{
+				Txn: &pbzk.Transaction_Delete{Delete: &pbzk.DeleteTxn{Path: child}},
+			})
+			assert.NoError(t, err)
+		}
+	}()
+
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		node := db.Get("/a")
+		_ = len(node.Data) + int(node.Version) + int(node.Cversion) + len(node.ACL)
+		for _, name := range db.Children("/a") {
+			db.Get("/a/" + name)
+		}
+	}
+}
+
+// deepDB returns a DB with a single chain of nodes that is depth nodes deep, along with the path to the
+// deepest node.
+func deepDB(b *testing.B, depth int) (*DB, string) {
+	db := NewDB()
+	path := ""
+	for i := range depth {
+		path += fmt.Sprintf("/node%d", i)
+		_, err := db.Create(&pbzk.Transaction{
+			Txn: &pbzk.Transaction_Create{Create: &pbzk.CreateTxn{Path: path}},
+		})
+		require.NoError(b, err)
+	}
+	return db, path
+}
+
+// BenchmarkDB_Get looks up the deepest node in trees of different depths using the index.
+func BenchmarkDB_Get(b *testing.B) {
+	for _, depth := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			db, path := deepDB(b, depth)
+			b.ResetTimer()
+			for range b.N {
+				if db.Get(path) == nil {
+					b.Fatal("missing node")
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkDB_Get_TreeWalk looks up the same nodes as BenchmarkDB_Get by walking down from the root, which
+// is how every lookup worked before the index.
+func BenchmarkDB_Get_TreeWalk(b *testing.B) {
+	for _, depth := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			db, path := deepDB(b, depth)
+			b.ResetTimer()
+			for range b.N {
+				db.mu.RLock()
+				node := findZNode(db.root, splitPathIntoNodeNames(path))
+				db.mu.RUnlock()
+				if node == nil {
+					b.Fatal("missing node")
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkDB_CreateDelete creates and deletes a child of the deepest node in trees of different depths.
+func BenchmarkDB_CreateDelete(b *testing.B) {
+	for _, depth := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			db, path := deepDB(b, depth)
+			create := &pbzk.Transaction{
+				Txn: &pbzk.Transaction_Create{Create: &pbzk.CreateTxn{Path: path + "/leaf"}},
+			}
+			del := &pbzk.Transaction{
+				Txn: &pbzk.Transaction_Delete{Delete: &pbzk.DeleteTxn{Path: path + "/leaf"}},
+			}
+			b.ResetTimer()
+			for range b.N {
+				_, err := db.Create(create)
+				if err != nil {
+					b.Fatal(err)
+				}
+				err = db.Delete(del)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ package mock_znode
 
 import (
 	reflect "reflect"
+	time "time"
 
 	znode "github.com/mikekulinski/zookeeper/pkg/znode"
 	zookeeper "github.com/mikekulinski/zookeeper/proto"
@@ -40,6 +41,35 @@ func (m *MockZKDB) EXPECT() *MockZKDBMockRecorder {
 	return m.recorder
 }
 
+// Children mocks base method.
+func (m *MockZKDB) Children(arg0 string) []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Children", arg0)
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// Children indicates an expected call of Children.
+func (mr *MockZKDBMockRecorder) Children(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Children", reflect.TypeOf((*MockZKDB)(nil).Children), arg0)
+}
+
+// CloseSession mocks base method.
+func (m *MockZKDB) CloseSession(arg0 *zookeeper.Transaction) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSession", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloseSession indicates an expected call of CloseSession.
+func (mr *MockZKDBMockRecorder) CloseSession(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSession", reflect.TypeOf((*MockZKDB)(nil).CloseSession), arg0)
+}
+
 // Create mocks base method.
 func (m *MockZKDB) Create(arg0 *zookeeper.Transaction) (*znode.ZNode, error) {
 	m.ctrl.T.Helper()
@@ -69,6 +99,34 @@ func (mr *MockZKDBMockRecorder) Delete(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockZKDB)(nil).Delete), arg0)
 }
 
+// Digest mocks base method.
+func (m *MockZKDB) Digest() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Digest")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// Digest indicates an expected call of Digest.
+func (mr *MockZKDBMockRecorder) Digest() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Digest", reflect.TypeOf((*MockZKDB)(nil).Digest))
+}
+
+// ExpiredNodes mocks base method.
+func (m *MockZKDB) ExpiredNodes(arg0 int64, arg1 time.Duration) []*znode.ZNode {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpiredNodes", arg0, arg1)
+	ret0, _ := ret[0].([]*znode.ZNode)
+	return ret0
+}
+
+// ExpiredNodes indicates an expected call of ExpiredNodes.
+func (mr *MockZKDBMockRecorder) ExpiredNodes(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpiredNodes", reflect.TypeOf((*MockZKDB)(nil).ExpiredNodes), arg0, arg1)
+}
+
 // Get mocks base method.
 func (m *MockZKDB) Get(arg0 string) *znode.ZNode {
 	m.ctrl.T.Helper()
@@ -83,6 +141,48 @@ func (mr *MockZKDBMockRecorder) Get(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockZKDB)(nil).Get), arg0)
 }
 
+// Quotas mocks base method.
+func (m *MockZKDB) Quotas(arg0 string) []znode.QuotaUsage {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Quotas", arg0)
+	ret0, _ := ret[0].([]znode.QuotaUsage)
+	return ret0
+}
+
+// Quotas indicates an expected call of Quotas.
+func (mr *MockZKDBMockRecorder) Quotas(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Quotas", reflect.TypeOf((*MockZKDB)(nil).Quotas), arg0)
+}
+
+// Reconfig mocks base method.
+func (m *MockZKDB) Reconfig(arg0 *zookeeper.Transaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reconfig", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reconfig indicates an expected call of Reconfig.
+func (mr *MockZKDBMockRecorder) Reconfig(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconfig", reflect.TypeOf((*MockZKDB)(nil).Reconfig), arg0)
+}
+
+// SetACL mocks base method.
+func (m *MockZKDB) SetACL(arg0 *zookeeper.Transaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetACL", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetACL indicates an expected call of SetACL.
+func (mr *MockZKDBMockRecorder) SetACL(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetACL", reflect.TypeOf((*MockZKDB)(nil).SetACL), arg0)
+}
+
 // SetData mocks base method.
 func (m *MockZKDB) SetData(arg0 *zookeeper.Transaction) error {
 	m.ctrl.T.Helper()
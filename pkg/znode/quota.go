@@ -0,0 +1,124 @@
+package znode
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// QuotaPath is the root of the subtree where quotas are configured. The quota for the subtree at /a/b is
+	// set by writing its limits to /zookeeper/quota/a/b/zookeeper_limits, the same as ZooKeeper.
+	QuotaPath = ZookeeperPath + "/quota"
+	// QuotaLimitsNode holds the limits of a quota. i.e. "count=10,bytes=-1,countHardLimit=20,bytesHardLimit=-1"
+	QuotaLimitsNode = "zookeeper_limits"
+	// QuotaStatsNode is kept up to date by the server with the usage of the subtree. i.e. "count=3,bytes=12"
+	QuotaStatsNode = "zookeeper_stats"
+)
+
+// ErrQuotaExceeded is returned when a request would go over the hard limit of a quota.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// Quota is the limits on the number of nodes in a subtree, and the bytes of data stored in them. The subtree
+// includes the node at its root. Limits of -1 are not enforced.
+type Quota struct {
+	// Count and Bytes are soft limits. Going over them is logged, but allowed.
+	Count int64
+	Bytes int64
+	// CountHardLimit and BytesHardLimit can't be gone over. Requests that would are rejected.
+	CountHardLimit int64
+	BytesHardLimit int64
+}
+
+// ParseQuota parses the data of a limits node. Any limit that isn't set is -1.
+func ParseQuota(data []byte) (Quota, error) {
+	q := Quota{Count: -1, Bytes: -1, CountHardLimit: -1, BytesHardLimit: -1}
+	if len(data) == 0 {
+		return q, fmt.Errorf("quota limits cannot be empty")
+	}
+	for _, field := range strings.Split(string(data), ",") {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return q, fmt.Errorf("invalid quota field [%s]: expected key=value", field)
+		}
+		limit, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || limit < -1 {
+			return q, fmt.Errorf("invalid quota %s [%s]: expected a number or -1", key, value)
+		}
+		switch key {
+		case "count":
+			q.Count = limit
+		case "bytes":
+			q.Bytes = limit
+		case "countHardLimit":
+			q.CountHardLimit = limit
+		case "bytesHardLimit":
+			q.BytesHardLimit = limit
+		default:
+			return q, fmt.Errorf("unknown quota field [%s]", key)
+		}
+	}
+	return q, nil
+}
+
+func (q Quota) String() string {
+	return fmt.Sprintf("count=%d,bytes=%d,countHardLimit=%d,bytesHardLimit=%d", q.Count, q.Bytes, q.CountHardLimit, q.BytesHardLimit)
+}
+
+// QuotaUsage is how much of its quota a subtree is using.
+type QuotaUsage struct {
+	// Path is the root of the subtree.
+	Path   string
+	Limits Quota
+	Count  int64
+	Bytes  int64
+}
+
+// Check returns an error if adding countDelta nodes and bytesDelta bytes to the subtree would go over a hard
+// limit. Otherwise, it returns whether it would go over a soft limit.
+func (u QuotaUsage) Check(countDelta int64, bytesDelta int64) (bool, error) {
+	count, bytes := u.Count+countDelta, u.Bytes+bytesDelta
+	if countDelta > 0 && u.Limits.CountHardLimit >= 0 && count > u.Limits.CountHardLimit {
+		return false, fmt.Errorf("%w: [%s] would have [%d] nodes, which is more than the limit of [%d]", ErrQuotaExceeded, u.Path, count, u.Limits.CountHardLimit)
+	}
+	if bytesDelta > 0 && u.Limits.BytesHardLimit >= 0 && bytes > u.Limits.BytesHardLimit {
+		return false, fmt.Errorf("%w: [%s] would have [%d] bytes, which is more than the limit of [%d]", ErrQuotaExceeded, u.Path, bytes, u.Limits.BytesHardLimit)
+	}
+	soft := (countDelta > 0 && u.Limits.Count >= 0 && count > u.Limits.Count) ||
+		(bytesDelta > 0 && u.Limits.Bytes >= 0 && bytes > u.Limits.Bytes)
+	return soft, nil
+}
+
+func (u QuotaUsage) statsData() []byte {
+	return []byte(fmt.Sprintf("count=%d,bytes=%d", u.Count, u.Bytes))
+}
+
+// QuotaTarget returns the root of the subtree that the limits node at path sets the quota for. This returns
+// false if path isn't a limits node.
+func QuotaTarget(path string) (string, bool) {
+	target, ok := strings.CutPrefix(path, QuotaPath)
+	if !ok {
+		return "", false
+	}
+	target, ok = strings.CutSuffix(target, "/"+QuotaLimitsNode)
+	if !ok || target == "" {
+		return "", false
+	}
+	return target, true
+}
+
+// isQuotaStats returns whether path is a stats node, which only the server is allowed to write.
+func isQuotaStats(path string) bool {
+	return strings.HasPrefix(path, QuotaPath+"/") && strings.HasSuffix(path, "/"+QuotaStatsNode)
+}
+
+// IsReserved returns whether the node at path stores Zookeeper's own metadata, and can't be modified by clients.
+func IsReserved(path string) bool {
+	return path == ZookeeperPath || path == ConfigPath || path == QuotaPath || isQuotaStats(path)
+}
+
+// quotaCovers returns whether the quota of the subtree at root counts the node at path.
+func quotaCovers(root string, path string) bool {
+	return path == root || strings.HasPrefix(path, root+"/")
+}
@@ -0,0 +1,158 @@
+package znode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuota(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		expected      Quota
+		errorExpected bool
+	}{
+		{
+			name:     "every limit",
+			data:     "count=1,bytes=2,countHardLimit=3,bytesHardLimit=4",
+			expected: Quota{Count: 1, Bytes: 2, CountHardLimit: 3, BytesHardLimit: 4},
+		},
+		{
+			name:     "unset limits",
+			data:     "countHardLimit=10",
+			expected: Quota{Count: -1, Bytes: -1, CountHardLimit: 10, BytesHardLimit: -1},
+		},
+		{
+			name:          "empty",
+			data:          "",
+			errorExpected: true,
+		},
+		{
+			name:          "missing equals",
+			data:          "count",
+			errorExpected: true,
+		},
+		{
+			name:          "not a number",
+			data:          "count=ten",
+			errorExpected: true,
+		},
+		{
+			name:          "negative",
+			data:          "bytes=-2",
+			errorExpected: true,
+		},
+		{
+			name:          "unknown field",
+			data:          "nodes=10",
+			errorExpected: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := ParseQuota([]byte(test.data))
+			if test.errorExpected {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, q)
+			// The string form can be parsed back.
+			parsed, err := ParseQuota([]byte(q.String()))
+			assert.NoError(t, err)
+			assert.Equal(t, q, parsed)
+		})
+	}
+}
+
+func TestQuotaUsage_Check(t *testing.T) {
+	usage := QuotaUsage{
+		Path:   "/a",
+		Limits: Quota{Count: 2, Bytes: 10, CountHardLimit: 3, BytesHardLimit: 20},
+		Count:  2,
+		Bytes:  10,
+	}
+	tests := []struct {
+		name          string
+		countDelta    int64
+		bytesDelta    int64
+		soft          bool
+		errorExpected bool
+	}{
+		{
+			name: "no change",
+		},
+		{
+			name:       "shrinking is always allowed",
+			countDelta: -1,
+			bytesDelta: -5,
+		},
+		{
+			name:       "over the soft count",
+			countDelta: 1,
+			soft:       true,
+		},
+		{
+			name:       "over the soft bytes",
+			bytesDelta: 10,
+			soft:       true,
+		},
+		{
+			name:          "over the hard count",
+			countDelta:    2,
+			errorExpected: true,
+		},
+		{
+			name:          "over the hard bytes",
+			bytesDelta:    11,
+			errorExpected: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			soft, err := usage.Check(test.countDelta, test.bytesDelta)
+			if test.errorExpected {
+				assert.ErrorIs(t, err, ErrQuotaExceeded)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.soft, soft)
+		})
+	}
+}
+
+func TestQuotaTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		target   string
+		expected bool
+	}{
+		{
+			name:     "limits node",
+			path:     "/zookeeper/quota/a/b/zookeeper_limits",
+			target:   "/a/b",
+			expected: true,
+		},
+		{
+			name: "stats node",
+			path: "/zookeeper/quota/a/b/zookeeper_stats",
+		},
+		{
+			name: "limits of the root",
+			path: "/zookeeper/quota/zookeeper_limits",
+		},
+		{
+			name: "outside the quota subtree",
+			path: "/a/b/zookeeper_limits",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			target, ok := QuotaTarget(test.path)
+			assert.Equal(t, test.expected, ok)
+			assert.Equal(t, test.target, target)
+		})
+	}
+}
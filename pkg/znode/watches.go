@@ -0,0 +1,278 @@
+package znode
+
+import (
+	"sort"
+	"sync"
+
+	pbzk "github.com/mikekulinski/zookeeper/proto"
+)
+
+// WatchKind is what set a watch, which decides the events it fires for.
+type WatchKind int
+
+const (
+	// WatchKind_DATA watches are set by exists and getData. They fire once, when the node is created, changed,
+	// or deleted.
+	WatchKind_DATA WatchKind = iota
+	// WatchKind_CHILDREN watches are set by getChildren. They fire once, when the children of the node change
+	// or the node is deleted.
+	WatchKind_CHILDREN
+	// WatchKind_PERSISTENT watches fire for every event on the node until they are removed.
+	WatchKind_PERSISTENT
+	// WatchKind_PERSISTENT_RECURSIVE watches fire for every create, delete, and data change of the node and its
+	// descendants until they are removed.
+	WatchKind_PERSISTENT_RECURSIVE
+)
+
+// AllWatchKinds is every kind of watch.
+var AllWatchKinds = []WatchKind{WatchKind_DATA, WatchKind_CHILDREN, WatchKind_PERSISTENT, WatchKind_PERSISTENT_RECURSIVE}
+
+func (k WatchKind) String() string {
+	switch k {
+	case WatchKind_DATA:
+		return "DATA"
+	case WatchKind_CHILDREN:
+		return "CHILDREN"
+	case WatchKind_PERSISTENT:
+		return "PERSISTENT"
+	case WatchKind_PERSISTENT_RECURSIVE:
+		return "PERSISTENT_RECURSIVE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Persistent returns whether watches of this kind stay after they fire.
+func (k WatchKind) Persistent() bool {
+	return k == WatchKind_PERSISTENT || k == WatchKind_PERSISTENT_RECURSIVE
+}
+
+// fires returns whether a watch of this kind on a node fires for the event on the same node.
+func (k WatchKind) fires(eventType pbzk.WatchEvent_EventType) bool {
+	switch k {
+	case WatchKind_DATA:
+		return eventType == pbzk.WatchEvent_EVENT_TYPE_ZNODE_CREATED ||
+			eventType == pbzk.WatchEvent_EVENT_TYPE_ZNODE_DATA_CHANGED ||
+			eventType == pbzk.WatchEvent_EVENT_TYPE_ZNODE_DELETED
+	case WatchKind_CHILDREN:
+		return eventType == pbzk.WatchEvent_EVENT_TYPE_ZNODE_CHILDREN_CHANGED ||
+			eventType == pbzk.WatchEvent_EVENT_TYPE_ZNODE_DELETED
+	case WatchKind_PERSISTENT:
+		return true
+	case WatchKind_PERSISTENT_RECURSIVE:
+		// Recursive watches get the create or delete of each child, so they don't need to know the children changed.
+		return eventType != pbzk.WatchEvent_EVENT_TYPE_ZNODE_CHILDREN_CHANGED
+	default:
+		return false
+	}
+}
+
+// watchKinds is a set of watch kinds.
+type watchKinds uint8
+
+func (ks watchKinds) has(k WatchKind) bool {
+	return ks&(1<<k) != 0
+}
+
+// Watch is a single watch that a client has set on a path.
+type Watch struct {
+	ClientID string
+	Path     string
+	Kind     WatchKind
+}
+
+// WatchStats counts the watches in a WatchManager.
+type WatchStats struct {
+	// Sessions is the number of sessions with at least one watch.
+	Sessions int
+	// Paths is the number of paths with at least one watch.
+	Paths int
+	// Watches is the total number of watches.
+	Watches int
+	// ByKind is the number of watches of each kind.
+	ByKind map[WatchKind]int
+}
+
+// WatchManager keeps track of the watches that every client has set. Watches are indexed both by path, to find
+// the ones an event triggers, and by session, to remove them all when the session closes. A client has at
+// most one watch of each kind on a path, so setting the same watch twice only makes it fire once.
+type WatchManager struct {
+	mu *sync.Mutex
+	// byPath is the kinds of watches each client has on each path.
+	byPath map[string]map[string]watchKinds
+	// bySession is the paths each client has watches on.
+	bySession map[string]map[string]struct{}
+	// counts is the number of watches of each kind.
+	counts map[WatchKind]int
+}
+
+func NewWatchManager() *WatchManager {
+	return &WatchManager{
+		mu:        &sync.Mutex{},
+		byPath:    map[string]map[string]watchKinds{},
+		bySession: map[string]map[string]struct{}{},
+		counts:    map[WatchKind]int{},
+	}
+}
+
+// Add sets a watch for the client. It returns false if the client already had the same watch.
+func (m *WatchManager) Add(clientID string, path string, kind WatchKind) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	watchers, ok := m.byPath[path]
+	if !ok {
+		watchers = map[string]watchKinds{}
+		m.byPath[path] = watchers
+	}
+	if watchers[clientID].has(kind) {
+		return false
+	}
+	watchers[clientID] |= 1 << kind
+	paths, ok := m.bySession[clientID]
+	if !ok {
+		paths = map[string]struct{}{}
+		m.bySession[clientID] = paths
+	}
+	paths[path] = struct{}{}
+	m.counts[kind]++
+	return true
+}
+
+// Remove removes the client's watches of the given kinds on the path. It returns false if the client didn't
+// have any of them.
+func (m *WatchManager) Remove(clientID string, path string, kinds ...WatchKind) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var toRemove watchKinds
+	for _, kind := range kinds {
+		toRemove |= 1 << kind
+	}
+	return m.removeLocked(clientID, path, toRemove)
+}
+
+// removeLocked removes the kinds of watches that the client has on the path, and returns whether there were any.
+func (m *WatchManager) removeLocked(clientID string, path string, toRemove watchKinds) bool {
+	existing := m.byPath[path][clientID]
+	removed := existing & toRemove
+	if removed == 0 {
+		return false
+	}
+	for _, kind := range AllWatchKinds {
+		if removed.has(kind) {
+			m.counts[kind]--
+		}
+	}
+
+	remaining := existing &^ toRemove
+	if remaining != 0 {
+		m.byPath[path][clientID] = remaining
+		return true
+	}
+	delete(m.byPath[path], clientID)
+	if len(m.byPath[path]) == 0 {
+		delete(m.byPath, path)
+	}
+	delete(m.bySession[clientID], path)
+	if len(m.bySession[clientID]) == 0 {
+		delete(m.bySession, clientID)
+	}
+	return true
+}
+
+// RemoveSession removes every watch the client has set. This only looks at the paths the client is watching.
+func (m *WatchManager) RemoveSession(clientID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for path := range m.bySession[clientID] {
+		m.removeLocked(clientID, path, ^watchKinds(0))
+	}
+}
+
+// Trigger returns the clients to notify of the event on the node at path, in sorted order. A client with several
+// watches that fire is only returned once. The watches that only fire once are removed.
+func (m *WatchManager) Trigger(path string, eventType pbzk.WatchEvent_EventType) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	notify := map[string]struct{}{}
+	for clientID, kinds := range m.byPath[path] {
+		var fired watchKinds
+		for _, kind := range AllWatchKinds {
+			if kinds.has(kind) && kind.fires(eventType) {
+				fired |= 1 << kind
+			}
+		}
+		if fired == 0 {
+			continue
+		}
+		notify[clientID] = struct{}{}
+		oneShot := fired &^ (1<<WatchKind_PERSISTENT | 1<<WatchKind_PERSISTENT_RECURSIVE)
+		if oneShot != 0 {
+			m.removeLocked(clientID, path, oneShot)
+		}
+	}
+
+	// Recursive watches on any ancestor also fire. Most trees don't have any, so skip walking up the tree.
+	if m.counts[WatchKind_PERSISTENT_RECURSIVE] > 0 && WatchKind_PERSISTENT_RECURSIVE.fires(eventType) {
+		for ancestor := path; ancestor != ""; {
+			ancestor = parentPath(ancestor)
+			for clientID, kinds := range m.byPath[ancestor] {
+				if kinds.has(WatchKind_PERSISTENT_RECURSIVE) {
+					notify[clientID] = struct{}{}
+				}
+			}
+		}
+	}
+
+	clientIDs := make([]string, 0, len(notify))
+	for clientID := range notify {
+		clientIDs = append(clientIDs, clientID)
+	}
+	sort.Strings(clientIDs)
+	return clientIDs
+}
+
+// Watches returns every watch the client has set, sorted by path and then kind.
+func (m *WatchManager) Watches(clientID string) []Watch {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var watches []Watch
+	for path := range m.bySession[clientID] {
+		kinds := m.byPath[path][clientID]
+		for _, kind := range AllWatchKinds {
+			if kinds.has(kind) {
+				watches = append(watches, Watch{ClientID: clientID, Path: path, Kind: kind})
+			}
+		}
+	}
+	sort.Slice(watches, func(i, j int) bool {
+		if watches[i].Path != watches[j].Path {
+			return watches[i].Path < watches[j].Path
+		}
+		return watches[i].Kind < watches[j].Kind
+	})
+	return watches
+}
+
+// Stats counts the watches.
+func (m *WatchManager) Stats() WatchStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := WatchStats{
+		Sessions: len(m.bySession),
+		Paths:    len(m.byPath),
+		ByKind:   map[WatchKind]int{},
+	}
+	for kind, count := range m.counts {
+		stats.Watches += count
+		if count > 0 {
+			stats.ByKind[kind] = count
+		}
+	}
+	return stats
+}
@@ -0,0 +1,209 @@
+package znode
+
+import (
+	"testing"
+
+	pbzk "github.com/mikekulinski/zookeeper/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchManager_Add(t *testing.T) {
+	m := NewWatchManager()
+	assert.True(t, m.Add("a", "/zoo", WatchKind_DATA))
+	// Setting the same watch again doesn't add another one.
+	assert.False(t, m.Add("a", "/zoo", WatchKind_DATA))
+	assert.True(t, m.Add("a", "/zoo", WatchKind_CHILDREN))
+	assert.True(t, m.Add("b", "/zoo", WatchKind_DATA))
+
+	assert.Equal(t, []Watch{
+		{ClientID: "a", Path: "/zoo", Kind: WatchKind_DATA},
+		{ClientID: "a", Path: "/zoo", Kind: WatchKind_CHILDREN},
+	}, m.Watches("a"))
+	assert.Equal(t, WatchStats{
+		Sessions: 2,
+		Paths:    1,
+		Watches:  3,
+		ByKind:   map[WatchKind]int{WatchKind_DATA: 2, WatchKind_CHILDREN: 1},
+	}, m.Stats())
+}
+
+func TestWatchManager_Remove(t *testing.T) {
+	tests := []struct {
+		name      string
+		kinds     []WatchKind
+		removed   bool
+		remaining []WatchKind
+	}{
+		{
+			name:      "one kind",
+			kinds:     []WatchKind{WatchKind_DATA},
+			removed:   true,
+			remaining: []WatchKind{WatchKind_CHILDREN, WatchKind_PERSISTENT},
+		},
+		{
+			name:      "several kinds",
+			kinds:     []WatchKind{WatchKind_CHILDREN, WatchKind_PERSISTENT},
+			removed:   true,
+			remaining: []WatchKind{WatchKind_DATA},
+		},
+		{
+			name:    "every kind",
+			kinds:   AllWatchKinds,
+			removed: true,
+		},
+		{
+			name:      "kind that isn't set",
+			kinds:     []WatchKind{WatchKind_PERSISTENT_RECURSIVE},
+			removed:   false,
+			remaining: []WatchKind{WatchKind_DATA, WatchKind_CHILDREN, WatchKind_PERSISTENT},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := NewWatchManager()
+			m.Add("a", "/zoo", WatchKind_DATA)
+			m.Add("a", "/zoo", WatchKind_CHILDREN)
+			m.Add("a", "/zoo", WatchKind_PERSISTENT)
+			m.Add("b", "/zoo", WatchKind_DATA)
+
+			assert.Equal(t, test.removed, m.Remove("a", "/zoo", test.kinds...))
+			var remaining []WatchKind
+			for _, w := range m.Watches("a") {
+				remaining = append(remaining, w.Kind)
+			}
+			assert.Equal(t, test.remaining, remaining)
+			// Another client's watches are never removed.
+			assert.Len(t, m.Watches("b"), 1)
+			assert.Equal(t, len(test.remaining)+1, m.Stats().Watches)
+		})
+	}
+}
+
+func TestWatchManager_RemoveSession(t *testing.T) {
+	m := NewWatchManager()
+	m.Add("a", "/zoo", WatchKind_DATA)
+	m.Add("a", "/zoo/lion", WatchKind_CHILDREN)
+	m.Add("a", "", WatchKind_PERSISTENT_RECURSIVE)
+	m.Add("b", "/zoo", WatchKind_DATA)
+
+	m.RemoveSession("a")
+	assert.Empty(t, m.Watches("a"))
+	assert.Equal(t, WatchStats{
+		Sessions: 1,
+		Paths:    1,
+		Watches:  1,
+		ByKind:   map[WatchKind]int{WatchKind_DATA: 1},
+	}, m.Stats())
+	// The recursive watch is gone, so it doesn't fire anymore.
+	assert.Empty(t, m.Trigger("/zoo/lion", pbzk.WatchEvent_EVENT_TYPE_ZNODE_DATA_CHANGED))
+}
+
+func TestWatchManager_Trigger(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		eventType pbzk.WatchEvent_EventType
+		notified  []string
+		remaining []Watch
+	}{
+		{
+			name:      "data changed",
+			path:      "/zoo",
+			eventType: pbzk.WatchEvent_EVENT_TYPE_ZNODE_DATA_CHANGED,
+			notified:  []string{"data", "persistent", "recursive", "root"},
+			remaining: []Watch{
+				{ClientID: "children", Path: "/zoo", Kind: WatchKind_CHILDREN},
+				{ClientID: "persistent", Path: "/zoo", Kind: WatchKind_PERSISTENT},
+				{ClientID: "recursive", Path: "/zoo", Kind: WatchKind_PERSISTENT_RECURSIVE},
+				{ClientID: "root", Path: "", Kind: WatchKind_PERSISTENT_RECURSIVE},
+			},
+		},
+		{
+			name:      "children changed",
+			path:      "/zoo",
+			eventType: pbzk.WatchEvent_EVENT_TYPE_ZNODE_CHILDREN_CHANGED,
+			notified:  []string{"children", "persistent"},
+			remaining: []Watch{
+				{ClientID: "data", Path: "/zoo", Kind: WatchKind_DATA},
+				{ClientID: "persistent", Path: "/zoo", Kind: WatchKind_PERSISTENT},
+				{ClientID: "recursive", Path: "/zoo", Kind: WatchKind_PERSISTENT_RECURSIVE},
+				{ClientID: "root", Path: "", Kind: WatchKind_PERSISTENT_RECURSIVE},
+			},
+		},
+		{
+			name:      "deleted",
+			path:      "/zoo",
+			eventType: pbzk.WatchEvent_EVENT_TYPE_ZNODE_DELETED,
+			notified:  []string{"children", "data", "persistent", "recursive", "root"},
+			remaining: []Watch{
+				{ClientID: "persistent", Path: "/zoo", Kind: WatchKind_PERSISTENT},
+				{ClientID: "recursive", Path: "/zoo", Kind: WatchKind_PERSISTENT_RECURSIVE},
+				{ClientID: "root", Path: "", Kind: WatchKind_PERSISTENT_RECURSIVE},
+			},
+		},
+		{
+			name:      "descendant created",
+			path:      "/zoo/lion/cub",
+			eventType: pbzk.WatchEvent_EVENT_TYPE_ZNODE_CREATED,
+			notified:  []string{"recursive", "root"},
+			remaining: []Watch{
+				{ClientID: "children", Path: "/zoo", Kind: WatchKind_CHILDREN},
+				{ClientID: "data", Path: "/zoo", Kind: WatchKind_DATA},
+				{ClientID: "persistent", Path: "/zoo", Kind: WatchKind_PERSISTENT},
+				{ClientID: "recursive", Path: "/zoo", Kind: WatchKind_PERSISTENT_RECURSIVE},
+				{ClientID: "root", Path: "", Kind: WatchKind_PERSISTENT_RECURSIVE},
+			},
+		},
+		{
+			name:      "unwatched node",
+			path:      "/aquarium",
+			eventType: pbzk.WatchEvent_EVENT_TYPE_ZNODE_DATA_CHANGED,
+			notified:  []string{"root"},
+			remaining: []Watch{
+				{ClientID: "children", Path: "/zoo", Kind: WatchKind_CHILDREN},
+				{ClientID: "data", Path: "/zoo", Kind: WatchKind_DATA},
+				{ClientID: "persistent", Path: "/zoo", Kind: WatchKind_PERSISTENT},
+				{ClientID: "recursive", Path: "/zoo", Kind: WatchKind_PERSISTENT_RECURSIVE},
+				{ClientID: "root", Path: "", Kind: WatchKind_PERSISTENT_RECURSIVE},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := NewWatchManager()
+			m.Add("data", "/zoo", WatchKind_DATA)
+			m.Add("children", "/zoo", WatchKind_CHILDREN)
+			m.Add("persistent", "/zoo", WatchKind_PERSISTENT)
+			m.Add("recursive", "/zoo", WatchKind_PERSISTENT_RECURSIVE)
+			m.Add("root", "", WatchKind_PERSISTENT_RECURSIVE)
+
+			assert.Equal(t, test.notified, m.Trigger(test.path, test.eventType))
+			var remaining []Watch
+			for _, clientID := range []string{"children", "data", "persistent", "recursive", "root"} {
+				remaining = append(remaining, m.Watches(clientID)...)
+			}
+			assert.Equal(t, test.remaining, remaining)
+		})
+	}
+}
+
+// TestWatchManager_Trigger_SameClient verifies that a client with several watches that fire is notified once,
+// and only the watches that fired are removed.
+func TestWatchManager_Trigger_SameClient(t *testing.T) {
+	m := NewWatchManager()
+	m.Add("a", "/zoo", WatchKind_DATA)
+	m.Add("a", "/zoo", WatchKind_CHILDREN)
+	m.Add("a", "/zoo", WatchKind_PERSISTENT)
+
+	assert.Equal(t, []string{"a"}, m.Trigger("/zoo", pbzk.WatchEvent_EVENT_TYPE_ZNODE_DATA_CHANGED))
+	assert.Equal(t, []Watch{
+		{ClientID: "a", Path: "/zoo", Kind: WatchKind_CHILDREN},
+		{ClientID: "a", Path: "/zoo", Kind: WatchKind_PERSISTENT},
+	}, m.Watches("a"))
+	assert.Equal(t, WatchStats{
+		Sessions: 1,
+		Paths:    1,
+		Watches:  2,
+		ByKind:   map[WatchKind]int{WatchKind_CHILDREN: 1, WatchKind_PERSISTENT: 1},
+	}, m.Stats())
+}
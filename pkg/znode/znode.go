@@ -1,28 +1,73 @@
 package znode
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	pbzk "github.com/mikekulinski/zookeeper/proto"
 )
 
+const (
+	// ZookeeperPath is the root of the subtree where Zookeeper stores its own metadata.
+	ZookeeperPath = "/zookeeper"
+	// ConfigPath stores the current membership of the ensemble. Clients can read and watch this node,
+	// but it can only be changed through a reconfig.
+	ConfigPath = ZookeeperPath + "/config"
+	// MaxSequenceNumber is the largest suffix we give sequential nodes. Suffixes are always 10 digits so
+	// that the names of sequential nodes sort in the order they were created.
+	MaxSequenceNumber = 9999999999
+)
+
+// ErrSequenceOverflow is returned when creating a sequential node under a parent that has already used
+// every sequence number.
+var ErrSequenceOverflow = errors.New("sequence number overflow")
+
 type ZNodeType int
 
 const (
 	ZNodeType_STANDARD ZNodeType = iota
 	ZNodeType_EPHEMERAL
+	// ZNodeType_TTL nodes are deleted by the server once they have had no children and no modifications
+	// for their TTL.
+	ZNodeType_TTL
+	// ZNodeType_CONTAINER nodes are deleted by the server once their last child is deleted.
+	ZNodeType_CONTAINER
 )
 
 type ZNode struct {
 	// ZNode metadata.
 	// Name is the full name of the ZNode from the root of the tree.
-	Name               string
-	Version            int64
-	Children           map[string]*ZNode
-	NodeType           ZNodeType
-	NextSequentialNode int
+	Name    string
+	Version int64
+	// Cversion is incremented each time a child is created or deleted.
+	Cversion int64
+	// Children is only set on the nodes inside the DB. The copies that the DB hands out leave it empty,
+	// since the children keep changing. Use DB.Children to list them instead.
+	Children map[string]*ZNode
+	NodeType ZNodeType
 	// Creator is the ClientID of who created this node. This is helpful when working with ephemeral nodes.
 	Creator string
+	// ACL is who is allowed to do what to this node. Like Data, it is replaced instead of modified in place.
+	ACL []*pbzk.ACL
+	// ACLVersion is incremented each time the ACL is set. It is separate from Version, which tracks the data.
+	ACLVersion int64
+	// Czxid is the zxid of the transaction that created the node.
+	Czxid int64
+	// Mzxid is the zxid of the transaction that last modified the data of the node.
+	Mzxid int64
+	// Pzxid is the zxid of the transaction that last created or deleted a child of the node.
+	Pzxid int64
+	// Ctime is when the node was created, in milliseconds since the epoch.
+	Ctime int64
+	// Mtime is when the data of the node was last modified, in milliseconds since the epoch. This is the
+	// timestamp of the transaction, so every replica has the same value.
+	Mtime int64
+	// TTL is how long a TTL node can go without children or modifications before it is deleted.
+	TTL time.Duration
 
-	// Data is the data stored here by the client.
+	// Data is the data stored here by the client. Writes replace it instead of modifying it in place, so the
+	// copies of the node can share it.
 	Data []byte
 }
 
@@ -35,12 +80,28 @@ func NewZNode(name string, nodeType ZNodeType, creator string, data []byte) *ZNo
 		Children: map[string]*ZNode{},
 		NodeType: nodeType,
 		Creator:  creator,
-		Data:     data,
+		// Anyone can do anything to the node until it's given an ACL.
+		ACL:  OpenACLUnsafe,
+		Data: data,
 	}
 }
 
-type Watch struct {
-	ClientID   string
-	Path       string
-	WatchTypes []pbzk.WatchEvent_EventType
+// copy returns a copy of the node without its children. Writers keep modifying the nodes in the tree, so
+// this is what the DB hands out to readers. The copy must not be modified.
+func (n *ZNode) copy() *ZNode {
+	c := *n
+	c.Children = nil
+	return &c
+}
+
+// SequentialName returns the name of a sequential node created under a parent with the given cversion. The
+// sequence number is the parent's cversion, so every replica picks the same one, and it is rebuilt along with
+// the rest of the tree from snapshots and the transaction log. Like in Zookeeper, the number is zero-padded
+// to 10 digits and appended directly to the name. Once the cversion is past MaxSequenceNumber, we return
+// ErrSequenceOverflow instead of names that would sort out of order.
+func SequentialName(name string, cversion int64) (string, error) {
+	if cversion < 0 || cversion > MaxSequenceNumber {
+		return "", fmt.Errorf("%w: the next sequence number for [%s] would be [%d]", ErrSequenceOverflow, name, cversion)
+	}
+	return fmt.Sprintf("%s%010d", name, cversion), nil
 }
@@ -29,6 +29,10 @@ type CreateTxn struct {
 	Data       []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
 	Ephemeral  bool   `protobuf:"varint,3,opt,name=ephemeral,proto3" json:"ephemeral,omitempty"`
 	Sequential bool   `protobuf:"varint,4,opt,name=sequential,proto3" json:"sequential,omitempty"`
+	Acl        []*ACL `protobuf:"bytes,5,rep,name=acl,proto3" json:"acl,omitempty"`
+	// ttl_ms is the time-to-live of a TTL node. It is 0 for every other type of node.
+	TtlMs     int64 `protobuf:"varint,6,opt,name=ttl_ms,json=ttlMs,proto3" json:"ttl_ms,omitempty"`
+	Container bool  `protobuf:"varint,7,opt,name=container,proto3" json:"container,omitempty"`
 }
 
 func (x *CreateTxn) Reset() {
@@ -91,12 +95,36 @@ func (x *CreateTxn) GetSequential() bool {
 	return false
 }
 
+func (x *CreateTxn) GetAcl() []*ACL {
+	if x != nil {
+		return x.Acl
+	}
+	return nil
+}
+
+func (x *CreateTxn) GetTtlMs() int64 {
+	if x != nil {
+		return x.TtlMs
+	}
+	return 0
+}
+
+func (x *CreateTxn) GetContainer() bool {
+	if x != nil {
+		return x.Container
+	}
+	return false
+}
+
 type DeleteTxn struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// If set, the node is only deleted if it still matches the condition. The server sets this when it deletes
+	// a node that it found had expired earlier, so that a node a client has used since is kept.
+	IfUnchanged *DeleteCondition `protobuf:"bytes,2,opt,name=if_unchanged,json=ifUnchanged,proto3" json:"if_unchanged,omitempty"`
 }
 
 func (x *DeleteTxn) Reset() {
@@ -138,6 +166,70 @@ func (x *DeleteTxn) GetPath() string {
 	return ""
 }
 
+func (x *DeleteTxn) GetIfUnchanged() *DeleteCondition {
+	if x != nil {
+		return x.IfUnchanged
+	}
+	return nil
+}
+
+// DeleteCondition is the state a node had when the server decided to delete it. The node must still have no
+// children, and must be at the same version and cversion.
+type DeleteCondition struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Version  int64 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Cversion int64 `protobuf:"varint,2,opt,name=cversion,proto3" json:"cversion,omitempty"`
+}
+
+func (x *DeleteCondition) Reset() {
+	*x = DeleteCondition{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_transaction_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteCondition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCondition) ProtoMessage() {}
+
+func (x *DeleteCondition) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCondition.ProtoReflect.Descriptor instead.
+func (*DeleteCondition) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DeleteCondition) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *DeleteCondition) GetCversion() int64 {
+	if x != nil {
+		return x.Cversion
+	}
+	return 0
+}
+
 type SetDataTxn struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -150,7 +242,7 @@ type SetDataTxn struct {
 func (x *SetDataTxn) Reset() {
 	*x = SetDataTxn{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_transaction_proto_msgTypes[2]
+		mi := &file_transaction_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -163,7 +255,7 @@ func (x *SetDataTxn) String() string {
 func (*SetDataTxn) ProtoMessage() {}
 
 func (x *SetDataTxn) ProtoReflect() protoreflect.Message {
-	mi := &file_transaction_proto_msgTypes[2]
+	mi := &file_transaction_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -176,7 +268,7 @@ func (x *SetDataTxn) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SetDataTxn.ProtoReflect.Descriptor instead.
 func (*SetDataTxn) Descriptor() ([]byte, []int) {
-	return file_transaction_proto_rawDescGZIP(), []int{2}
+	return file_transaction_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *SetDataTxn) GetPath() string {
@@ -193,6 +285,149 @@ func (x *SetDataTxn) GetData() []byte {
 	return nil
 }
 
+type SetACLTxn struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Acl  []*ACL `protobuf:"bytes,2,rep,name=acl,proto3" json:"acl,omitempty"`
+}
+
+func (x *SetACLTxn) Reset() {
+	*x = SetACLTxn{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_transaction_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetACLTxn) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetACLTxn) ProtoMessage() {}
+
+func (x *SetACLTxn) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetACLTxn.ProtoReflect.Descriptor instead.
+func (*SetACLTxn) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SetACLTxn) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *SetACLTxn) GetAcl() []*ACL {
+	if x != nil {
+		return x.Acl
+	}
+	return nil
+}
+
+type ReconfigTxn struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The full membership of the ensemble after the reconfig.
+	Config []byte `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+}
+
+func (x *ReconfigTxn) Reset() {
+	*x = ReconfigTxn{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_transaction_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReconfigTxn) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconfigTxn) ProtoMessage() {}
+
+func (x *ReconfigTxn) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconfigTxn.ProtoReflect.Descriptor instead.
+func (*ReconfigTxn) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ReconfigTxn) GetConfig() []byte {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+// CloseSessionTxn closes the session of the transaction's client and deletes every ephemeral node
+// the session created.
+type CloseSessionTxn struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *CloseSessionTxn) Reset() {
+	*x = CloseSessionTxn{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_transaction_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CloseSessionTxn) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseSessionTxn) ProtoMessage() {}
+
+func (x *CloseSessionTxn) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseSessionTxn.ProtoReflect.Descriptor instead.
+func (*CloseSessionTxn) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{6}
+}
+
 type ErrorTxn struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -204,7 +439,7 @@ type ErrorTxn struct {
 func (x *ErrorTxn) Reset() {
 	*x = ErrorTxn{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_transaction_proto_msgTypes[3]
+		mi := &file_transaction_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -217,7 +452,7 @@ func (x *ErrorTxn) String() string {
 func (*ErrorTxn) ProtoMessage() {}
 
 func (x *ErrorTxn) ProtoReflect() protoreflect.Message {
-	mi := &file_transaction_proto_msgTypes[3]
+	mi := &file_transaction_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -230,7 +465,7 @@ func (x *ErrorTxn) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ErrorTxn.ProtoReflect.Descriptor instead.
 func (*ErrorTxn) Descriptor() ([]byte, []int) {
-	return file_transaction_proto_rawDescGZIP(), []int{3}
+	return file_transaction_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *ErrorTxn) GetErr() string {
@@ -254,13 +489,16 @@ type Transaction struct {
 	//	*Transaction_Delete
 	//	*Transaction_SetData
 	//	*Transaction_Error
+	//	*Transaction_Reconfig
+	//	*Transaction_CloseSession
+	//	*Transaction_SetAcl
 	Txn isTransaction_Txn `protobuf_oneof:"txn"`
 }
 
 func (x *Transaction) Reset() {
 	*x = Transaction{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_transaction_proto_msgTypes[4]
+		mi := &file_transaction_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -273,7 +511,7 @@ func (x *Transaction) String() string {
 func (*Transaction) ProtoMessage() {}
 
 func (x *Transaction) ProtoReflect() protoreflect.Message {
-	mi := &file_transaction_proto_msgTypes[4]
+	mi := &file_transaction_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -286,7 +524,7 @@ func (x *Transaction) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Transaction.ProtoReflect.Descriptor instead.
 func (*Transaction) Descriptor() ([]byte, []int) {
-	return file_transaction_proto_rawDescGZIP(), []int{4}
+	return file_transaction_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *Transaction) GetClientId() string {
@@ -345,6 +583,27 @@ func (x *Transaction) GetError() *ErrorTxn {
 	return nil
 }
 
+func (x *Transaction) GetReconfig() *ReconfigTxn {
+	if x, ok := x.GetTxn().(*Transaction_Reconfig); ok {
+		return x.Reconfig
+	}
+	return nil
+}
+
+func (x *Transaction) GetCloseSession() *CloseSessionTxn {
+	if x, ok := x.GetTxn().(*Transaction_CloseSession); ok {
+		return x.CloseSession
+	}
+	return nil
+}
+
+func (x *Transaction) GetSetAcl() *SetACLTxn {
+	if x, ok := x.GetTxn().(*Transaction_SetAcl); ok {
+		return x.SetAcl
+	}
+	return nil
+}
+
 type isTransaction_Txn interface {
 	isTransaction_Txn()
 }
@@ -365,6 +624,18 @@ type Transaction_Error struct {
 	Error *ErrorTxn `protobuf:"bytes,7,opt,name=error,proto3,oneof"`
 }
 
+type Transaction_Reconfig struct {
+	Reconfig *ReconfigTxn `protobuf:"bytes,8,opt,name=reconfig,proto3,oneof"`
+}
+
+type Transaction_CloseSession struct {
+	CloseSession *CloseSessionTxn `protobuf:"bytes,9,opt,name=close_session,json=closeSession,proto3,oneof"`
+}
+
+type Transaction_SetAcl struct {
+	SetAcl *SetACLTxn `protobuf:"bytes,10,opt,name=set_acl,json=setAcl,proto3,oneof"`
+}
+
 func (*Transaction_Create) isTransaction_Txn() {}
 
 func (*Transaction_Delete) isTransaction_Txn() {}
@@ -373,48 +644,88 @@ func (*Transaction_SetData) isTransaction_Txn() {}
 
 func (*Transaction_Error) isTransaction_Txn() {}
 
+func (*Transaction_Reconfig) isTransaction_Txn() {}
+
+func (*Transaction_CloseSession) isTransaction_Txn() {}
+
+func (*Transaction_SetAcl) isTransaction_Txn() {}
+
 var File_transaction_proto protoreflect.FileDescriptor
 
 var file_transaction_proto_rawDesc = []byte{
 	0x0a, 0x11, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x12, 0x09, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x22, 0x71,
-	0x0a, 0x09, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x54, 0x78, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x70,
+	0x6f, 0x74, 0x6f, 0x12, 0x09, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x1a, 0x09,
+	0x61, 0x63, 0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xc8, 0x01, 0x0a, 0x09, 0x43, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x54, 0x78, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x64,
+	0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12,
+	0x1c, 0x0a, 0x09, 0x65, 0x70, 0x68, 0x65, 0x6d, 0x65, 0x72, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x09, 0x65, 0x70, 0x68, 0x65, 0x6d, 0x65, 0x72, 0x61, 0x6c, 0x12, 0x1e, 0x0a,
+	0x0a, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x0a, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x12, 0x20, 0x0a,
+	0x03, 0x61, 0x63, 0x6c, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x7a, 0x6f, 0x6f,
+	0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x41, 0x43, 0x4c, 0x52, 0x03, 0x61, 0x63, 0x6c, 0x12,
+	0x15, 0x0a, 0x06, 0x74, 0x74, 0x6c, 0x5f, 0x6d, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x05, 0x74, 0x74, 0x6c, 0x4d, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69,
+	0x6e, 0x65, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x63, 0x6f, 0x6e, 0x74, 0x61,
+	0x69, 0x6e, 0x65, 0x72, 0x22, 0x5e, 0x0a, 0x09, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x54, 0x78,
+	0x6e, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x3d, 0x0a, 0x0c, 0x69, 0x66, 0x5f, 0x75, 0x6e, 0x63, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x7a, 0x6f,
+	0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x43, 0x6f,
+	0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x69, 0x66, 0x55, 0x6e, 0x63, 0x68, 0x61,
+	0x6e, 0x67, 0x65, 0x64, 0x22, 0x47, 0x0a, 0x0f, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x43, 0x6f,
+	0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x08, 0x63, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x34, 0x0a,
+	0x0a, 0x53, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x54, 0x78, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x70,
 	0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12,
 	0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64,
-	0x61, 0x74, 0x61, 0x12, 0x1c, 0x0a, 0x09, 0x65, 0x70, 0x68, 0x65, 0x6d, 0x65, 0x72, 0x61, 0x6c,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x65, 0x70, 0x68, 0x65, 0x6d, 0x65, 0x72, 0x61,
-	0x6c, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x18,
-	0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x74, 0x69, 0x61,
-	0x6c, 0x22, 0x1f, 0x0a, 0x09, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x54, 0x78, 0x6e, 0x12, 0x12,
-	0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61,
-	0x74, 0x68, 0x22, 0x34, 0x0a, 0x0a, 0x53, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x54, 0x78, 0x6e,
+	0x61, 0x74, 0x61, 0x22, 0x41, 0x0a, 0x09, 0x53, 0x65, 0x74, 0x41, 0x43, 0x4c, 0x54, 0x78, 0x6e,
 	0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
-	0x70, 0x61, 0x74, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x1c, 0x0a, 0x08, 0x45, 0x72, 0x72, 0x6f,
-	0x72, 0x54, 0x78, 0x6e, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x72, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x03, 0x65, 0x72, 0x72, 0x22, 0xa9, 0x02, 0x0a, 0x0b, 0x54, 0x72, 0x61, 0x6e, 0x73,
-	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74,
-	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e,
-	0x74, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x7a, 0x78, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x03, 0x52, 0x04, 0x7a, 0x78, 0x69, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x74, 0x69, 0x6d, 0x65, 0x73,
-	0x74, 0x61, 0x6d, 0x70, 0x5f, 0x6d, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x74,
-	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x4d, 0x73, 0x12, 0x2e, 0x0a, 0x06, 0x63, 0x72,
-	0x65, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x7a, 0x6f, 0x6f,
-	0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x54, 0x78, 0x6e,
-	0x48, 0x00, 0x52, 0x06, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x12, 0x2e, 0x0a, 0x06, 0x64, 0x65,
-	0x6c, 0x65, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x7a, 0x6f, 0x6f,
-	0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x54, 0x78, 0x6e,
-	0x48, 0x00, 0x52, 0x06, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x32, 0x0a, 0x08, 0x73, 0x65,
-	0x74, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x7a,
-	0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x53, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61,
-	0x54, 0x78, 0x6e, 0x48, 0x00, 0x52, 0x07, 0x73, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x12, 0x2b,
-	0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e,
-	0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x54,
-	0x78, 0x6e, 0x48, 0x00, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x42, 0x05, 0x0a, 0x03, 0x74,
-	0x78, 0x6e, 0x42, 0x33, 0x5a, 0x31, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
-	0x2f, 0x6d, 0x69, 0x6b, 0x65, 0x6b, 0x75, 0x6c, 0x69, 0x6e, 0x73, 0x6b, 0x69, 0x2f, 0x7a, 0x6f,
-	0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x7a, 0x6f,
-	0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x70, 0x61, 0x74, 0x68, 0x12, 0x20, 0x0a, 0x03, 0x61, 0x63, 0x6c, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x0e, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x41, 0x43,
+	0x4c, 0x52, 0x03, 0x61, 0x63, 0x6c, 0x22, 0x25, 0x0a, 0x0b, 0x52, 0x65, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x54, 0x78, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x22, 0x11, 0x0a,
+	0x0f, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x54, 0x78, 0x6e,
+	0x22, 0x1c, 0x0a, 0x08, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x54, 0x78, 0x6e, 0x12, 0x10, 0x0a, 0x03,
+	0x65, 0x72, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x65, 0x72, 0x72, 0x22, 0xd3,
+	0x03, 0x0a, 0x0b, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1b,
+	0x0a, 0x09, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x7a,
+	0x78, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x7a, 0x78, 0x69, 0x64, 0x12,
+	0x21, 0x0a, 0x0c, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x5f, 0x6d, 0x73, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x4d, 0x73, 0x12, 0x2e, 0x0a, 0x06, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x14, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x43,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x54, 0x78, 0x6e, 0x48, 0x00, 0x52, 0x06, 0x63, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x12, 0x2e, 0x0a, 0x06, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x14, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x44,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x54, 0x78, 0x6e, 0x48, 0x00, 0x52, 0x06, 0x64, 0x65, 0x6c, 0x65,
+	0x74, 0x65, 0x12, 0x32, 0x0a, 0x08, 0x73, 0x65, 0x74, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72,
+	0x2e, 0x53, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x54, 0x78, 0x6e, 0x48, 0x00, 0x52, 0x07, 0x73,
+	0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x12, 0x2b, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65,
+	0x72, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x54, 0x78, 0x6e, 0x48, 0x00, 0x52, 0x05, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x12, 0x34, 0x0a, 0x08, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65,
+	0x72, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x54, 0x78, 0x6e, 0x48, 0x00, 0x52,
+	0x08, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x41, 0x0a, 0x0d, 0x63, 0x6c, 0x6f,
+	0x73, 0x65, 0x5f, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x43, 0x6c, 0x6f,
+	0x73, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x54, 0x78, 0x6e, 0x48, 0x00, 0x52, 0x0c,
+	0x63, 0x6c, 0x6f, 0x73, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2f, 0x0a, 0x07,
+	0x73, 0x65, 0x74, 0x5f, 0x61, 0x63, 0x6c, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e,
+	0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x53, 0x65, 0x74, 0x41, 0x43, 0x4c,
+	0x54, 0x78, 0x6e, 0x48, 0x00, 0x52, 0x06, 0x73, 0x65, 0x74, 0x41, 0x63, 0x6c, 0x42, 0x05, 0x0a,
+	0x03, 0x74, 0x78, 0x6e, 0x42, 0x33, 0x5a, 0x31, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
+	0x6f, 0x6d, 0x2f, 0x6d, 0x69, 0x6b, 0x65, 0x6b, 0x75, 0x6c, 0x69, 0x6e, 0x73, 0x6b, 0x69, 0x2f,
+	0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f,
+	0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
 }
 
 var (
@@ -429,24 +740,35 @@ func file_transaction_proto_rawDescGZIP() []byte {
 	return file_transaction_proto_rawDescData
 }
 
-var file_transaction_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_transaction_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_transaction_proto_goTypes = []interface{}{
-	(*CreateTxn)(nil),   // 0: zookeeper.CreateTxn
-	(*DeleteTxn)(nil),   // 1: zookeeper.DeleteTxn
-	(*SetDataTxn)(nil),  // 2: zookeeper.SetDataTxn
-	(*ErrorTxn)(nil),    // 3: zookeeper.ErrorTxn
-	(*Transaction)(nil), // 4: zookeeper.Transaction
+	(*CreateTxn)(nil),       // 0: zookeeper.CreateTxn
+	(*DeleteTxn)(nil),       // 1: zookeeper.DeleteTxn
+	(*DeleteCondition)(nil), // 2: zookeeper.DeleteCondition
+	(*SetDataTxn)(nil),      // 3: zookeeper.SetDataTxn
+	(*SetACLTxn)(nil),       // 4: zookeeper.SetACLTxn
+	(*ReconfigTxn)(nil),     // 5: zookeeper.ReconfigTxn
+	(*CloseSessionTxn)(nil), // 6: zookeeper.CloseSessionTxn
+	(*ErrorTxn)(nil),        // 7: zookeeper.ErrorTxn
+	(*Transaction)(nil),     // 8: zookeeper.Transaction
+	(*ACL)(nil),             // 9: zookeeper.ACL
 }
 var file_transaction_proto_depIdxs = []int32{
-	0, // 0: zookeeper.Transaction.create:type_name -> zookeeper.CreateTxn
-	1, // 1: zookeeper.Transaction.delete:type_name -> zookeeper.DeleteTxn
-	2, // 2: zookeeper.Transaction.set_data:type_name -> zookeeper.SetDataTxn
-	3, // 3: zookeeper.Transaction.error:type_name -> zookeeper.ErrorTxn
-	4, // [4:4] is the sub-list for method output_type
-	4, // [4:4] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	9,  // 0: zookeeper.CreateTxn.acl:type_name -> zookeeper.ACL
+	2,  // 1: zookeeper.DeleteTxn.if_unchanged:type_name -> zookeeper.DeleteCondition
+	9,  // 2: zookeeper.SetACLTxn.acl:type_name -> zookeeper.ACL
+	0,  // 3: zookeeper.Transaction.create:type_name -> zookeeper.CreateTxn
+	1,  // 4: zookeeper.Transaction.delete:type_name -> zookeeper.DeleteTxn
+	3,  // 5: zookeeper.Transaction.set_data:type_name -> zookeeper.SetDataTxn
+	7,  // 6: zookeeper.Transaction.error:type_name -> zookeeper.ErrorTxn
+	5,  // 7: zookeeper.Transaction.reconfig:type_name -> zookeeper.ReconfigTxn
+	6,  // 8: zookeeper.Transaction.close_session:type_name -> zookeeper.CloseSessionTxn
+	4,  // 9: zookeeper.Transaction.set_acl:type_name -> zookeeper.SetACLTxn
+	10, // [10:10] is the sub-list for method output_type
+	10, // [10:10] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_transaction_proto_init() }
@@ -454,6 +776,7 @@ func file_transaction_proto_init() {
 	if File_transaction_proto != nil {
 		return
 	}
+	file_acl_proto_init()
 	if !protoimpl.UnsafeEnabled {
 		file_transaction_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CreateTxn); i {
@@ -480,7 +803,7 @@ func file_transaction_proto_init() {
 			}
 		}
 		file_transaction_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SetDataTxn); i {
+			switch v := v.(*DeleteCondition); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -492,7 +815,7 @@ func file_transaction_proto_init() {
 			}
 		}
 		file_transaction_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ErrorTxn); i {
+			switch v := v.(*SetDataTxn); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -504,6 +827,54 @@ func file_transaction_proto_init() {
 			}
 		}
 		file_transaction_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetACLTxn); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_transaction_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReconfigTxn); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_transaction_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CloseSessionTxn); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_transaction_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ErrorTxn); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_transaction_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Transaction); i {
 			case 0:
 				return &v.state
@@ -516,11 +887,14 @@ func file_transaction_proto_init() {
 			}
 		}
 	}
-	file_transaction_proto_msgTypes[4].OneofWrappers = []interface{}{
+	file_transaction_proto_msgTypes[8].OneofWrappers = []interface{}{
 		(*Transaction_Create)(nil),
 		(*Transaction_Delete)(nil),
 		(*Transaction_SetData)(nil),
 		(*Transaction_Error)(nil),
+		(*Transaction_Reconfig)(nil),
+		(*Transaction_CloseSession)(nil),
+		(*Transaction_SetAcl)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -528,7 +902,7 @@ func file_transaction_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_transaction_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -20,6 +20,8 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Deprecated: Use CreateMode instead. FLAG_EPHEMERAL is the default value, so a flag that was never set
+// reads as ephemeral.
 type CreateRequest_Flag int32
 
 const (
@@ -29,6 +31,13 @@ const (
 	// FLAG_SEQUENTIAL indicates that the node to be created should have a monotonically increasing counter appended
 	// to the end of the provided name.
 	CreateRequest_FLAG_SEQUENTIAL CreateRequest_Flag = 1
+	// FLAG_TTL indicates that the ZNode to be created should be automatically destroyed once it has had no
+	// children and no modifications for ttl_ms. This can't be combined with FLAG_EPHEMERAL.
+	CreateRequest_FLAG_TTL CreateRequest_Flag = 2
+	// FLAG_CONTAINER indicates that the ZNode to be created should be automatically destroyed once its last child
+	// has been deleted. This is useful for the parents of locks and leader elections. This can't be combined with
+	// any other flag.
+	CreateRequest_FLAG_CONTAINER CreateRequest_Flag = 3
 )
 
 // Enum value maps for CreateRequest_Flag.
@@ -36,10 +45,14 @@ var (
 	CreateRequest_Flag_name = map[int32]string{
 		0: "FLAG_EPHEMERAL",
 		1: "FLAG_SEQUENTIAL",
+		2: "FLAG_TTL",
+		3: "FLAG_CONTAINER",
 	}
 	CreateRequest_Flag_value = map[string]int32{
 		"FLAG_EPHEMERAL":  0,
 		"FLAG_SEQUENTIAL": 1,
+		"FLAG_TTL":        2,
+		"FLAG_CONTAINER":  3,
 	}
 )
 
@@ -67,7 +80,385 @@ func (x CreateRequest_Flag) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use CreateRequest_Flag.Descriptor instead.
 func (CreateRequest_Flag) EnumDescriptor() ([]byte, []int) {
-	return file_zookeeper_proto_rawDescGZIP(), []int{2, 0}
+	return file_zookeeper_proto_rawDescGZIP(), []int{4, 0}
+}
+
+// CreateMode is the type of ZNode to create.
+type CreateRequest_CreateMode int32
+
+const (
+	// MODE_UNSET falls back to the flags, which create a persistent node if there aren't any.
+	CreateRequest_MODE_UNSET CreateRequest_CreateMode = 0
+	// MODE_PERSISTENT nodes stay until they are deleted.
+	CreateRequest_MODE_PERSISTENT CreateRequest_CreateMode = 1
+	// MODE_EPHEMERAL nodes are automatically destroyed once the session has been terminated (either
+	// intentionally or on failure). They cannot have children.
+	CreateRequest_MODE_EPHEMERAL CreateRequest_CreateMode = 2
+	// MODE_PERSISTENT_SEQUENTIAL nodes are persistent nodes with a monotonically increasing counter appended to
+	// the end of the provided name.
+	CreateRequest_MODE_PERSISTENT_SEQUENTIAL CreateRequest_CreateMode = 3
+	// MODE_EPHEMERAL_SEQUENTIAL nodes are ephemeral nodes with a monotonically increasing counter appended to
+	// the end of the provided name.
+	CreateRequest_MODE_EPHEMERAL_SEQUENTIAL CreateRequest_CreateMode = 4
+	// MODE_CONTAINER nodes are automatically destroyed once their last child has been deleted. This is useful
+	// for the parents of locks and leader elections.
+	CreateRequest_MODE_CONTAINER CreateRequest_CreateMode = 5
+	// MODE_PERSISTENT_WITH_TTL nodes are automatically destroyed once they have had no children and no
+	// modifications for ttl_ms.
+	CreateRequest_MODE_PERSISTENT_WITH_TTL CreateRequest_CreateMode = 6
+	// MODE_PERSISTENT_SEQUENTIAL_WITH_TTL nodes are TTL nodes with a monotonically increasing counter appended
+	// to the end of the provided name.
+	CreateRequest_MODE_PERSISTENT_SEQUENTIAL_WITH_TTL CreateRequest_CreateMode = 7
+)
+
+// Enum value maps for CreateRequest_CreateMode.
+var (
+	CreateRequest_CreateMode_name = map[int32]string{
+		0: "MODE_UNSET",
+		1: "MODE_PERSISTENT",
+		2: "MODE_EPHEMERAL",
+		3: "MODE_PERSISTENT_SEQUENTIAL",
+		4: "MODE_EPHEMERAL_SEQUENTIAL",
+		5: "MODE_CONTAINER",
+		6: "MODE_PERSISTENT_WITH_TTL",
+		7: "MODE_PERSISTENT_SEQUENTIAL_WITH_TTL",
+	}
+	CreateRequest_CreateMode_value = map[string]int32{
+		"MODE_UNSET":                          0,
+		"MODE_PERSISTENT":                     1,
+		"MODE_EPHEMERAL":                      2,
+		"MODE_PERSISTENT_SEQUENTIAL":          3,
+		"MODE_EPHEMERAL_SEQUENTIAL":           4,
+		"MODE_CONTAINER":                      5,
+		"MODE_PERSISTENT_WITH_TTL":            6,
+		"MODE_PERSISTENT_SEQUENTIAL_WITH_TTL": 7,
+	}
+)
+
+func (x CreateRequest_CreateMode) Enum() *CreateRequest_CreateMode {
+	p := new(CreateRequest_CreateMode)
+	*p = x
+	return p
+}
+
+func (x CreateRequest_CreateMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CreateRequest_CreateMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_zookeeper_proto_enumTypes[1].Descriptor()
+}
+
+func (CreateRequest_CreateMode) Type() protoreflect.EnumType {
+	return &file_zookeeper_proto_enumTypes[1]
+}
+
+func (x CreateRequest_CreateMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CreateRequest_CreateMode.Descriptor instead.
+func (CreateRequest_CreateMode) EnumDescriptor() ([]byte, []int) {
+	return file_zookeeper_proto_rawDescGZIP(), []int{4, 1}
+}
+
+type AddWatchRequest_Mode int32
+
+const (
+	AddWatchRequest_MODE_UNSET AddWatchRequest_Mode = 0
+	// MODE_PERSISTENT watches for the ZNode being created, deleted, or changed, and for its children changing.
+	AddWatchRequest_MODE_PERSISTENT AddWatchRequest_Mode = 1
+	// MODE_PERSISTENT_RECURSIVE watches for the ZNode or any of its descendants being created, deleted, or changed.
+	AddWatchRequest_MODE_PERSISTENT_RECURSIVE AddWatchRequest_Mode = 2
+)
+
+// Enum value maps for AddWatchRequest_Mode.
+var (
+	AddWatchRequest_Mode_name = map[int32]string{
+		0: "MODE_UNSET",
+		1: "MODE_PERSISTENT",
+		2: "MODE_PERSISTENT_RECURSIVE",
+	}
+	AddWatchRequest_Mode_value = map[string]int32{
+		"MODE_UNSET":                0,
+		"MODE_PERSISTENT":           1,
+		"MODE_PERSISTENT_RECURSIVE": 2,
+	}
+)
+
+func (x AddWatchRequest_Mode) Enum() *AddWatchRequest_Mode {
+	p := new(AddWatchRequest_Mode)
+	*p = x
+	return p
+}
+
+func (x AddWatchRequest_Mode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AddWatchRequest_Mode) Descriptor() protoreflect.EnumDescriptor {
+	return file_zookeeper_proto_enumTypes[2].Descriptor()
+}
+
+func (AddWatchRequest_Mode) Type() protoreflect.EnumType {
+	return &file_zookeeper_proto_enumTypes[2]
+}
+
+func (x AddWatchRequest_Mode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AddWatchRequest_Mode.Descriptor instead.
+func (AddWatchRequest_Mode) EnumDescriptor() ([]byte, []int) {
+	return file_zookeeper_proto_rawDescGZIP(), []int{24, 0}
+}
+
+type RemoveWatchesRequest_WatcherType int32
+
+const (
+	RemoveWatchesRequest_WATCHER_TYPE_UNSET RemoveWatchesRequest_WatcherType = 0
+	// WATCHER_TYPE_CHILDREN removes the watches set by getChildren.
+	RemoveWatchesRequest_WATCHER_TYPE_CHILDREN RemoveWatchesRequest_WatcherType = 1
+	// WATCHER_TYPE_DATA removes the watches set by exists and getData.
+	RemoveWatchesRequest_WATCHER_TYPE_DATA RemoveWatchesRequest_WatcherType = 2
+	// WATCHER_TYPE_ANY removes every watch on the ZNode, including persistent ones.
+	RemoveWatchesRequest_WATCHER_TYPE_ANY RemoveWatchesRequest_WatcherType = 3
+	// WATCHER_TYPE_PERSISTENT removes the watch set by addWatch with MODE_PERSISTENT.
+	RemoveWatchesRequest_WATCHER_TYPE_PERSISTENT RemoveWatchesRequest_WatcherType = 4
+	// WATCHER_TYPE_PERSISTENT_RECURSIVE removes the watch set by addWatch with MODE_PERSISTENT_RECURSIVE.
+	RemoveWatchesRequest_WATCHER_TYPE_PERSISTENT_RECURSIVE RemoveWatchesRequest_WatcherType = 5
+)
+
+// Enum value maps for RemoveWatchesRequest_WatcherType.
+var (
+	RemoveWatchesRequest_WatcherType_name = map[int32]string{
+		0: "WATCHER_TYPE_UNSET",
+		1: "WATCHER_TYPE_CHILDREN",
+		2: "WATCHER_TYPE_DATA",
+		3: "WATCHER_TYPE_ANY",
+		4: "WATCHER_TYPE_PERSISTENT",
+		5: "WATCHER_TYPE_PERSISTENT_RECURSIVE",
+	}
+	RemoveWatchesRequest_WatcherType_value = map[string]int32{
+		"WATCHER_TYPE_UNSET":                0,
+		"WATCHER_TYPE_CHILDREN":             1,
+		"WATCHER_TYPE_DATA":                 2,
+		"WATCHER_TYPE_ANY":                  3,
+		"WATCHER_TYPE_PERSISTENT":           4,
+		"WATCHER_TYPE_PERSISTENT_RECURSIVE": 5,
+	}
+)
+
+func (x RemoveWatchesRequest_WatcherType) Enum() *RemoveWatchesRequest_WatcherType {
+	p := new(RemoveWatchesRequest_WatcherType)
+	*p = x
+	return p
+}
+
+func (x RemoveWatchesRequest_WatcherType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RemoveWatchesRequest_WatcherType) Descriptor() protoreflect.EnumDescriptor {
+	return file_zookeeper_proto_enumTypes[3].Descriptor()
+}
+
+func (RemoveWatchesRequest_WatcherType) Type() protoreflect.EnumType {
+	return &file_zookeeper_proto_enumTypes[3]
+}
+
+func (x RemoveWatchesRequest_WatcherType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RemoveWatchesRequest_WatcherType.Descriptor instead.
+func (RemoveWatchesRequest_WatcherType) EnumDescriptor() ([]byte, []int) {
+	return file_zookeeper_proto_rawDescGZIP(), []int{26, 0}
+}
+
+// ConnectRequest is the first message the client sends on a new stream. It either starts a new session
+// or moves an existing session to this stream.
+type ConnectRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The session timeout the client would like, in ms. The server may pick a different timeout.
+	TimeoutMs int64 `protobuf:"varint,1,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
+	// The id and password of the session to resume. These are empty when starting a new session.
+	SessionId string `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Password  string `protobuf:"bytes,3,opt,name=password,proto3" json:"password,omitempty"`
+	// The last zxid the client has seen. Servers that are behind this refuse the connection.
+	LastZxid int64 `protobuf:"varint,4,opt,name=last_zxid,json=lastZxid,proto3" json:"last_zxid,omitempty"`
+	// The paths of the watches the client had set before reconnecting, by the type of watch. The server sets
+	// them again, and immediately fires the ones that would have fired since last_zxid.
+	DataWatches                []string `protobuf:"bytes,5,rep,name=data_watches,json=dataWatches,proto3" json:"data_watches,omitempty"`
+	ChildWatches               []string `protobuf:"bytes,6,rep,name=child_watches,json=childWatches,proto3" json:"child_watches,omitempty"`
+	PersistentWatches          []string `protobuf:"bytes,7,rep,name=persistent_watches,json=persistentWatches,proto3" json:"persistent_watches,omitempty"`
+	PersistentRecursiveWatches []string `protobuf:"bytes,8,rep,name=persistent_recursive_watches,json=persistentRecursiveWatches,proto3" json:"persistent_recursive_watches,omitempty"`
+	// The paths of the watches set by exists on nodes that didn't exist yet. Unlike data_watches, the server keeps
+	// watching these if the node is still missing, and fires a created event if it was created since last_zxid.
+	ExistWatches []string `protobuf:"bytes,9,rep,name=exist_watches,json=existWatches,proto3" json:"exist_watches,omitempty"`
+}
+
+func (x *ConnectRequest) Reset() {
+	*x = ConnectRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zookeeper_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConnectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnectRequest) ProtoMessage() {}
+
+func (x *ConnectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zookeeper_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConnectRequest.ProtoReflect.Descriptor instead.
+func (*ConnectRequest) Descriptor() ([]byte, []int) {
+	return file_zookeeper_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ConnectRequest) GetTimeoutMs() int64 {
+	if x != nil {
+		return x.TimeoutMs
+	}
+	return 0
+}
+
+func (x *ConnectRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *ConnectRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *ConnectRequest) GetLastZxid() int64 {
+	if x != nil {
+		return x.LastZxid
+	}
+	return 0
+}
+
+func (x *ConnectRequest) GetDataWatches() []string {
+	if x != nil {
+		return x.DataWatches
+	}
+	return nil
+}
+
+func (x *ConnectRequest) GetChildWatches() []string {
+	if x != nil {
+		return x.ChildWatches
+	}
+	return nil
+}
+
+func (x *ConnectRequest) GetPersistentWatches() []string {
+	if x != nil {
+		return x.PersistentWatches
+	}
+	return nil
+}
+
+func (x *ConnectRequest) GetPersistentRecursiveWatches() []string {
+	if x != nil {
+		return x.PersistentRecursiveWatches
+	}
+	return nil
+}
+
+func (x *ConnectRequest) GetExistWatches() []string {
+	if x != nil {
+		return x.ExistWatches
+	}
+	return nil
+}
+
+type ConnectResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The session timeout the server actually gave the session, in ms.
+	TimeoutMs int64 `protobuf:"varint,1,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
+	// The id and password the client needs to resume the session on another stream.
+	SessionId string `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Password  string `protobuf:"bytes,3,opt,name=password,proto3" json:"password,omitempty"`
+}
+
+func (x *ConnectResponse) Reset() {
+	*x = ConnectResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zookeeper_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConnectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnectResponse) ProtoMessage() {}
+
+func (x *ConnectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_zookeeper_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConnectResponse.ProtoReflect.Descriptor instead.
+func (*ConnectResponse) Descriptor() ([]byte, []int) {
+	return file_zookeeper_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ConnectResponse) GetTimeoutMs() int64 {
+	if x != nil {
+		return x.TimeoutMs
+	}
+	return 0
+}
+
+func (x *ConnectResponse) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *ConnectResponse) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
 }
 
 type HeartbeatRequest struct {
@@ -82,7 +473,7 @@ type HeartbeatRequest struct {
 func (x *HeartbeatRequest) Reset() {
 	*x = HeartbeatRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zookeeper_proto_msgTypes[0]
+		mi := &file_zookeeper_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -95,7 +486,7 @@ func (x *HeartbeatRequest) String() string {
 func (*HeartbeatRequest) ProtoMessage() {}
 
 func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_zookeeper_proto_msgTypes[0]
+	mi := &file_zookeeper_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -108,7 +499,7 @@ func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
 func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
-	return file_zookeeper_proto_rawDescGZIP(), []int{0}
+	return file_zookeeper_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *HeartbeatRequest) GetSentTsMs() int64 {
@@ -130,7 +521,7 @@ type HeartbeatResponse struct {
 func (x *HeartbeatResponse) Reset() {
 	*x = HeartbeatResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zookeeper_proto_msgTypes[1]
+		mi := &file_zookeeper_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -143,7 +534,7 @@ func (x *HeartbeatResponse) String() string {
 func (*HeartbeatResponse) ProtoMessage() {}
 
 func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_zookeeper_proto_msgTypes[1]
+	mi := &file_zookeeper_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -156,7 +547,7 @@ func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HeartbeatResponse.ProtoReflect.Descriptor instead.
 func (*HeartbeatResponse) Descriptor() ([]byte, []int) {
-	return file_zookeeper_proto_rawDescGZIP(), []int{1}
+	return file_zookeeper_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *HeartbeatResponse) GetReceivedTsMs() int64 {
@@ -175,14 +566,22 @@ type CreateRequest struct {
 	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
 	// The data that we'd like to save in the ZNode we're creating.
 	Data []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
-	// Flags are an optional array of arguments to create different types of ZNodes.
+	// Deprecated: Use mode instead. Flags are only read when the mode is unset.
+	//
+	// Deprecated: Marked as deprecated in zookeeper.proto.
 	Flags []CreateRequest_Flag `protobuf:"varint,3,rep,packed,name=flags,proto3,enum=zookeeper.CreateRequest_Flag" json:"flags,omitempty"`
+	// The ACL of the new ZNode. If this is empty, then anyone can do anything to the node.
+	Acl []*ACL `protobuf:"bytes,4,rep,name=acl,proto3" json:"acl,omitempty"`
+	// The time-to-live of the ZNode in milliseconds. This must be set for the modes with a TTL, and only for them.
+	TtlMs int64 `protobuf:"varint,5,opt,name=ttl_ms,json=ttlMs,proto3" json:"ttl_ms,omitempty"`
+	// The type of ZNode to create.
+	Mode CreateRequest_CreateMode `protobuf:"varint,6,opt,name=mode,proto3,enum=zookeeper.CreateRequest_CreateMode" json:"mode,omitempty"`
 }
 
 func (x *CreateRequest) Reset() {
 	*x = CreateRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zookeeper_proto_msgTypes[2]
+		mi := &file_zookeeper_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -195,7 +594,7 @@ func (x *CreateRequest) String() string {
 func (*CreateRequest) ProtoMessage() {}
 
 func (x *CreateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_zookeeper_proto_msgTypes[2]
+	mi := &file_zookeeper_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -208,7 +607,7 @@ func (x *CreateRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateRequest.ProtoReflect.Descriptor instead.
 func (*CreateRequest) Descriptor() ([]byte, []int) {
-	return file_zookeeper_proto_rawDescGZIP(), []int{2}
+	return file_zookeeper_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *CreateRequest) GetPath() string {
@@ -225,6 +624,7 @@ func (x *CreateRequest) GetData() []byte {
 	return nil
 }
 
+// Deprecated: Marked as deprecated in zookeeper.proto.
 func (x *CreateRequest) GetFlags() []CreateRequest_Flag {
 	if x != nil {
 		return x.Flags
@@ -232,6 +632,27 @@ func (x *CreateRequest) GetFlags() []CreateRequest_Flag {
 	return nil
 }
 
+func (x *CreateRequest) GetAcl() []*ACL {
+	if x != nil {
+		return x.Acl
+	}
+	return nil
+}
+
+func (x *CreateRequest) GetTtlMs() int64 {
+	if x != nil {
+		return x.TtlMs
+	}
+	return 0
+}
+
+func (x *CreateRequest) GetMode() CreateRequest_CreateMode {
+	if x != nil {
+		return x.Mode
+	}
+	return CreateRequest_MODE_UNSET
+}
+
 type CreateResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -245,7 +666,7 @@ type CreateResponse struct {
 func (x *CreateResponse) Reset() {
 	*x = CreateResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zookeeper_proto_msgTypes[3]
+		mi := &file_zookeeper_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -258,7 +679,7 @@ func (x *CreateResponse) String() string {
 func (*CreateResponse) ProtoMessage() {}
 
 func (x *CreateResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_zookeeper_proto_msgTypes[3]
+	mi := &file_zookeeper_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -271,7 +692,7 @@ func (x *CreateResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateResponse.ProtoReflect.Descriptor instead.
 func (*CreateResponse) Descriptor() ([]byte, []int) {
-	return file_zookeeper_proto_rawDescGZIP(), []int{3}
+	return file_zookeeper_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *CreateResponse) GetZNodeName() string {
@@ -295,7 +716,7 @@ type DeleteRequest struct {
 func (x *DeleteRequest) Reset() {
 	*x = DeleteRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zookeeper_proto_msgTypes[4]
+		mi := &file_zookeeper_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -308,7 +729,7 @@ func (x *DeleteRequest) String() string {
 func (*DeleteRequest) ProtoMessage() {}
 
 func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_zookeeper_proto_msgTypes[4]
+	mi := &file_zookeeper_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -321,7 +742,7 @@ func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
 func (*DeleteRequest) Descriptor() ([]byte, []int) {
-	return file_zookeeper_proto_rawDescGZIP(), []int{4}
+	return file_zookeeper_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *DeleteRequest) GetPath() string {
@@ -347,7 +768,7 @@ type DeleteResponse struct {
 func (x *DeleteResponse) Reset() {
 	*x = DeleteResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zookeeper_proto_msgTypes[5]
+		mi := &file_zookeeper_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -360,7 +781,7 @@ func (x *DeleteResponse) String() string {
 func (*DeleteResponse) ProtoMessage() {}
 
 func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_zookeeper_proto_msgTypes[5]
+	mi := &file_zookeeper_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -373,7 +794,7 @@ func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteResponse.ProtoReflect.Descriptor instead.
 func (*DeleteResponse) Descriptor() ([]byte, []int) {
-	return file_zookeeper_proto_rawDescGZIP(), []int{5}
+	return file_zookeeper_proto_rawDescGZIP(), []int{7}
 }
 
 type ExistsRequest struct {
@@ -390,7 +811,7 @@ type ExistsRequest struct {
 func (x *ExistsRequest) Reset() {
 	*x = ExistsRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zookeeper_proto_msgTypes[6]
+		mi := &file_zookeeper_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -403,7 +824,7 @@ func (x *ExistsRequest) String() string {
 func (*ExistsRequest) ProtoMessage() {}
 
 func (x *ExistsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_zookeeper_proto_msgTypes[6]
+	mi := &file_zookeeper_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -416,7 +837,7 @@ func (x *ExistsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExistsRequest.ProtoReflect.Descriptor instead.
 func (*ExistsRequest) Descriptor() ([]byte, []int) {
-	return file_zookeeper_proto_rawDescGZIP(), []int{6}
+	return file_zookeeper_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *ExistsRequest) GetPath() string {
@@ -445,7 +866,7 @@ type ExistsResponse struct {
 func (x *ExistsResponse) Reset() {
 	*x = ExistsResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zookeeper_proto_msgTypes[7]
+		mi := &file_zookeeper_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -458,7 +879,7 @@ func (x *ExistsResponse) String() string {
 func (*ExistsResponse) ProtoMessage() {}
 
 func (x *ExistsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_zookeeper_proto_msgTypes[7]
+	mi := &file_zookeeper_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -471,7 +892,7 @@ func (x *ExistsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExistsResponse.ProtoReflect.Descriptor instead.
 func (*ExistsResponse) Descriptor() ([]byte, []int) {
-	return file_zookeeper_proto_rawDescGZIP(), []int{7}
+	return file_zookeeper_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *ExistsResponse) GetExists() bool {
@@ -495,7 +916,7 @@ type GetDataRequest struct {
 func (x *GetDataRequest) Reset() {
 	*x = GetDataRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zookeeper_proto_msgTypes[8]
+		mi := &file_zookeeper_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -508,7 +929,7 @@ func (x *GetDataRequest) String() string {
 func (*GetDataRequest) ProtoMessage() {}
 
 func (x *GetDataRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_zookeeper_proto_msgTypes[8]
+	mi := &file_zookeeper_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -521,7 +942,7 @@ func (x *GetDataRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetDataRequest.ProtoReflect.Descriptor instead.
 func (*GetDataRequest) Descriptor() ([]byte, []int) {
-	return file_zookeeper_proto_rawDescGZIP(), []int{8}
+	return file_zookeeper_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *GetDataRequest) GetPath() string {
@@ -552,7 +973,7 @@ type GetDataResponse struct {
 func (x *GetDataResponse) Reset() {
 	*x = GetDataResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zookeeper_proto_msgTypes[9]
+		mi := &file_zookeeper_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -565,7 +986,7 @@ func (x *GetDataResponse) String() string {
 func (*GetDataResponse) ProtoMessage() {}
 
 func (x *GetDataResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_zookeeper_proto_msgTypes[9]
+	mi := &file_zookeeper_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -578,7 +999,7 @@ func (x *GetDataResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetDataResponse.ProtoReflect.Descriptor instead.
 func (*GetDataResponse) Descriptor() ([]byte, []int) {
-	return file_zookeeper_proto_rawDescGZIP(), []int{9}
+	return file_zookeeper_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *GetDataResponse) GetData() []byte {
@@ -611,7 +1032,7 @@ type SetDataRequest struct {
 func (x *SetDataRequest) Reset() {
 	*x = SetDataRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zookeeper_proto_msgTypes[10]
+		mi := &file_zookeeper_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -624,7 +1045,7 @@ func (x *SetDataRequest) String() string {
 func (*SetDataRequest) ProtoMessage() {}
 
 func (x *SetDataRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_zookeeper_proto_msgTypes[10]
+	mi := &file_zookeeper_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -637,7 +1058,7 @@ func (x *SetDataRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SetDataRequest.ProtoReflect.Descriptor instead.
 func (*SetDataRequest) Descriptor() ([]byte, []int) {
-	return file_zookeeper_proto_rawDescGZIP(), []int{10}
+	return file_zookeeper_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *SetDataRequest) GetPath() string {
@@ -670,7 +1091,7 @@ type SetDataResponse struct {
 func (x *SetDataResponse) Reset() {
 	*x = SetDataResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zookeeper_proto_msgTypes[11]
+		mi := &file_zookeeper_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -683,7 +1104,7 @@ func (x *SetDataResponse) String() string {
 func (*SetDataResponse) ProtoMessage() {}
 
 func (x *SetDataResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_zookeeper_proto_msgTypes[11]
+	mi := &file_zookeeper_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -696,7 +1117,7 @@ func (x *SetDataResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SetDataResponse.ProtoReflect.Descriptor instead.
 func (*SetDataResponse) Descriptor() ([]byte, []int) {
-	return file_zookeeper_proto_rawDescGZIP(), []int{11}
+	return file_zookeeper_proto_rawDescGZIP(), []int{13}
 }
 
 type GetChildrenRequest struct {
@@ -713,7 +1134,7 @@ type GetChildrenRequest struct {
 func (x *GetChildrenRequest) Reset() {
 	*x = GetChildrenRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zookeeper_proto_msgTypes[12]
+		mi := &file_zookeeper_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -726,7 +1147,7 @@ func (x *GetChildrenRequest) String() string {
 func (*GetChildrenRequest) ProtoMessage() {}
 
 func (x *GetChildrenRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_zookeeper_proto_msgTypes[12]
+	mi := &file_zookeeper_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -739,7 +1160,7 @@ func (x *GetChildrenRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetChildrenRequest.ProtoReflect.Descriptor instead.
 func (*GetChildrenRequest) Descriptor() ([]byte, []int) {
-	return file_zookeeper_proto_rawDescGZIP(), []int{12}
+	return file_zookeeper_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *GetChildrenRequest) GetPath() string {
@@ -770,7 +1191,7 @@ type GetChildrenResponse struct {
 func (x *GetChildrenResponse) Reset() {
 	*x = GetChildrenResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zookeeper_proto_msgTypes[13]
+		mi := &file_zookeeper_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -783,7 +1204,7 @@ func (x *GetChildrenResponse) String() string {
 func (*GetChildrenResponse) ProtoMessage() {}
 
 func (x *GetChildrenResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_zookeeper_proto_msgTypes[13]
+	mi := &file_zookeeper_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -796,7 +1217,7 @@ func (x *GetChildrenResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetChildrenResponse.ProtoReflect.Descriptor instead.
 func (*GetChildrenResponse) Descriptor() ([]byte, []int) {
-	return file_zookeeper_proto_rawDescGZIP(), []int{13}
+	return file_zookeeper_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *GetChildrenResponse) GetChildren() []string {
@@ -818,7 +1239,7 @@ type SyncRequest struct {
 func (x *SyncRequest) Reset() {
 	*x = SyncRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zookeeper_proto_msgTypes[14]
+		mi := &file_zookeeper_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -831,7 +1252,7 @@ func (x *SyncRequest) String() string {
 func (*SyncRequest) ProtoMessage() {}
 
 func (x *SyncRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_zookeeper_proto_msgTypes[14]
+	mi := &file_zookeeper_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -844,7 +1265,7 @@ func (x *SyncRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SyncRequest.ProtoReflect.Descriptor instead.
 func (*SyncRequest) Descriptor() ([]byte, []int) {
-	return file_zookeeper_proto_rawDescGZIP(), []int{14}
+	return file_zookeeper_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *SyncRequest) GetPath() string {
@@ -863,7 +1284,7 @@ type SyncResponse struct {
 func (x *SyncResponse) Reset() {
 	*x = SyncResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zookeeper_proto_msgTypes[15]
+		mi := &file_zookeeper_proto_msgTypes[17]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -876,7 +1297,7 @@ func (x *SyncResponse) String() string {
 func (*SyncResponse) ProtoMessage() {}
 
 func (x *SyncResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_zookeeper_proto_msgTypes[15]
+	mi := &file_zookeeper_proto_msgTypes[17]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -889,44 +1310,35 @@ func (x *SyncResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SyncResponse.ProtoReflect.Descriptor instead.
 func (*SyncResponse) Descriptor() ([]byte, []int) {
-	return file_zookeeper_proto_rawDescGZIP(), []int{15}
+	return file_zookeeper_proto_rawDescGZIP(), []int{17}
 }
 
-type ZookeeperRequest struct {
+type GetACLRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// Types that are assignable to Message:
-	//
-	//	*ZookeeperRequest_Heartbeat
-	//	*ZookeeperRequest_Create
-	//	*ZookeeperRequest_Delete
-	//	*ZookeeperRequest_Exists
-	//	*ZookeeperRequest_GetData
-	//	*ZookeeperRequest_SetData
-	//	*ZookeeperRequest_GetChildren
-	//	*ZookeeperRequest_Sync
-	Message isZookeeperRequest_Message `protobuf_oneof:"message"`
+	// The virtual file path to the ZNode we are checking.
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
 }
 
-func (x *ZookeeperRequest) Reset() {
-	*x = ZookeeperRequest{}
+func (x *GetACLRequest) Reset() {
+	*x = GetACLRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zookeeper_proto_msgTypes[16]
+		mi := &file_zookeeper_proto_msgTypes[18]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ZookeeperRequest) String() string {
+func (x *GetACLRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ZookeeperRequest) ProtoMessage() {}
+func (*GetACLRequest) ProtoMessage() {}
 
-func (x *ZookeeperRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_zookeeper_proto_msgTypes[16]
+func (x *GetACLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zookeeper_proto_msgTypes[18]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -937,177 +1349,207 @@ func (x *ZookeeperRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ZookeeperRequest.ProtoReflect.Descriptor instead.
-func (*ZookeeperRequest) Descriptor() ([]byte, []int) {
-	return file_zookeeper_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use GetACLRequest.ProtoReflect.Descriptor instead.
+func (*GetACLRequest) Descriptor() ([]byte, []int) {
+	return file_zookeeper_proto_rawDescGZIP(), []int{18}
 }
 
-func (m *ZookeeperRequest) GetMessage() isZookeeperRequest_Message {
-	if m != nil {
-		return m.Message
+func (x *GetACLRequest) GetPath() string {
+	if x != nil {
+		return x.Path
 	}
-	return nil
+	return ""
 }
 
-func (x *ZookeeperRequest) GetHeartbeat() *HeartbeatRequest {
-	if x, ok := x.GetMessage().(*ZookeeperRequest_Heartbeat); ok {
-		return x.Heartbeat
-	}
-	return nil
-}
+type GetACLResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 
-func (x *ZookeeperRequest) GetCreate() *CreateRequest {
-	if x, ok := x.GetMessage().(*ZookeeperRequest_Create); ok {
-		return x.Create
-	}
-	return nil
+	// The ACL of the ZNode.
+	Acl []*ACL `protobuf:"bytes,1,rep,name=acl,proto3" json:"acl,omitempty"`
+	// The version of the ACL. This is incremented each time the ACL is set, separately from the version of the data.
+	Aversion int64 `protobuf:"varint,2,opt,name=aversion,proto3" json:"aversion,omitempty"`
 }
 
-func (x *ZookeeperRequest) GetDelete() *DeleteRequest {
-	if x, ok := x.GetMessage().(*ZookeeperRequest_Delete); ok {
-		return x.Delete
+func (x *GetACLResponse) Reset() {
+	*x = GetACLResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zookeeper_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	return nil
 }
 
-func (x *ZookeeperRequest) GetExists() *ExistsRequest {
-	if x, ok := x.GetMessage().(*ZookeeperRequest_Exists); ok {
-		return x.Exists
-	}
-	return nil
+func (x *GetACLResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *ZookeeperRequest) GetGetData() *GetDataRequest {
-	if x, ok := x.GetMessage().(*ZookeeperRequest_GetData); ok {
-		return x.GetData
+func (*GetACLResponse) ProtoMessage() {}
+
+func (x *GetACLResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_zookeeper_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *ZookeeperRequest) GetSetData() *SetDataRequest {
-	if x, ok := x.GetMessage().(*ZookeeperRequest_SetData); ok {
-		return x.SetData
-	}
-	return nil
+// Deprecated: Use GetACLResponse.ProtoReflect.Descriptor instead.
+func (*GetACLResponse) Descriptor() ([]byte, []int) {
+	return file_zookeeper_proto_rawDescGZIP(), []int{19}
 }
 
-func (x *ZookeeperRequest) GetGetChildren() *GetChildrenRequest {
-	if x, ok := x.GetMessage().(*ZookeeperRequest_GetChildren); ok {
-		return x.GetChildren
+func (x *GetACLResponse) GetAcl() []*ACL {
+	if x != nil {
+		return x.Acl
 	}
 	return nil
 }
 
-func (x *ZookeeperRequest) GetSync() *SyncRequest {
-	if x, ok := x.GetMessage().(*ZookeeperRequest_Sync); ok {
-		return x.Sync
+func (x *GetACLResponse) GetAversion() int64 {
+	if x != nil {
+		return x.Aversion
 	}
-	return nil
+	return 0
 }
 
-type isZookeeperRequest_Message interface {
-	isZookeeperRequest_Message()
-}
+type SetACLRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 
-type ZookeeperRequest_Heartbeat struct {
-	// To prevent the session from timing out, the ZooKeeper client library sends a heartbeat after the session
-	// has been idle for s/3 ms and switch to a new server if it has not heard from a server for 2s/3 ms,
-	// where s is the session timeout in milliseconds.
-	Heartbeat *HeartbeatRequest `protobuf:"bytes,1,opt,name=heartbeat,proto3,oneof"`
+	// The virtual file path to the ZNode we are changing.
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// The ACL that replaces the current ACL of the ZNode.
+	Acl []*ACL `protobuf:"bytes,2,rep,name=acl,proto3" json:"acl,omitempty"`
+	// The ACL version we expect this ZNode to be at. Pass -1 to skip the version check.
+	Aversion int64 `protobuf:"varint,3,opt,name=aversion,proto3" json:"aversion,omitempty"`
 }
 
-type ZookeeperRequest_Create struct {
-	// Create creates a ZNode with path name path, stores data in it, and returns the name of the new ZNode
-	// Flags can also be passed to pick certain attributes you want the ZNode to have.
-	Create *CreateRequest `protobuf:"bytes,2,opt,name=create,proto3,oneof"`
+func (x *SetACLRequest) Reset() {
+	*x = SetACLRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zookeeper_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
 }
 
-type ZookeeperRequest_Delete struct {
-	// Delete deletes the ZNode at the given path if that ZNode is at the expected version.
-	Delete *DeleteRequest `protobuf:"bytes,3,opt,name=delete,proto3,oneof"`
+func (x *SetACLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-type ZookeeperRequest_Exists struct {
-	// Exists returns true if the ZNode with path name path exists, and returns false otherwise. The watch flag
-	// enables a client to set a watch on the ZNode.
-	Exists *ExistsRequest `protobuf:"bytes,4,opt,name=exists,proto3,oneof"`
-}
+func (*SetACLRequest) ProtoMessage() {}
 
-type ZookeeperRequest_GetData struct {
-	// GetData returns the data and metadata, such as version information, associated with the ZNode.
-	// The watch flag works in the same way as it does for exists(), except that ZooKeeper does not set the watch
-	// if the ZNode does not exist.
-	GetData *GetDataRequest `protobuf:"bytes,5,opt,name=get_data,json=getData,proto3,oneof"`
+func (x *SetACLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zookeeper_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-type ZookeeperRequest_SetData struct {
-	// SetData writes data to the ZNode path if the version number is the current version of the ZNode.
-	SetData *SetDataRequest `protobuf:"bytes,6,opt,name=set_data,json=setData,proto3,oneof"`
+// Deprecated: Use SetACLRequest.ProtoReflect.Descriptor instead.
+func (*SetACLRequest) Descriptor() ([]byte, []int) {
+	return file_zookeeper_proto_rawDescGZIP(), []int{20}
 }
 
-type ZookeeperRequest_GetChildren struct {
-	// GetChildren returns the set of names of the children of a ZNode.
-	GetChildren *GetChildrenRequest `protobuf:"bytes,7,opt,name=get_children,json=getChildren,proto3,oneof"`
+func (x *SetACLRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
 }
 
-type ZookeeperRequest_Sync struct {
-	// Sync waits for all updates pending at the start of the operation to propagate to the server
-	// that the client is connected to. The path is currently ignored. (Using path is not discussed in the white paper)
-	Sync *SyncRequest `protobuf:"bytes,8,opt,name=sync,proto3,oneof"`
+func (x *SetACLRequest) GetAcl() []*ACL {
+	if x != nil {
+		return x.Acl
+	}
+	return nil
 }
 
-func (*ZookeeperRequest_Heartbeat) isZookeeperRequest_Message() {}
-
-func (*ZookeeperRequest_Create) isZookeeperRequest_Message() {}
+func (x *SetACLRequest) GetAversion() int64 {
+	if x != nil {
+		return x.Aversion
+	}
+	return 0
+}
 
-func (*ZookeeperRequest_Delete) isZookeeperRequest_Message() {}
+type SetACLResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
 
-func (*ZookeeperRequest_Exists) isZookeeperRequest_Message() {}
+func (x *SetACLResponse) Reset() {
+	*x = SetACLResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zookeeper_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
 
-func (*ZookeeperRequest_GetData) isZookeeperRequest_Message() {}
+func (x *SetACLResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
 
-func (*ZookeeperRequest_SetData) isZookeeperRequest_Message() {}
+func (*SetACLResponse) ProtoMessage() {}
 
-func (*ZookeeperRequest_GetChildren) isZookeeperRequest_Message() {}
+func (x *SetACLResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_zookeeper_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
 
-func (*ZookeeperRequest_Sync) isZookeeperRequest_Message() {}
+// Deprecated: Use SetACLResponse.ProtoReflect.Descriptor instead.
+func (*SetACLResponse) Descriptor() ([]byte, []int) {
+	return file_zookeeper_proto_rawDescGZIP(), []int{21}
+}
 
-type ZookeeperResponse struct {
+type AddAuthRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// Types that are assignable to Message:
-	//
-	//	*ZookeeperResponse_Create
-	//	*ZookeeperResponse_Delete
-	//	*ZookeeperResponse_Exists
-	//	*ZookeeperResponse_GetData
-	//	*ZookeeperResponse_SetData
-	//	*ZookeeperResponse_GetChildren
-	//	*ZookeeperResponse_Sync
-	//	*ZookeeperResponse_WatchEvent
-	//	*ZookeeperResponse_Heartbeat
-	Message isZookeeperResponse_Message `protobuf_oneof:"message"`
+	// The authentication scheme the credentials are for. i.e. "digest"
+	Scheme string `protobuf:"bytes,1,opt,name=scheme,proto3" json:"scheme,omitempty"`
+	// The credentials, in the format the scheme expects. i.e. "user:password" for the digest scheme.
+	Auth []byte `protobuf:"bytes,2,opt,name=auth,proto3" json:"auth,omitempty"`
 }
 
-func (x *ZookeeperResponse) Reset() {
-	*x = ZookeeperResponse{}
+func (x *AddAuthRequest) Reset() {
+	*x = AddAuthRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zookeeper_proto_msgTypes[17]
+		mi := &file_zookeeper_proto_msgTypes[22]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ZookeeperResponse) String() string {
+func (x *AddAuthRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ZookeeperResponse) ProtoMessage() {}
+func (*AddAuthRequest) ProtoMessage() {}
 
-func (x *ZookeeperResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_zookeeper_proto_msgTypes[17]
+func (x *AddAuthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zookeeper_proto_msgTypes[22]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1118,16 +1560,778 @@ func (x *ZookeeperResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ZookeeperResponse.ProtoReflect.Descriptor instead.
-func (*ZookeeperResponse) Descriptor() ([]byte, []int) {
-	return file_zookeeper_proto_rawDescGZIP(), []int{17}
+// Deprecated: Use AddAuthRequest.ProtoReflect.Descriptor instead.
+func (*AddAuthRequest) Descriptor() ([]byte, []int) {
+	return file_zookeeper_proto_rawDescGZIP(), []int{22}
 }
 
-func (m *ZookeeperResponse) GetMessage() isZookeeperResponse_Message {
-	if m != nil {
-		return m.Message
+func (x *AddAuthRequest) GetScheme() string {
+	if x != nil {
+		return x.Scheme
 	}
-	return nil
+	return ""
+}
+
+func (x *AddAuthRequest) GetAuth() []byte {
+	if x != nil {
+		return x.Auth
+	}
+	return nil
+}
+
+type AddAuthResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *AddAuthResponse) Reset() {
+	*x = AddAuthResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zookeeper_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddAuthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddAuthResponse) ProtoMessage() {}
+
+func (x *AddAuthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_zookeeper_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddAuthResponse.ProtoReflect.Descriptor instead.
+func (*AddAuthResponse) Descriptor() ([]byte, []int) {
+	return file_zookeeper_proto_rawDescGZIP(), []int{23}
+}
+
+type AddWatchRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The virtual file path to the ZNode we'd like to watch. This can be the root, "/".
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// Unlike the watches set by exists, getData, and getChildren, these watches aren't removed once they fire.
+	// They stay until they are removed or the session is closed.
+	Mode AddWatchRequest_Mode `protobuf:"varint,2,opt,name=mode,proto3,enum=zookeeper.AddWatchRequest_Mode" json:"mode,omitempty"`
+}
+
+func (x *AddWatchRequest) Reset() {
+	*x = AddWatchRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zookeeper_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddWatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddWatchRequest) ProtoMessage() {}
+
+func (x *AddWatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zookeeper_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddWatchRequest.ProtoReflect.Descriptor instead.
+func (*AddWatchRequest) Descriptor() ([]byte, []int) {
+	return file_zookeeper_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *AddWatchRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *AddWatchRequest) GetMode() AddWatchRequest_Mode {
+	if x != nil {
+		return x.Mode
+	}
+	return AddWatchRequest_MODE_UNSET
+}
+
+type AddWatchResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *AddWatchResponse) Reset() {
+	*x = AddWatchResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zookeeper_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddWatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddWatchResponse) ProtoMessage() {}
+
+func (x *AddWatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_zookeeper_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddWatchResponse.ProtoReflect.Descriptor instead.
+func (*AddWatchResponse) Descriptor() ([]byte, []int) {
+	return file_zookeeper_proto_rawDescGZIP(), []int{25}
+}
+
+type RemoveWatchesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The virtual file path to the ZNode whose watches we'd like to remove. This can be the root, "/".
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// The type of watches to remove. Only the watches of the session sending the request are removed.
+	Type RemoveWatchesRequest_WatcherType `protobuf:"varint,2,opt,name=type,proto3,enum=zookeeper.RemoveWatchesRequest_WatcherType" json:"type,omitempty"`
+}
+
+func (x *RemoveWatchesRequest) Reset() {
+	*x = RemoveWatchesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zookeeper_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemoveWatchesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveWatchesRequest) ProtoMessage() {}
+
+func (x *RemoveWatchesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zookeeper_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveWatchesRequest.ProtoReflect.Descriptor instead.
+func (*RemoveWatchesRequest) Descriptor() ([]byte, []int) {
+	return file_zookeeper_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *RemoveWatchesRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *RemoveWatchesRequest) GetType() RemoveWatchesRequest_WatcherType {
+	if x != nil {
+		return x.Type
+	}
+	return RemoveWatchesRequest_WATCHER_TYPE_UNSET
+}
+
+type RemoveWatchesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *RemoveWatchesResponse) Reset() {
+	*x = RemoveWatchesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zookeeper_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemoveWatchesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveWatchesResponse) ProtoMessage() {}
+
+func (x *RemoveWatchesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_zookeeper_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveWatchesResponse.ProtoReflect.Descriptor instead.
+func (*RemoveWatchesResponse) Descriptor() ([]byte, []int) {
+	return file_zookeeper_proto_rawDescGZIP(), []int{27}
+}
+
+type ReconfigRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Servers to add to the ensemble, in the same format as the config file. i.e. "server.4=host:2888:3888;2181"
+	// If a server with the same id already exists, then it is replaced. This is how we change a server's address.
+	JoiningServers []string `protobuf:"bytes,1,rep,name=joining_servers,json=joiningServers,proto3" json:"joining_servers,omitempty"`
+	// The ids of the servers to remove from the ensemble.
+	LeavingServers []int64 `protobuf:"varint,2,rep,packed,name=leaving_servers,json=leavingServers,proto3" json:"leaving_servers,omitempty"`
+	// The config version we expect the ensemble to be at. Pass -1 to skip the version check.
+	FromConfig int64 `protobuf:"varint,3,opt,name=from_config,json=fromConfig,proto3" json:"from_config,omitempty"`
+	// Groups for hierarchical quorums, in the same format as the config file. i.e. "group.2=3:4" Each group
+	// replaces the old members of the group, so a joining participant is listed along with the rest of its group.
+	Groups []string `protobuf:"bytes,4,rep,name=groups,proto3" json:"groups,omitempty"`
+	// Weights for weighted quorums, in the same format as the config file. i.e. "weight.4=2"
+	Weights []string `protobuf:"bytes,5,rep,name=weights,proto3" json:"weights,omitempty"`
+}
+
+func (x *ReconfigRequest) Reset() {
+	*x = ReconfigRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zookeeper_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReconfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconfigRequest) ProtoMessage() {}
+
+func (x *ReconfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zookeeper_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconfigRequest.ProtoReflect.Descriptor instead.
+func (*ReconfigRequest) Descriptor() ([]byte, []int) {
+	return file_zookeeper_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ReconfigRequest) GetJoiningServers() []string {
+	if x != nil {
+		return x.JoiningServers
+	}
+	return nil
+}
+
+func (x *ReconfigRequest) GetLeavingServers() []int64 {
+	if x != nil {
+		return x.LeavingServers
+	}
+	return nil
+}
+
+func (x *ReconfigRequest) GetFromConfig() int64 {
+	if x != nil {
+		return x.FromConfig
+	}
+	return 0
+}
+
+func (x *ReconfigRequest) GetGroups() []string {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+func (x *ReconfigRequest) GetWeights() []string {
+	if x != nil {
+		return x.Weights
+	}
+	return nil
+}
+
+type ReconfigResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The new config of the ensemble. This is the same data that is stored in /zookeeper/config.
+	Config []byte `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+	// The version of the new config.
+	Version int64 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+}
+
+func (x *ReconfigResponse) Reset() {
+	*x = ReconfigResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zookeeper_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReconfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconfigResponse) ProtoMessage() {}
+
+func (x *ReconfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_zookeeper_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconfigResponse.ProtoReflect.Descriptor instead.
+func (*ReconfigResponse) Descriptor() ([]byte, []int) {
+	return file_zookeeper_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ReconfigResponse) GetConfig() []byte {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+func (x *ReconfigResponse) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type ZookeeperRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The id the client gave this request. Ids increase with each request the client sends in a session, which lets the
+	// server recognize requests the client resent after reconnecting. Heartbeats don't have an id.
+	// We use a high field number to keep it out of the way of the messages below.
+	Xid int64 `protobuf:"varint,100,opt,name=xid,proto3" json:"xid,omitempty"`
+	// Types that are assignable to Message:
+	//
+	//	*ZookeeperRequest_Heartbeat
+	//	*ZookeeperRequest_Create
+	//	*ZookeeperRequest_Delete
+	//	*ZookeeperRequest_Exists
+	//	*ZookeeperRequest_GetData
+	//	*ZookeeperRequest_SetData
+	//	*ZookeeperRequest_GetChildren
+	//	*ZookeeperRequest_Sync
+	//	*ZookeeperRequest_Reconfig
+	//	*ZookeeperRequest_Connect
+	//	*ZookeeperRequest_GetAcl
+	//	*ZookeeperRequest_SetAcl
+	//	*ZookeeperRequest_AddAuth
+	//	*ZookeeperRequest_AddWatch
+	//	*ZookeeperRequest_RemoveWatches
+	Message isZookeeperRequest_Message `protobuf_oneof:"message"`
+}
+
+func (x *ZookeeperRequest) Reset() {
+	*x = ZookeeperRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zookeeper_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ZookeeperRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ZookeeperRequest) ProtoMessage() {}
+
+func (x *ZookeeperRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zookeeper_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ZookeeperRequest.ProtoReflect.Descriptor instead.
+func (*ZookeeperRequest) Descriptor() ([]byte, []int) {
+	return file_zookeeper_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ZookeeperRequest) GetXid() int64 {
+	if x != nil {
+		return x.Xid
+	}
+	return 0
+}
+
+func (m *ZookeeperRequest) GetMessage() isZookeeperRequest_Message {
+	if m != nil {
+		return m.Message
+	}
+	return nil
+}
+
+func (x *ZookeeperRequest) GetHeartbeat() *HeartbeatRequest {
+	if x, ok := x.GetMessage().(*ZookeeperRequest_Heartbeat); ok {
+		return x.Heartbeat
+	}
+	return nil
+}
+
+func (x *ZookeeperRequest) GetCreate() *CreateRequest {
+	if x, ok := x.GetMessage().(*ZookeeperRequest_Create); ok {
+		return x.Create
+	}
+	return nil
+}
+
+func (x *ZookeeperRequest) GetDelete() *DeleteRequest {
+	if x, ok := x.GetMessage().(*ZookeeperRequest_Delete); ok {
+		return x.Delete
+	}
+	return nil
+}
+
+func (x *ZookeeperRequest) GetExists() *ExistsRequest {
+	if x, ok := x.GetMessage().(*ZookeeperRequest_Exists); ok {
+		return x.Exists
+	}
+	return nil
+}
+
+func (x *ZookeeperRequest) GetGetData() *GetDataRequest {
+	if x, ok := x.GetMessage().(*ZookeeperRequest_GetData); ok {
+		return x.GetData
+	}
+	return nil
+}
+
+func (x *ZookeeperRequest) GetSetData() *SetDataRequest {
+	if x, ok := x.GetMessage().(*ZookeeperRequest_SetData); ok {
+		return x.SetData
+	}
+	return nil
+}
+
+func (x *ZookeeperRequest) GetGetChildren() *GetChildrenRequest {
+	if x, ok := x.GetMessage().(*ZookeeperRequest_GetChildren); ok {
+		return x.GetChildren
+	}
+	return nil
+}
+
+func (x *ZookeeperRequest) GetSync() *SyncRequest {
+	if x, ok := x.GetMessage().(*ZookeeperRequest_Sync); ok {
+		return x.Sync
+	}
+	return nil
+}
+
+func (x *ZookeeperRequest) GetReconfig() *ReconfigRequest {
+	if x, ok := x.GetMessage().(*ZookeeperRequest_Reconfig); ok {
+		return x.Reconfig
+	}
+	return nil
+}
+
+func (x *ZookeeperRequest) GetConnect() *ConnectRequest {
+	if x, ok := x.GetMessage().(*ZookeeperRequest_Connect); ok {
+		return x.Connect
+	}
+	return nil
+}
+
+func (x *ZookeeperRequest) GetGetAcl() *GetACLRequest {
+	if x, ok := x.GetMessage().(*ZookeeperRequest_GetAcl); ok {
+		return x.GetAcl
+	}
+	return nil
+}
+
+func (x *ZookeeperRequest) GetSetAcl() *SetACLRequest {
+	if x, ok := x.GetMessage().(*ZookeeperRequest_SetAcl); ok {
+		return x.SetAcl
+	}
+	return nil
+}
+
+func (x *ZookeeperRequest) GetAddAuth() *AddAuthRequest {
+	if x, ok := x.GetMessage().(*ZookeeperRequest_AddAuth); ok {
+		return x.AddAuth
+	}
+	return nil
+}
+
+func (x *ZookeeperRequest) GetAddWatch() *AddWatchRequest {
+	if x, ok := x.GetMessage().(*ZookeeperRequest_AddWatch); ok {
+		return x.AddWatch
+	}
+	return nil
+}
+
+func (x *ZookeeperRequest) GetRemoveWatches() *RemoveWatchesRequest {
+	if x, ok := x.GetMessage().(*ZookeeperRequest_RemoveWatches); ok {
+		return x.RemoveWatches
+	}
+	return nil
+}
+
+type isZookeeperRequest_Message interface {
+	isZookeeperRequest_Message()
+}
+
+type ZookeeperRequest_Heartbeat struct {
+	// To prevent the session from timing out, the ZooKeeper client library sends a heartbeat after the session
+	// has been idle for s/3 ms and switch to a new server if it has not heard from a server for 2s/3 ms,
+	// where s is the session timeout in milliseconds.
+	Heartbeat *HeartbeatRequest `protobuf:"bytes,1,opt,name=heartbeat,proto3,oneof"`
+}
+
+type ZookeeperRequest_Create struct {
+	// Create creates a ZNode with path name path, stores data in it, and returns the name of the new ZNode
+	// Flags can also be passed to pick certain attributes you want the ZNode to have.
+	Create *CreateRequest `protobuf:"bytes,2,opt,name=create,proto3,oneof"`
+}
+
+type ZookeeperRequest_Delete struct {
+	// Delete deletes the ZNode at the given path if that ZNode is at the expected version.
+	Delete *DeleteRequest `protobuf:"bytes,3,opt,name=delete,proto3,oneof"`
+}
+
+type ZookeeperRequest_Exists struct {
+	// Exists returns true if the ZNode with path name path exists, and returns false otherwise. The watch flag
+	// enables a client to set a watch on the ZNode.
+	Exists *ExistsRequest `protobuf:"bytes,4,opt,name=exists,proto3,oneof"`
+}
+
+type ZookeeperRequest_GetData struct {
+	// GetData returns the data and metadata, such as version information, associated with the ZNode.
+	// The watch flag works in the same way as it does for exists(), except that ZooKeeper does not set the watch
+	// if the ZNode does not exist.
+	GetData *GetDataRequest `protobuf:"bytes,5,opt,name=get_data,json=getData,proto3,oneof"`
+}
+
+type ZookeeperRequest_SetData struct {
+	// SetData writes data to the ZNode path if the version number is the current version of the ZNode.
+	SetData *SetDataRequest `protobuf:"bytes,6,opt,name=set_data,json=setData,proto3,oneof"`
+}
+
+type ZookeeperRequest_GetChildren struct {
+	// GetChildren returns the set of names of the children of a ZNode.
+	GetChildren *GetChildrenRequest `protobuf:"bytes,7,opt,name=get_children,json=getChildren,proto3,oneof"`
+}
+
+type ZookeeperRequest_Sync struct {
+	// Sync waits for all updates pending at the start of the operation to propagate to the server
+	// that the client is connected to. The path is currently ignored. (Using path is not discussed in the white paper)
+	Sync *SyncRequest `protobuf:"bytes,8,opt,name=sync,proto3,oneof"`
+}
+
+type ZookeeperRequest_Reconfig struct {
+	// Reconfig adds or removes servers from the ensemble. The change is committed like any other transaction, so
+	// every server switches to the new config at the same point in the log.
+	Reconfig *ReconfigRequest `protobuf:"bytes,9,opt,name=reconfig,proto3,oneof"`
+}
+
+type ZookeeperRequest_Connect struct {
+	// Connect has to be the first message on the stream, and can't be sent again after that.
+	Connect *ConnectRequest `protobuf:"bytes,10,opt,name=connect,proto3,oneof"`
+}
+
+type ZookeeperRequest_GetAcl struct {
+	// GetACL returns the ACL of the ZNode, and the version of the ACL.
+	GetAcl *GetACLRequest `protobuf:"bytes,11,opt,name=get_acl,json=getAcl,proto3,oneof"`
+}
+
+type ZookeeperRequest_SetAcl struct {
+	// SetACL replaces the ACL of the ZNode if the ACL is at the expected version.
+	SetAcl *SetACLRequest `protobuf:"bytes,12,opt,name=set_acl,json=setAcl,proto3,oneof"`
+}
+
+type ZookeeperRequest_AddAuth struct {
+	// AddAuth authenticates the session with the credentials. Every id the session authenticates as is checked
+	// against ACLs for the rest of the session. If the credentials are invalid, the session is closed.
+	AddAuth *AddAuthRequest `protobuf:"bytes,13,opt,name=add_auth,json=addAuth,proto3,oneof"`
+}
+
+type ZookeeperRequest_AddWatch struct {
+	// AddWatch sets a watch that fires for every change instead of only the next one.
+	AddWatch *AddWatchRequest `protobuf:"bytes,14,opt,name=add_watch,json=addWatch,proto3,oneof"`
+}
+
+type ZookeeperRequest_RemoveWatches struct {
+	// RemoveWatches removes the watches of the given type that the session has set on the ZNode.
+	RemoveWatches *RemoveWatchesRequest `protobuf:"bytes,15,opt,name=remove_watches,json=removeWatches,proto3,oneof"`
+}
+
+func (*ZookeeperRequest_Heartbeat) isZookeeperRequest_Message() {}
+
+func (*ZookeeperRequest_Create) isZookeeperRequest_Message() {}
+
+func (*ZookeeperRequest_Delete) isZookeeperRequest_Message() {}
+
+func (*ZookeeperRequest_Exists) isZookeeperRequest_Message() {}
+
+func (*ZookeeperRequest_GetData) isZookeeperRequest_Message() {}
+
+func (*ZookeeperRequest_SetData) isZookeeperRequest_Message() {}
+
+func (*ZookeeperRequest_GetChildren) isZookeeperRequest_Message() {}
+
+func (*ZookeeperRequest_Sync) isZookeeperRequest_Message() {}
+
+func (*ZookeeperRequest_Reconfig) isZookeeperRequest_Message() {}
+
+func (*ZookeeperRequest_Connect) isZookeeperRequest_Message() {}
+
+func (*ZookeeperRequest_GetAcl) isZookeeperRequest_Message() {}
+
+func (*ZookeeperRequest_SetAcl) isZookeeperRequest_Message() {}
+
+func (*ZookeeperRequest_AddAuth) isZookeeperRequest_Message() {}
+
+func (*ZookeeperRequest_AddWatch) isZookeeperRequest_Message() {}
+
+func (*ZookeeperRequest_RemoveWatches) isZookeeperRequest_Message() {}
+
+type ZookeeperResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The id of the request this is a response to. This is unset for heartbeats and watch events.
+	Xid int64 `protobuf:"varint,100,opt,name=xid,proto3" json:"xid,omitempty"`
+	// The zxid of the last transaction the server has applied. Clients use this to avoid reconnecting to a server that
+	// is behind what they have already seen.
+	Zxid int64 `protobuf:"varint,101,opt,name=zxid,proto3" json:"zxid,omitempty"`
+	// Types that are assignable to Message:
+	//
+	//	*ZookeeperResponse_Create
+	//	*ZookeeperResponse_Delete
+	//	*ZookeeperResponse_Exists
+	//	*ZookeeperResponse_GetData
+	//	*ZookeeperResponse_SetData
+	//	*ZookeeperResponse_GetChildren
+	//	*ZookeeperResponse_Sync
+	//	*ZookeeperResponse_WatchEvent
+	//	*ZookeeperResponse_Heartbeat
+	//	*ZookeeperResponse_Reconfig
+	//	*ZookeeperResponse_Connect
+	//	*ZookeeperResponse_GetAcl
+	//	*ZookeeperResponse_SetAcl
+	//	*ZookeeperResponse_AddAuth
+	//	*ZookeeperResponse_AddWatch
+	//	*ZookeeperResponse_RemoveWatches
+	//	*ZookeeperResponse_Error
+	Message isZookeeperResponse_Message `protobuf_oneof:"message"`
+}
+
+func (x *ZookeeperResponse) Reset() {
+	*x = ZookeeperResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zookeeper_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ZookeeperResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ZookeeperResponse) ProtoMessage() {}
+
+func (x *ZookeeperResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_zookeeper_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ZookeeperResponse.ProtoReflect.Descriptor instead.
+func (*ZookeeperResponse) Descriptor() ([]byte, []int) {
+	return file_zookeeper_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ZookeeperResponse) GetXid() int64 {
+	if x != nil {
+		return x.Xid
+	}
+	return 0
+}
+
+func (x *ZookeeperResponse) GetZxid() int64 {
+	if x != nil {
+		return x.Zxid
+	}
+	return 0
+}
+
+func (m *ZookeeperResponse) GetMessage() isZookeeperResponse_Message {
+	if m != nil {
+		return m.Message
+	}
+	return nil
 }
 
 func (x *ZookeeperResponse) GetCreate() *CreateResponse {
@@ -1186,9 +2390,65 @@ func (x *ZookeeperResponse) GetWatchEvent() *WatchEvent {
 	return nil
 }
 
-func (x *ZookeeperResponse) GetHeartbeat() *HeartbeatResponse {
-	if x, ok := x.GetMessage().(*ZookeeperResponse_Heartbeat); ok {
-		return x.Heartbeat
+func (x *ZookeeperResponse) GetHeartbeat() *HeartbeatResponse {
+	if x, ok := x.GetMessage().(*ZookeeperResponse_Heartbeat); ok {
+		return x.Heartbeat
+	}
+	return nil
+}
+
+func (x *ZookeeperResponse) GetReconfig() *ReconfigResponse {
+	if x, ok := x.GetMessage().(*ZookeeperResponse_Reconfig); ok {
+		return x.Reconfig
+	}
+	return nil
+}
+
+func (x *ZookeeperResponse) GetConnect() *ConnectResponse {
+	if x, ok := x.GetMessage().(*ZookeeperResponse_Connect); ok {
+		return x.Connect
+	}
+	return nil
+}
+
+func (x *ZookeeperResponse) GetGetAcl() *GetACLResponse {
+	if x, ok := x.GetMessage().(*ZookeeperResponse_GetAcl); ok {
+		return x.GetAcl
+	}
+	return nil
+}
+
+func (x *ZookeeperResponse) GetSetAcl() *SetACLResponse {
+	if x, ok := x.GetMessage().(*ZookeeperResponse_SetAcl); ok {
+		return x.SetAcl
+	}
+	return nil
+}
+
+func (x *ZookeeperResponse) GetAddAuth() *AddAuthResponse {
+	if x, ok := x.GetMessage().(*ZookeeperResponse_AddAuth); ok {
+		return x.AddAuth
+	}
+	return nil
+}
+
+func (x *ZookeeperResponse) GetAddWatch() *AddWatchResponse {
+	if x, ok := x.GetMessage().(*ZookeeperResponse_AddWatch); ok {
+		return x.AddWatch
+	}
+	return nil
+}
+
+func (x *ZookeeperResponse) GetRemoveWatches() *RemoveWatchesResponse {
+	if x, ok := x.GetMessage().(*ZookeeperResponse_RemoveWatches); ok {
+		return x.RemoveWatches
+	}
+	return nil
+}
+
+func (x *ZookeeperResponse) GetError() *ErrorResponse {
+	if x, ok := x.GetMessage().(*ZookeeperResponse_Error); ok {
+		return x.Error
 	}
 	return nil
 }
@@ -1233,6 +2493,39 @@ type ZookeeperResponse_Heartbeat struct {
 	Heartbeat *HeartbeatResponse `protobuf:"bytes,9,opt,name=heartbeat,proto3,oneof"`
 }
 
+type ZookeeperResponse_Reconfig struct {
+	Reconfig *ReconfigResponse `protobuf:"bytes,10,opt,name=reconfig,proto3,oneof"`
+}
+
+type ZookeeperResponse_Connect struct {
+	Connect *ConnectResponse `protobuf:"bytes,11,opt,name=connect,proto3,oneof"`
+}
+
+type ZookeeperResponse_GetAcl struct {
+	GetAcl *GetACLResponse `protobuf:"bytes,12,opt,name=get_acl,json=getAcl,proto3,oneof"`
+}
+
+type ZookeeperResponse_SetAcl struct {
+	SetAcl *SetACLResponse `protobuf:"bytes,13,opt,name=set_acl,json=setAcl,proto3,oneof"`
+}
+
+type ZookeeperResponse_AddAuth struct {
+	AddAuth *AddAuthResponse `protobuf:"bytes,14,opt,name=add_auth,json=addAuth,proto3,oneof"`
+}
+
+type ZookeeperResponse_AddWatch struct {
+	AddWatch *AddWatchResponse `protobuf:"bytes,15,opt,name=add_watch,json=addWatch,proto3,oneof"`
+}
+
+type ZookeeperResponse_RemoveWatches struct {
+	RemoveWatches *RemoveWatchesResponse `protobuf:"bytes,16,opt,name=remove_watches,json=removeWatches,proto3,oneof"`
+}
+
+type ZookeeperResponse_Error struct {
+	// Error is sent in place of the usual response when the request failed.
+	Error *ErrorResponse `protobuf:"bytes,17,opt,name=error,proto3,oneof"`
+}
+
 func (*ZookeeperResponse_Create) isZookeeperResponse_Message() {}
 
 func (*ZookeeperResponse_Delete) isZookeeperResponse_Message() {}
@@ -1251,141 +2544,397 @@ func (*ZookeeperResponse_WatchEvent) isZookeeperResponse_Message() {}
 
 func (*ZookeeperResponse_Heartbeat) isZookeeperResponse_Message() {}
 
+func (*ZookeeperResponse_Reconfig) isZookeeperResponse_Message() {}
+
+func (*ZookeeperResponse_Connect) isZookeeperResponse_Message() {}
+
+func (*ZookeeperResponse_GetAcl) isZookeeperResponse_Message() {}
+
+func (*ZookeeperResponse_SetAcl) isZookeeperResponse_Message() {}
+
+func (*ZookeeperResponse_AddAuth) isZookeeperResponse_Message() {}
+
+func (*ZookeeperResponse_AddWatch) isZookeeperResponse_Message() {}
+
+func (*ZookeeperResponse_RemoveWatches) isZookeeperResponse_Message() {}
+
+func (*ZookeeperResponse_Error) isZookeeperResponse_Message() {}
+
+// ErrorResponse is the response to a request that failed. Only the request failed, so the session can still be used.
+type ErrorResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// code is the gRPC status code of the error. i.e. PERMISSION_DENIED if the ACL doesn't allow the request.
+	Code    int32  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (x *ErrorResponse) Reset() {
+	*x = ErrorResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zookeeper_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ErrorResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErrorResponse) ProtoMessage() {}
+
+func (x *ErrorResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_zookeeper_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErrorResponse.ProtoReflect.Descriptor instead.
+func (*ErrorResponse) Descriptor() ([]byte, []int) {
+	return file_zookeeper_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ErrorResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ErrorResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
 var File_zookeeper_proto protoreflect.FileDescriptor
 
 var file_zookeeper_proto_rawDesc = []byte{
 	0x0a, 0x0f, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x12, 0x09, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x1a, 0x0b, 0x77, 0x61,
-	0x74, 0x63, 0x68, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x30, 0x0a, 0x10, 0x48, 0x65, 0x61,
+	0x6f, 0x12, 0x09, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x1a, 0x09, 0x61, 0x63,
+	0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0b, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x22, 0xe5, 0x02, 0x0a, 0x0e, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x69, 0x6d, 0x65, 0x6f,
+	0x75, 0x74, 0x5f, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69, 0x6d,
+	0x65, 0x6f, 0x75, 0x74, 0x4d, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72,
+	0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72,
+	0x64, 0x12, 0x1b, 0x0a, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x7a, 0x78, 0x69, 0x64, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x6c, 0x61, 0x73, 0x74, 0x5a, 0x78, 0x69, 0x64, 0x12, 0x21,
+	0x0a, 0x0c, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x77, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x18, 0x05,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x61, 0x74, 0x61, 0x57, 0x61, 0x74, 0x63, 0x68, 0x65,
+	0x73, 0x12, 0x23, 0x0a, 0x0d, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x5f, 0x77, 0x61, 0x74, 0x63, 0x68,
+	0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x57,
+	0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x12, 0x2d, 0x0a, 0x12, 0x70, 0x65, 0x72, 0x73, 0x69, 0x73,
+	0x74, 0x65, 0x6e, 0x74, 0x5f, 0x77, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x18, 0x07, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x11, 0x70, 0x65, 0x72, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x74, 0x57, 0x61,
+	0x74, 0x63, 0x68, 0x65, 0x73, 0x12, 0x40, 0x0a, 0x1c, 0x70, 0x65, 0x72, 0x73, 0x69, 0x73, 0x74,
+	0x65, 0x6e, 0x74, 0x5f, 0x72, 0x65, 0x63, 0x75, 0x72, 0x73, 0x69, 0x76, 0x65, 0x5f, 0x77, 0x61,
+	0x74, 0x63, 0x68, 0x65, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x1a, 0x70, 0x65, 0x72,
+	0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x63, 0x75, 0x72, 0x73, 0x69, 0x76, 0x65,
+	0x57, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x65, 0x78, 0x69, 0x73, 0x74,
+	0x5f, 0x77, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c,
+	0x65, 0x78, 0x69, 0x73, 0x74, 0x57, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x22, 0x6b, 0x0a, 0x0f,
+	0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x1d, 0x0a, 0x0a, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x5f, 0x6d, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x4d, 0x73, 0x12, 0x1d,
+	0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x1a, 0x0a,
+	0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x22, 0x30, 0x0a, 0x10, 0x48, 0x65, 0x61,
 	0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a,
 	0x0a, 0x73, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x73, 0x5f, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
 	0x03, 0x52, 0x08, 0x73, 0x65, 0x6e, 0x74, 0x54, 0x73, 0x4d, 0x73, 0x22, 0x39, 0x0a, 0x11, 0x48,
 	0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
 	0x12, 0x24, 0x0a, 0x0e, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x5f, 0x74, 0x73, 0x5f,
 	0x6d, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76,
-	0x65, 0x64, 0x54, 0x73, 0x4d, 0x73, 0x22, 0x9d, 0x01, 0x0a, 0x0d, 0x43, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x64, 0x54, 0x73, 0x4d, 0x73, 0x22, 0x97, 0x04, 0x0a, 0x0d, 0x43, 0x72, 0x65, 0x61, 0x74,
 	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68,
 	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x12, 0x0a, 0x04,
 	0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61,
-	0x12, 0x33, 0x0a, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0e, 0x32,
+	0x12, 0x37, 0x0a, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0e, 0x32,
 	0x1d, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x43, 0x72, 0x65, 0x61,
-	0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x46, 0x6c, 0x61, 0x67, 0x52, 0x05,
-	0x66, 0x6c, 0x61, 0x67, 0x73, 0x22, 0x2f, 0x0a, 0x04, 0x46, 0x6c, 0x61, 0x67, 0x12, 0x12, 0x0a,
-	0x0e, 0x46, 0x4c, 0x41, 0x47, 0x5f, 0x45, 0x50, 0x48, 0x45, 0x4d, 0x45, 0x52, 0x41, 0x4c, 0x10,
-	0x00, 0x12, 0x13, 0x0a, 0x0f, 0x46, 0x4c, 0x41, 0x47, 0x5f, 0x53, 0x45, 0x51, 0x55, 0x45, 0x4e,
-	0x54, 0x49, 0x41, 0x4c, 0x10, 0x01, 0x22, 0x30, 0x0a, 0x0e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1e, 0x0a, 0x0b, 0x7a, 0x5f, 0x6e, 0x6f,
-	0x64, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x7a,
-	0x4e, 0x6f, 0x64, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0x3d, 0x0a, 0x0d, 0x44, 0x65, 0x6c, 0x65,
-	0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74,
-	0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x18, 0x0a,
-	0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07,
-	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x10, 0x0a, 0x0e, 0x44, 0x65, 0x6c, 0x65, 0x74,
-	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x39, 0x0a, 0x0d, 0x45, 0x78, 0x69,
-	0x73, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61,
+	0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x46, 0x6c, 0x61, 0x67, 0x42, 0x02,
+	0x18, 0x01, 0x52, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x20, 0x0a, 0x03, 0x61, 0x63, 0x6c,
+	0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70,
+	0x65, 0x72, 0x2e, 0x41, 0x43, 0x4c, 0x52, 0x03, 0x61, 0x63, 0x6c, 0x12, 0x15, 0x0a, 0x06, 0x74,
+	0x74, 0x6c, 0x5f, 0x6d, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x74, 0x74, 0x6c,
+	0x4d, 0x73, 0x12, 0x37, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x23, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x43, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x22, 0x51, 0x0a, 0x04, 0x46,
+	0x6c, 0x61, 0x67, 0x12, 0x12, 0x0a, 0x0e, 0x46, 0x4c, 0x41, 0x47, 0x5f, 0x45, 0x50, 0x48, 0x45,
+	0x4d, 0x45, 0x52, 0x41, 0x4c, 0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f, 0x46, 0x4c, 0x41, 0x47, 0x5f,
+	0x53, 0x45, 0x51, 0x55, 0x45, 0x4e, 0x54, 0x49, 0x41, 0x4c, 0x10, 0x01, 0x12, 0x0c, 0x0a, 0x08,
+	0x46, 0x4c, 0x41, 0x47, 0x5f, 0x54, 0x54, 0x4c, 0x10, 0x02, 0x12, 0x12, 0x0a, 0x0e, 0x46, 0x4c,
+	0x41, 0x47, 0x5f, 0x43, 0x4f, 0x4e, 0x54, 0x41, 0x49, 0x4e, 0x45, 0x52, 0x10, 0x03, 0x22, 0xdf,
+	0x01, 0x0a, 0x0a, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x0e, 0x0a,
+	0x0a, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x45, 0x54, 0x10, 0x00, 0x12, 0x13, 0x0a,
+	0x0f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x50, 0x45, 0x52, 0x53, 0x49, 0x53, 0x54, 0x45, 0x4e, 0x54,
+	0x10, 0x01, 0x12, 0x12, 0x0a, 0x0e, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x45, 0x50, 0x48, 0x45, 0x4d,
+	0x45, 0x52, 0x41, 0x4c, 0x10, 0x02, 0x12, 0x1e, 0x0a, 0x1a, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x50,
+	0x45, 0x52, 0x53, 0x49, 0x53, 0x54, 0x45, 0x4e, 0x54, 0x5f, 0x53, 0x45, 0x51, 0x55, 0x45, 0x4e,
+	0x54, 0x49, 0x41, 0x4c, 0x10, 0x03, 0x12, 0x1d, 0x0a, 0x19, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x45,
+	0x50, 0x48, 0x45, 0x4d, 0x45, 0x52, 0x41, 0x4c, 0x5f, 0x53, 0x45, 0x51, 0x55, 0x45, 0x4e, 0x54,
+	0x49, 0x41, 0x4c, 0x10, 0x04, 0x12, 0x12, 0x0a, 0x0e, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x43, 0x4f,
+	0x4e, 0x54, 0x41, 0x49, 0x4e, 0x45, 0x52, 0x10, 0x05, 0x12, 0x1c, 0x0a, 0x18, 0x4d, 0x4f, 0x44,
+	0x45, 0x5f, 0x50, 0x45, 0x52, 0x53, 0x49, 0x53, 0x54, 0x45, 0x4e, 0x54, 0x5f, 0x57, 0x49, 0x54,
+	0x48, 0x5f, 0x54, 0x54, 0x4c, 0x10, 0x06, 0x12, 0x27, 0x0a, 0x23, 0x4d, 0x4f, 0x44, 0x45, 0x5f,
+	0x50, 0x45, 0x52, 0x53, 0x49, 0x53, 0x54, 0x45, 0x4e, 0x54, 0x5f, 0x53, 0x45, 0x51, 0x55, 0x45,
+	0x4e, 0x54, 0x49, 0x41, 0x4c, 0x5f, 0x57, 0x49, 0x54, 0x48, 0x5f, 0x54, 0x54, 0x4c, 0x10, 0x07,
+	0x22, 0x30, 0x0a, 0x0e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x1e, 0x0a, 0x0b, 0x7a, 0x5f, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x7a, 0x4e, 0x6f, 0x64, 0x65, 0x4e, 0x61,
+	0x6d, 0x65, 0x22, 0x3d, 0x0a, 0x0d, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x22, 0x10, 0x0a, 0x0e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x39, 0x0a, 0x0d, 0x45, 0x78, 0x69, 0x73, 0x74, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x14, 0x0a, 0x05, 0x77, 0x61, 0x74, 0x63,
+	0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x77, 0x61, 0x74, 0x63, 0x68, 0x22, 0x28,
+	0x0a, 0x0e, 0x45, 0x78, 0x69, 0x73, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x65, 0x78, 0x69, 0x73, 0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x06, 0x65, 0x78, 0x69, 0x73, 0x74, 0x73, 0x22, 0x3a, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x44,
+	0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61,
 	0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x14,
 	0x0a, 0x05, 0x77, 0x61, 0x74, 0x63, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x77,
-	0x61, 0x74, 0x63, 0x68, 0x22, 0x28, 0x0a, 0x0e, 0x45, 0x78, 0x69, 0x73, 0x74, 0x73, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x78, 0x69, 0x73, 0x74, 0x73,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x65, 0x78, 0x69, 0x73, 0x74, 0x73, 0x22, 0x3a,
-	0x0a, 0x0e, 0x47, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
-	0x70, 0x61, 0x74, 0x68, 0x12, 0x14, 0x0a, 0x05, 0x77, 0x61, 0x74, 0x63, 0x68, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x08, 0x52, 0x05, 0x77, 0x61, 0x74, 0x63, 0x68, 0x22, 0x3f, 0x0a, 0x0f, 0x47, 0x65,
-	0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a,
-	0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74,
-	0x61, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x03, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x52, 0x0a, 0x0e, 0x53,
-	0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a,
-	0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74,
-	0x68, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
-	0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22,
-	0x11, 0x0a, 0x0f, 0x53, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x22, 0x3e, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65,
-	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x14, 0x0a, 0x05,
-	0x77, 0x61, 0x74, 0x63, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x77, 0x61, 0x74,
-	0x63, 0x68, 0x22, 0x31, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65,
-	0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x68, 0x69,
-	0x6c, 0x64, 0x72, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x63, 0x68, 0x69,
-	0x6c, 0x64, 0x72, 0x65, 0x6e, 0x22, 0x21, 0x0a, 0x0b, 0x53, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x22, 0x0e, 0x0a, 0x0c, 0x53, 0x79, 0x6e, 0x63,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0xd8, 0x03, 0x0a, 0x10, 0x5a, 0x6f, 0x6f,
-	0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x3b, 0x0a,
-	0x09, 0x68, 0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x1b, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x48, 0x65, 0x61,
-	0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52,
-	0x09, 0x68, 0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x12, 0x32, 0x0a, 0x06, 0x63, 0x72,
-	0x65, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x7a, 0x6f, 0x6f,
-	0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x06, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x12, 0x32,
-	0x0a, 0x06, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18,
-	0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74,
-	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x06, 0x64, 0x65, 0x6c, 0x65,
-	0x74, 0x65, 0x12, 0x32, 0x0a, 0x06, 0x65, 0x78, 0x69, 0x73, 0x74, 0x73, 0x18, 0x04, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x18, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x45,
-	0x78, 0x69, 0x73, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x06,
-	0x65, 0x78, 0x69, 0x73, 0x74, 0x73, 0x12, 0x36, 0x0a, 0x08, 0x67, 0x65, 0x74, 0x5f, 0x64, 0x61,
-	0x74, 0x61, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65,
-	0x65, 0x70, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x07, 0x67, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x12, 0x36,
-	0x0a, 0x08, 0x73, 0x65, 0x74, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x19, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x53, 0x65, 0x74,
-	0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x07, 0x73,
-	0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x12, 0x42, 0x0a, 0x0c, 0x67, 0x65, 0x74, 0x5f, 0x63, 0x68,
-	0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x7a,
-	0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x68, 0x69, 0x6c,
-	0x64, 0x72, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x0b, 0x67,
-	0x65, 0x74, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x12, 0x2c, 0x0a, 0x04, 0x73, 0x79,
-	0x6e, 0x63, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65,
-	0x65, 0x70, 0x65, 0x72, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x48, 0x00, 0x52, 0x04, 0x73, 0x79, 0x6e, 0x63, 0x42, 0x09, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x22, 0x9b, 0x04, 0x0a, 0x11, 0x5a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65,
-	0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x33, 0x0a, 0x06, 0x63, 0x72, 0x65,
-	0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b,
-	0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x06, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x12, 0x33,
-	0x0a, 0x06, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19,
-	0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74,
-	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x06, 0x64, 0x65, 0x6c,
-	0x65, 0x74, 0x65, 0x12, 0x33, 0x0a, 0x06, 0x65, 0x78, 0x69, 0x73, 0x74, 0x73, 0x18, 0x03, 0x20,
+	0x61, 0x74, 0x63, 0x68, 0x22, 0x3f, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x18, 0x0a, 0x07, 0x76,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x52, 0x0a, 0x0e, 0x53, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x64,
+	0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12,
+	0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x11, 0x0a, 0x0f, 0x53, 0x65, 0x74,
+	0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x3e, 0x0a, 0x12,
+	0x47, 0x65, 0x74, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x14, 0x0a, 0x05, 0x77, 0x61, 0x74, 0x63, 0x68, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x77, 0x61, 0x74, 0x63, 0x68, 0x22, 0x31, 0x0a, 0x13,
+	0x47, 0x65, 0x74, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x22,
+	0x21, 0x0a, 0x0b, 0x53, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61,
+	0x74, 0x68, 0x22, 0x0e, 0x0a, 0x0c, 0x53, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x23, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x41, 0x43, 0x4c, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x22, 0x4e, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x41, 0x43,
+	0x4c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x20, 0x0a, 0x03, 0x61, 0x63, 0x6c,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70,
+	0x65, 0x72, 0x2e, 0x41, 0x43, 0x4c, 0x52, 0x03, 0x61, 0x63, 0x6c, 0x12, 0x1a, 0x0a, 0x08, 0x61,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x61,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x61, 0x0a, 0x0d, 0x53, 0x65, 0x74, 0x41, 0x43,
+	0x4c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x20, 0x0a, 0x03,
+	0x61, 0x63, 0x6c, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b,
+	0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x41, 0x43, 0x4c, 0x52, 0x03, 0x61, 0x63, 0x6c, 0x12, 0x1a,
+	0x0a, 0x08, 0x61, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x08, 0x61, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x10, 0x0a, 0x0e, 0x53, 0x65,
+	0x74, 0x41, 0x43, 0x4c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x3c, 0x0a, 0x0e,
+	0x41, 0x64, 0x64, 0x41, 0x75, 0x74, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16,
+	0x0a, 0x06, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x73, 0x63, 0x68, 0x65, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x75, 0x74, 0x68, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x61, 0x75, 0x74, 0x68, 0x22, 0x11, 0x0a, 0x0f, 0x41, 0x64,
+	0x64, 0x41, 0x75, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0xa6, 0x01,
+	0x0a, 0x0f, 0x41, 0x64, 0x64, 0x57, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x33, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e,
+	0x41, 0x64, 0x64, 0x57, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e,
+	0x4d, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x22, 0x4a, 0x0a, 0x04, 0x4d, 0x6f,
+	0x64, 0x65, 0x12, 0x0e, 0x0a, 0x0a, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x45, 0x54,
+	0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x50, 0x45, 0x52, 0x53, 0x49,
+	0x53, 0x54, 0x45, 0x4e, 0x54, 0x10, 0x01, 0x12, 0x1d, 0x0a, 0x19, 0x4d, 0x4f, 0x44, 0x45, 0x5f,
+	0x50, 0x45, 0x52, 0x53, 0x49, 0x53, 0x54, 0x45, 0x4e, 0x54, 0x5f, 0x52, 0x45, 0x43, 0x55, 0x52,
+	0x53, 0x49, 0x56, 0x45, 0x10, 0x02, 0x22, 0x12, 0x0a, 0x10, 0x41, 0x64, 0x64, 0x57, 0x61, 0x74,
+	0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x9f, 0x02, 0x0a, 0x14, 0x52,
+	0x65, 0x6d, 0x6f, 0x76, 0x65, 0x57, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x3f, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x2b, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65,
+	0x72, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x57, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x54, 0x79,
+	0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x22, 0xb1, 0x01, 0x0a, 0x0b, 0x57, 0x61, 0x74,
+	0x63, 0x68, 0x65, 0x72, 0x54, 0x79, 0x70, 0x65, 0x12, 0x16, 0x0a, 0x12, 0x57, 0x41, 0x54, 0x43,
+	0x48, 0x45, 0x52, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x45, 0x54, 0x10, 0x00,
+	0x12, 0x19, 0x0a, 0x15, 0x57, 0x41, 0x54, 0x43, 0x48, 0x45, 0x52, 0x5f, 0x54, 0x59, 0x50, 0x45,
+	0x5f, 0x43, 0x48, 0x49, 0x4c, 0x44, 0x52, 0x45, 0x4e, 0x10, 0x01, 0x12, 0x15, 0x0a, 0x11, 0x57,
+	0x41, 0x54, 0x43, 0x48, 0x45, 0x52, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x44, 0x41, 0x54, 0x41,
+	0x10, 0x02, 0x12, 0x14, 0x0a, 0x10, 0x57, 0x41, 0x54, 0x43, 0x48, 0x45, 0x52, 0x5f, 0x54, 0x59,
+	0x50, 0x45, 0x5f, 0x41, 0x4e, 0x59, 0x10, 0x03, 0x12, 0x1b, 0x0a, 0x17, 0x57, 0x41, 0x54, 0x43,
+	0x48, 0x45, 0x52, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x50, 0x45, 0x52, 0x53, 0x49, 0x53, 0x54,
+	0x45, 0x4e, 0x54, 0x10, 0x04, 0x12, 0x25, 0x0a, 0x21, 0x57, 0x41, 0x54, 0x43, 0x48, 0x45, 0x52,
+	0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x50, 0x45, 0x52, 0x53, 0x49, 0x53, 0x54, 0x45, 0x4e, 0x54,
+	0x5f, 0x52, 0x45, 0x43, 0x55, 0x52, 0x53, 0x49, 0x56, 0x45, 0x10, 0x05, 0x22, 0x17, 0x0a, 0x15,
+	0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x57, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0xb6, 0x01, 0x0a, 0x0f, 0x52, 0x65, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x27, 0x0a, 0x0f, 0x6a, 0x6f, 0x69,
+	0x6e, 0x69, 0x6e, 0x67, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x0e, 0x6a, 0x6f, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x53, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x6c, 0x65, 0x61, 0x76, 0x69, 0x6e, 0x67, 0x5f, 0x73, 0x65,
+	0x72, 0x76, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x03, 0x52, 0x0e, 0x6c, 0x65, 0x61,
+	0x76, 0x69, 0x6e, 0x67, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x66,
+	0x72, 0x6f, 0x6d, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0a, 0x66, 0x72, 0x6f, 0x6d, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x16, 0x0a, 0x06,
+	0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x67, 0x72,
+	0x6f, 0x75, 0x70, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x73, 0x18,
+	0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x73, 0x22, 0x44,
+	0x0a, 0x10, 0x52, 0x65, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x76, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x22, 0x82, 0x07, 0x0a, 0x10, 0x5a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70,
+	0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x78, 0x69, 0x64,
+	0x18, 0x64, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x78, 0x69, 0x64, 0x12, 0x3b, 0x0a, 0x09, 0x68,
+	0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b,
+	0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x48, 0x65, 0x61, 0x72, 0x74,
+	0x62, 0x65, 0x61, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x09, 0x68,
+	0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x12, 0x32, 0x0a, 0x06, 0x63, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65,
+	0x65, 0x70, 0x65, 0x72, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x48, 0x00, 0x52, 0x06, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x12, 0x32, 0x0a, 0x06,
+	0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x7a,
+	0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x06, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x12, 0x32, 0x0a, 0x06, 0x65, 0x78, 0x69, 0x73, 0x74, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x18, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x45, 0x78, 0x69,
+	0x73, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x06, 0x65, 0x78,
+	0x69, 0x73, 0x74, 0x73, 0x12, 0x36, 0x0a, 0x08, 0x67, 0x65, 0x74, 0x5f, 0x64, 0x61, 0x74, 0x61,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70,
+	0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x48, 0x00, 0x52, 0x07, 0x67, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x12, 0x36, 0x0a, 0x08,
+	0x73, 0x65, 0x74, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19,
+	0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x53, 0x65, 0x74, 0x44, 0x61,
+	0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x07, 0x73, 0x65, 0x74,
+	0x44, 0x61, 0x74, 0x61, 0x12, 0x42, 0x0a, 0x0c, 0x67, 0x65, 0x74, 0x5f, 0x63, 0x68, 0x69, 0x6c,
+	0x64, 0x72, 0x65, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x7a, 0x6f, 0x6f,
+	0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x72,
+	0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x0b, 0x67, 0x65, 0x74,
+	0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x12, 0x2c, 0x0a, 0x04, 0x73, 0x79, 0x6e, 0x63,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70,
+	0x65, 0x72, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00,
+	0x52, 0x04, 0x73, 0x79, 0x6e, 0x63, 0x12, 0x38, 0x0a, 0x08, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65,
+	0x65, 0x70, 0x65, 0x72, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x08, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x12, 0x35, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x43, 0x6f,
+	0x6e, 0x6e, 0x65, 0x63, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x07,
+	0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x12, 0x33, 0x0a, 0x07, 0x67, 0x65, 0x74, 0x5f, 0x61,
+	0x63, 0x6c, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65,
+	0x65, 0x70, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x43, 0x4c, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x48, 0x00, 0x52, 0x06, 0x67, 0x65, 0x74, 0x41, 0x63, 0x6c, 0x12, 0x33, 0x0a, 0x07,
+	0x73, 0x65, 0x74, 0x5f, 0x61, 0x63, 0x6c, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e,
+	0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x53, 0x65, 0x74, 0x41, 0x43, 0x4c,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x06, 0x73, 0x65, 0x74, 0x41, 0x63,
+	0x6c, 0x12, 0x36, 0x0a, 0x08, 0x61, 0x64, 0x64, 0x5f, 0x61, 0x75, 0x74, 0x68, 0x18, 0x0d, 0x20,
 	0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e,
-	0x45, 0x78, 0x69, 0x73, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00,
-	0x52, 0x06, 0x65, 0x78, 0x69, 0x73, 0x74, 0x73, 0x12, 0x37, 0x0a, 0x08, 0x67, 0x65, 0x74, 0x5f,
-	0x64, 0x61, 0x74, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x7a, 0x6f, 0x6f,
-	0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x07, 0x67, 0x65, 0x74, 0x44, 0x61, 0x74,
-	0x61, 0x12, 0x37, 0x0a, 0x08, 0x73, 0x65, 0x74, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x05, 0x20,
+	0x41, 0x64, 0x64, 0x41, 0x75, 0x74, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00,
+	0x52, 0x07, 0x61, 0x64, 0x64, 0x41, 0x75, 0x74, 0x68, 0x12, 0x39, 0x0a, 0x09, 0x61, 0x64, 0x64,
+	0x5f, 0x77, 0x61, 0x74, 0x63, 0x68, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x7a,
+	0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x41, 0x64, 0x64, 0x57, 0x61, 0x74, 0x63,
+	0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x08, 0x61, 0x64, 0x64, 0x57,
+	0x61, 0x74, 0x63, 0x68, 0x12, 0x48, 0x0a, 0x0e, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x5f, 0x77,
+	0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x7a,
+	0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x57,
+	0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52,
+	0x0d, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x57, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x42, 0x09,
+	0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x92, 0x08, 0x0a, 0x11, 0x5a, 0x6f,
+	0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x10, 0x0a, 0x03, 0x78, 0x69, 0x64, 0x18, 0x64, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x78, 0x69,
+	0x64, 0x12, 0x12, 0x0a, 0x04, 0x7a, 0x78, 0x69, 0x64, 0x18, 0x65, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x04, 0x7a, 0x78, 0x69, 0x64, 0x12, 0x33, 0x0a, 0x06, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65,
+	0x72, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x48, 0x00, 0x52, 0x06, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x12, 0x33, 0x0a, 0x06, 0x64, 0x65,
+	0x6c, 0x65, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x7a, 0x6f, 0x6f,
+	0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x06, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x12,
+	0x33, 0x0a, 0x06, 0x65, 0x78, 0x69, 0x73, 0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x19, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x45, 0x78, 0x69, 0x73,
+	0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x06, 0x65, 0x78,
+	0x69, 0x73, 0x74, 0x73, 0x12, 0x37, 0x0a, 0x08, 0x67, 0x65, 0x74, 0x5f, 0x64, 0x61, 0x74, 0x61,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70,
+	0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x48, 0x00, 0x52, 0x07, 0x67, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x12, 0x37, 0x0a,
+	0x08, 0x73, 0x65, 0x74, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x53, 0x65, 0x74, 0x44,
+	0x61, 0x74, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x07, 0x73,
+	0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x12, 0x43, 0x0a, 0x0c, 0x67, 0x65, 0x74, 0x5f, 0x63, 0x68,
+	0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x7a,
+	0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x68, 0x69, 0x6c,
+	0x64, 0x72, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x0b,
+	0x67, 0x65, 0x74, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x12, 0x2d, 0x0a, 0x04, 0x73,
+	0x79, 0x6e, 0x63, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b,
+	0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x48, 0x00, 0x52, 0x04, 0x73, 0x79, 0x6e, 0x63, 0x12, 0x38, 0x0a, 0x0b, 0x77, 0x61,
+	0x74, 0x63, 0x68, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x15, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x57, 0x61, 0x74, 0x63,
+	0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x48, 0x00, 0x52, 0x0a, 0x77, 0x61, 0x74, 0x63, 0x68, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x12, 0x3c, 0x0a, 0x09, 0x68, 0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61,
+	0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65,
+	0x70, 0x65, 0x72, 0x2e, 0x48, 0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x09, 0x68, 0x65, 0x61, 0x72, 0x74, 0x62, 0x65,
+	0x61, 0x74, 0x12, 0x39, 0x0a, 0x08, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x0a,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72,
+	0x2e, 0x52, 0x65, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x48, 0x00, 0x52, 0x08, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x36, 0x0a,
+	0x07, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x43, 0x6f, 0x6e, 0x6e, 0x65,
+	0x63, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x07, 0x63, 0x6f,
+	0x6e, 0x6e, 0x65, 0x63, 0x74, 0x12, 0x34, 0x0a, 0x07, 0x67, 0x65, 0x74, 0x5f, 0x61, 0x63, 0x6c,
+	0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70,
+	0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x43, 0x4c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x48, 0x00, 0x52, 0x06, 0x67, 0x65, 0x74, 0x41, 0x63, 0x6c, 0x12, 0x34, 0x0a, 0x07, 0x73,
+	0x65, 0x74, 0x5f, 0x61, 0x63, 0x6c, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x7a,
+	0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x53, 0x65, 0x74, 0x41, 0x43, 0x4c, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x06, 0x73, 0x65, 0x74, 0x41, 0x63,
+	0x6c, 0x12, 0x37, 0x0a, 0x08, 0x61, 0x64, 0x64, 0x5f, 0x61, 0x75, 0x74, 0x68, 0x18, 0x0e, 0x20,
 	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e,
-	0x53, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48,
-	0x00, 0x52, 0x07, 0x73, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x12, 0x43, 0x0a, 0x0c, 0x67, 0x65,
-	0x74, 0x5f, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x1e, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74,
-	0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x48, 0x00, 0x52, 0x0b, 0x67, 0x65, 0x74, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x12,
-	0x2d, 0x0a, 0x04, 0x73, 0x79, 0x6e, 0x63, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e,
-	0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x04, 0x73, 0x79, 0x6e, 0x63, 0x12, 0x38,
-	0x0a, 0x0b, 0x77, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x08, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e,
-	0x57, 0x61, 0x74, 0x63, 0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x48, 0x00, 0x52, 0x0a, 0x77, 0x61,
-	0x74, 0x63, 0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x3c, 0x0a, 0x09, 0x68, 0x65, 0x61, 0x72,
-	0x74, 0x62, 0x65, 0x61, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x7a, 0x6f,
-	0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x48, 0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61,
-	0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x09, 0x68, 0x65, 0x61,
-	0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x42, 0x09, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
-	0x65, 0x32, 0x57, 0x0a, 0x09, 0x5a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x12, 0x4a,
-	0x0a, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1b, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b,
-	0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x5a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70,
-	0x65, 0x72, 0x2e, 0x5a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x42, 0x33, 0x5a, 0x31, 0x67, 0x69,
-	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x69, 0x6b, 0x65, 0x6b, 0x75, 0x6c,
-	0x69, 0x6e, 0x73, 0x6b, 0x69, 0x2f, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2f,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x62,
-	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x41, 0x64, 0x64, 0x41, 0x75, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48,
+	0x00, 0x52, 0x07, 0x61, 0x64, 0x64, 0x41, 0x75, 0x74, 0x68, 0x12, 0x3a, 0x0a, 0x09, 0x61, 0x64,
+	0x64, 0x5f, 0x77, 0x61, 0x74, 0x63, 0x68, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e,
+	0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x41, 0x64, 0x64, 0x57, 0x61, 0x74,
+	0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x08, 0x61, 0x64,
+	0x64, 0x57, 0x61, 0x74, 0x63, 0x68, 0x12, 0x49, 0x0a, 0x0e, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65,
+	0x5f, 0x77, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20,
+	0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76,
+	0x65, 0x57, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x48, 0x00, 0x52, 0x0d, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x57, 0x61, 0x74, 0x63, 0x68, 0x65,
+	0x73, 0x12, 0x30, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x11, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x18, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x45, 0x72, 0x72,
+	0x6f, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x05, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x42, 0x09, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x3d,
+	0x0a, 0x0d, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x63,
+	0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x32, 0x57, 0x0a,
+	0x09, 0x5a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x12, 0x4a, 0x0a, 0x07, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1b, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65,
+	0x72, 0x2e, 0x5a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x5a,
+	0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x42, 0x33, 0x5a, 0x31, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x69, 0x6b, 0x65, 0x6b, 0x75, 0x6c, 0x69, 0x6e, 0x73, 0x6b,
+	0x69, 0x2f, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2f, 0x7a, 0x6f, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1400,56 +2949,96 @@ func file_zookeeper_proto_rawDescGZIP() []byte {
 	return file_zookeeper_proto_rawDescData
 }
 
-var file_zookeeper_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_zookeeper_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_zookeeper_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_zookeeper_proto_msgTypes = make([]protoimpl.MessageInfo, 33)
 var file_zookeeper_proto_goTypes = []interface{}{
-	(CreateRequest_Flag)(0),     // 0: zookeeper.CreateRequest.Flag
-	(*HeartbeatRequest)(nil),    // 1: zookeeper.HeartbeatRequest
-	(*HeartbeatResponse)(nil),   // 2: zookeeper.HeartbeatResponse
-	(*CreateRequest)(nil),       // 3: zookeeper.CreateRequest
-	(*CreateResponse)(nil),      // 4: zookeeper.CreateResponse
-	(*DeleteRequest)(nil),       // 5: zookeeper.DeleteRequest
-	(*DeleteResponse)(nil),      // 6: zookeeper.DeleteResponse
-	(*ExistsRequest)(nil),       // 7: zookeeper.ExistsRequest
-	(*ExistsResponse)(nil),      // 8: zookeeper.ExistsResponse
-	(*GetDataRequest)(nil),      // 9: zookeeper.GetDataRequest
-	(*GetDataResponse)(nil),     // 10: zookeeper.GetDataResponse
-	(*SetDataRequest)(nil),      // 11: zookeeper.SetDataRequest
-	(*SetDataResponse)(nil),     // 12: zookeeper.SetDataResponse
-	(*GetChildrenRequest)(nil),  // 13: zookeeper.GetChildrenRequest
-	(*GetChildrenResponse)(nil), // 14: zookeeper.GetChildrenResponse
-	(*SyncRequest)(nil),         // 15: zookeeper.SyncRequest
-	(*SyncResponse)(nil),        // 16: zookeeper.SyncResponse
-	(*ZookeeperRequest)(nil),    // 17: zookeeper.ZookeeperRequest
-	(*ZookeeperResponse)(nil),   // 18: zookeeper.ZookeeperResponse
-	(*WatchEvent)(nil),          // 19: zookeeper.WatchEvent
+	(CreateRequest_Flag)(0),               // 0: zookeeper.CreateRequest.Flag
+	(CreateRequest_CreateMode)(0),         // 1: zookeeper.CreateRequest.CreateMode
+	(AddWatchRequest_Mode)(0),             // 2: zookeeper.AddWatchRequest.Mode
+	(RemoveWatchesRequest_WatcherType)(0), // 3: zookeeper.RemoveWatchesRequest.WatcherType
+	(*ConnectRequest)(nil),                // 4: zookeeper.ConnectRequest
+	(*ConnectResponse)(nil),               // 5: zookeeper.ConnectResponse
+	(*HeartbeatRequest)(nil),              // 6: zookeeper.HeartbeatRequest
+	(*HeartbeatResponse)(nil),             // 7: zookeeper.HeartbeatResponse
+	(*CreateRequest)(nil),                 // 8: zookeeper.CreateRequest
+	(*CreateResponse)(nil),                // 9: zookeeper.CreateResponse
+	(*DeleteRequest)(nil),                 // 10: zookeeper.DeleteRequest
+	(*DeleteResponse)(nil),                // 11: zookeeper.DeleteResponse
+	(*ExistsRequest)(nil),                 // 12: zookeeper.ExistsRequest
+	(*ExistsResponse)(nil),                // 13: zookeeper.ExistsResponse
+	(*GetDataRequest)(nil),                // 14: zookeeper.GetDataRequest
+	(*GetDataResponse)(nil),               // 15: zookeeper.GetDataResponse
+	(*SetDataRequest)(nil),                // 16: zookeeper.SetDataRequest
+	(*SetDataResponse)(nil),               // 17: zookeeper.SetDataResponse
+	(*GetChildrenRequest)(nil),            // 18: zookeeper.GetChildrenRequest
+	(*GetChildrenResponse)(nil),           // 19: zookeeper.GetChildrenResponse
+	(*SyncRequest)(nil),                   // 20: zookeeper.SyncRequest
+	(*SyncResponse)(nil),                  // 21: zookeeper.SyncResponse
+	(*GetACLRequest)(nil),                 // 22: zookeeper.GetACLRequest
+	(*GetACLResponse)(nil),                // 23: zookeeper.GetACLResponse
+	(*SetACLRequest)(nil),                 // 24: zookeeper.SetACLRequest
+	(*SetACLResponse)(nil),                // 25: zookeeper.SetACLResponse
+	(*AddAuthRequest)(nil),                // 26: zookeeper.AddAuthRequest
+	(*AddAuthResponse)(nil),               // 27: zookeeper.AddAuthResponse
+	(*AddWatchRequest)(nil),               // 28: zookeeper.AddWatchRequest
+	(*AddWatchResponse)(nil),              // 29: zookeeper.AddWatchResponse
+	(*RemoveWatchesRequest)(nil),          // 30: zookeeper.RemoveWatchesRequest
+	(*RemoveWatchesResponse)(nil),         // 31: zookeeper.RemoveWatchesResponse
+	(*ReconfigRequest)(nil),               // 32: zookeeper.ReconfigRequest
+	(*ReconfigResponse)(nil),              // 33: zookeeper.ReconfigResponse
+	(*ZookeeperRequest)(nil),              // 34: zookeeper.ZookeeperRequest
+	(*ZookeeperResponse)(nil),             // 35: zookeeper.ZookeeperResponse
+	(*ErrorResponse)(nil),                 // 36: zookeeper.ErrorResponse
+	(*ACL)(nil),                           // 37: zookeeper.ACL
+	(*WatchEvent)(nil),                    // 38: zookeeper.WatchEvent
 }
 var file_zookeeper_proto_depIdxs = []int32{
 	0,  // 0: zookeeper.CreateRequest.flags:type_name -> zookeeper.CreateRequest.Flag
-	1,  // 1: zookeeper.ZookeeperRequest.heartbeat:type_name -> zookeeper.HeartbeatRequest
-	3,  // 2: zookeeper.ZookeeperRequest.create:type_name -> zookeeper.CreateRequest
-	5,  // 3: zookeeper.ZookeeperRequest.delete:type_name -> zookeeper.DeleteRequest
-	7,  // 4: zookeeper.ZookeeperRequest.exists:type_name -> zookeeper.ExistsRequest
-	9,  // 5: zookeeper.ZookeeperRequest.get_data:type_name -> zookeeper.GetDataRequest
-	11, // 6: zookeeper.ZookeeperRequest.set_data:type_name -> zookeeper.SetDataRequest
-	13, // 7: zookeeper.ZookeeperRequest.get_children:type_name -> zookeeper.GetChildrenRequest
-	15, // 8: zookeeper.ZookeeperRequest.sync:type_name -> zookeeper.SyncRequest
-	4,  // 9: zookeeper.ZookeeperResponse.create:type_name -> zookeeper.CreateResponse
-	6,  // 10: zookeeper.ZookeeperResponse.delete:type_name -> zookeeper.DeleteResponse
-	8,  // 11: zookeeper.ZookeeperResponse.exists:type_name -> zookeeper.ExistsResponse
-	10, // 12: zookeeper.ZookeeperResponse.get_data:type_name -> zookeeper.GetDataResponse
-	12, // 13: zookeeper.ZookeeperResponse.set_data:type_name -> zookeeper.SetDataResponse
-	14, // 14: zookeeper.ZookeeperResponse.get_children:type_name -> zookeeper.GetChildrenResponse
-	16, // 15: zookeeper.ZookeeperResponse.sync:type_name -> zookeeper.SyncResponse
-	19, // 16: zookeeper.ZookeeperResponse.watch_event:type_name -> zookeeper.WatchEvent
-	2,  // 17: zookeeper.ZookeeperResponse.heartbeat:type_name -> zookeeper.HeartbeatResponse
-	17, // 18: zookeeper.Zookeeper.Message:input_type -> zookeeper.ZookeeperRequest
-	18, // 19: zookeeper.Zookeeper.Message:output_type -> zookeeper.ZookeeperResponse
-	19, // [19:20] is the sub-list for method output_type
-	18, // [18:19] is the sub-list for method input_type
-	18, // [18:18] is the sub-list for extension type_name
-	18, // [18:18] is the sub-list for extension extendee
-	0,  // [0:18] is the sub-list for field type_name
+	37, // 1: zookeeper.CreateRequest.acl:type_name -> zookeeper.ACL
+	1,  // 2: zookeeper.CreateRequest.mode:type_name -> zookeeper.CreateRequest.CreateMode
+	37, // 3: zookeeper.GetACLResponse.acl:type_name -> zookeeper.ACL
+	37, // 4: zookeeper.SetACLRequest.acl:type_name -> zookeeper.ACL
+	2,  // 5: zookeeper.AddWatchRequest.mode:type_name -> zookeeper.AddWatchRequest.Mode
+	3,  // 6: zookeeper.RemoveWatchesRequest.type:type_name -> zookeeper.RemoveWatchesRequest.WatcherType
+	6,  // 7: zookeeper.ZookeeperRequest.heartbeat:type_name -> zookeeper.HeartbeatRequest
+	8,  // 8: zookeeper.ZookeeperRequest.create:type_name -> zookeeper.CreateRequest
+	10, // 9: zookeeper.ZookeeperRequest.delete:type_name -> zookeeper.DeleteRequest
+	12, // 10: zookeeper.ZookeeperRequest.exists:type_name -> zookeeper.ExistsRequest
+	14, // 11: zookeeper.ZookeeperRequest.get_data:type_name -> zookeeper.GetDataRequest
+	16, // 12: zookeeper.ZookeeperRequest.set_data:type_name -> zookeeper.SetDataRequest
+	18, // 13: zookeeper.ZookeeperRequest.get_children:type_name -> zookeeper.GetChildrenRequest
+	20, // 14: zookeeper.ZookeeperRequest.sync:type_name -> zookeeper.SyncRequest
+	32, // 15: zookeeper.ZookeeperRequest.reconfig:type_name -> zookeeper.ReconfigRequest
+	4,  // 16: zookeeper.ZookeeperRequest.connect:type_name -> zookeeper.ConnectRequest
+	22, // 17: zookeeper.ZookeeperRequest.get_acl:type_name -> zookeeper.GetACLRequest
+	24, // 18: zookeeper.ZookeeperRequest.set_acl:type_name -> zookeeper.SetACLRequest
+	26, // 19: zookeeper.ZookeeperRequest.add_auth:type_name -> zookeeper.AddAuthRequest
+	28, // 20: zookeeper.ZookeeperRequest.add_watch:type_name -> zookeeper.AddWatchRequest
+	30, // 21: zookeeper.ZookeeperRequest.remove_watches:type_name -> zookeeper.RemoveWatchesRequest
+	9,  // 22: zookeeper.ZookeeperResponse.create:type_name -> zookeeper.CreateResponse
+	11, // 23: zookeeper.ZookeeperResponse.delete:type_name -> zookeeper.DeleteResponse
+	13, // 24: zookeeper.ZookeeperResponse.exists:type_name -> zookeeper.ExistsResponse
+	15, // 25: zookeeper.ZookeeperResponse.get_data:type_name -> zookeeper.GetDataResponse
+	17, // 26: zookeeper.ZookeeperResponse.set_data:type_name -> zookeeper.SetDataResponse
+	19, // 27: zookeeper.ZookeeperResponse.get_children:type_name -> zookeeper.GetChildrenResponse
+	21, // 28: zookeeper.ZookeeperResponse.sync:type_name -> zookeeper.SyncResponse
+	38, // 29: zookeeper.ZookeeperResponse.watch_event:type_name -> zookeeper.WatchEvent
+	7,  // 30: zookeeper.ZookeeperResponse.heartbeat:type_name -> zookeeper.HeartbeatResponse
+	33, // 31: zookeeper.ZookeeperResponse.reconfig:type_name -> zookeeper.ReconfigResponse
+	5,  // 32: zookeeper.ZookeeperResponse.connect:type_name -> zookeeper.ConnectResponse
+	23, // 33: zookeeper.ZookeeperResponse.get_acl:type_name -> zookeeper.GetACLResponse
+	25, // 34: zookeeper.ZookeeperResponse.set_acl:type_name -> zookeeper.SetACLResponse
+	27, // 35: zookeeper.ZookeeperResponse.add_auth:type_name -> zookeeper.AddAuthResponse
+	29, // 36: zookeeper.ZookeeperResponse.add_watch:type_name -> zookeeper.AddWatchResponse
+	31, // 37: zookeeper.ZookeeperResponse.remove_watches:type_name -> zookeeper.RemoveWatchesResponse
+	36, // 38: zookeeper.ZookeeperResponse.error:type_name -> zookeeper.ErrorResponse
+	34, // 39: zookeeper.Zookeeper.Message:input_type -> zookeeper.ZookeeperRequest
+	35, // 40: zookeeper.Zookeeper.Message:output_type -> zookeeper.ZookeeperResponse
+	40, // [40:41] is the sub-list for method output_type
+	39, // [39:40] is the sub-list for method input_type
+	39, // [39:39] is the sub-list for extension type_name
+	39, // [39:39] is the sub-list for extension extendee
+	0,  // [0:39] is the sub-list for field type_name
 }
 
 func init() { file_zookeeper_proto_init() }
@@ -1457,10 +3046,11 @@ func file_zookeeper_proto_init() {
 	if File_zookeeper_proto != nil {
 		return
 	}
+	file_acl_proto_init()
 	file_watch_proto_init()
 	if !protoimpl.UnsafeEnabled {
 		file_zookeeper_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*HeartbeatRequest); i {
+			switch v := v.(*ConnectRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1472,7 +3062,7 @@ func file_zookeeper_proto_init() {
 			}
 		}
 		file_zookeeper_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*HeartbeatResponse); i {
+			switch v := v.(*ConnectResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1484,7 +3074,7 @@ func file_zookeeper_proto_init() {
 			}
 		}
 		file_zookeeper_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CreateRequest); i {
+			switch v := v.(*HeartbeatRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1496,7 +3086,7 @@ func file_zookeeper_proto_init() {
 			}
 		}
 		file_zookeeper_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CreateResponse); i {
+			switch v := v.(*HeartbeatResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1508,7 +3098,7 @@ func file_zookeeper_proto_init() {
 			}
 		}
 		file_zookeeper_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DeleteRequest); i {
+			switch v := v.(*CreateRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1520,7 +3110,7 @@ func file_zookeeper_proto_init() {
 			}
 		}
 		file_zookeeper_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DeleteResponse); i {
+			switch v := v.(*CreateResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1532,7 +3122,7 @@ func file_zookeeper_proto_init() {
 			}
 		}
 		file_zookeeper_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ExistsRequest); i {
+			switch v := v.(*DeleteRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1544,7 +3134,7 @@ func file_zookeeper_proto_init() {
 			}
 		}
 		file_zookeeper_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ExistsResponse); i {
+			switch v := v.(*DeleteResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1556,7 +3146,7 @@ func file_zookeeper_proto_init() {
 			}
 		}
 		file_zookeeper_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetDataRequest); i {
+			switch v := v.(*ExistsRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1568,7 +3158,7 @@ func file_zookeeper_proto_init() {
 			}
 		}
 		file_zookeeper_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetDataResponse); i {
+			switch v := v.(*ExistsResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1580,7 +3170,7 @@ func file_zookeeper_proto_init() {
 			}
 		}
 		file_zookeeper_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SetDataRequest); i {
+			switch v := v.(*GetDataRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1592,7 +3182,7 @@ func file_zookeeper_proto_init() {
 			}
 		}
 		file_zookeeper_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SetDataResponse); i {
+			switch v := v.(*GetDataResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1604,7 +3194,7 @@ func file_zookeeper_proto_init() {
 			}
 		}
 		file_zookeeper_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetChildrenRequest); i {
+			switch v := v.(*SetDataRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1616,7 +3206,7 @@ func file_zookeeper_proto_init() {
 			}
 		}
 		file_zookeeper_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetChildrenResponse); i {
+			switch v := v.(*SetDataResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1628,7 +3218,7 @@ func file_zookeeper_proto_init() {
 			}
 		}
 		file_zookeeper_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SyncRequest); i {
+			switch v := v.(*GetChildrenRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1640,7 +3230,7 @@ func file_zookeeper_proto_init() {
 			}
 		}
 		file_zookeeper_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SyncResponse); i {
+			switch v := v.(*GetChildrenResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1652,7 +3242,7 @@ func file_zookeeper_proto_init() {
 			}
 		}
 		file_zookeeper_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ZookeeperRequest); i {
+			switch v := v.(*SyncRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1664,6 +3254,174 @@ func file_zookeeper_proto_init() {
 			}
 		}
 		file_zookeeper_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SyncResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zookeeper_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetACLRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zookeeper_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetACLResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zookeeper_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetACLRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zookeeper_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetACLResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zookeeper_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AddAuthRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zookeeper_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AddAuthResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zookeeper_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AddWatchRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zookeeper_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AddWatchResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zookeeper_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RemoveWatchesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zookeeper_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RemoveWatchesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zookeeper_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReconfigRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zookeeper_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReconfigResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zookeeper_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ZookeeperRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zookeeper_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ZookeeperResponse); i {
 			case 0:
 				return &v.state
@@ -1675,8 +3433,20 @@ func file_zookeeper_proto_init() {
 				return nil
 			}
 		}
+		file_zookeeper_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ErrorResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
-	file_zookeeper_proto_msgTypes[16].OneofWrappers = []interface{}{
+	file_zookeeper_proto_msgTypes[30].OneofWrappers = []interface{}{
 		(*ZookeeperRequest_Heartbeat)(nil),
 		(*ZookeeperRequest_Create)(nil),
 		(*ZookeeperRequest_Delete)(nil),
@@ -1685,8 +3455,15 @@ func file_zookeeper_proto_init() {
 		(*ZookeeperRequest_SetData)(nil),
 		(*ZookeeperRequest_GetChildren)(nil),
 		(*ZookeeperRequest_Sync)(nil),
-	}
-	file_zookeeper_proto_msgTypes[17].OneofWrappers = []interface{}{
+		(*ZookeeperRequest_Reconfig)(nil),
+		(*ZookeeperRequest_Connect)(nil),
+		(*ZookeeperRequest_GetAcl)(nil),
+		(*ZookeeperRequest_SetAcl)(nil),
+		(*ZookeeperRequest_AddAuth)(nil),
+		(*ZookeeperRequest_AddWatch)(nil),
+		(*ZookeeperRequest_RemoveWatches)(nil),
+	}
+	file_zookeeper_proto_msgTypes[31].OneofWrappers = []interface{}{
 		(*ZookeeperResponse_Create)(nil),
 		(*ZookeeperResponse_Delete)(nil),
 		(*ZookeeperResponse_Exists)(nil),
@@ -1696,14 +3473,22 @@ func file_zookeeper_proto_init() {
 		(*ZookeeperResponse_Sync)(nil),
 		(*ZookeeperResponse_WatchEvent)(nil),
 		(*ZookeeperResponse_Heartbeat)(nil),
+		(*ZookeeperResponse_Reconfig)(nil),
+		(*ZookeeperResponse_Connect)(nil),
+		(*ZookeeperResponse_GetAcl)(nil),
+		(*ZookeeperResponse_SetAcl)(nil),
+		(*ZookeeperResponse_AddAuth)(nil),
+		(*ZookeeperResponse_AddWatch)(nil),
+		(*ZookeeperResponse_RemoveWatches)(nil),
+		(*ZookeeperResponse_Error)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_zookeeper_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   18,
+			NumEnums:      4,
+			NumMessages:   33,
 			NumExtensions: 0,
 			NumServices:   1,
 		},